@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"iter"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// streamSearch runs executor.SearchStream for a gRPC search, using the
+// search url templates from RUTOR_SEARCH_URL and KZ_SEARCH_URL.
+func streamSearch(rutorSearchURL, kinozalSearchURL string) grpcapi.SearchFunc {
+	return func(ctx context.Context, query, year string, movie bool) (iter.Seq2[grpcapi.Batch, error], error) {
+		rutorURL, err := buildTrackerURL(rutorSearchURL, query, year)
+		if err != nil {
+			return nil, err
+		}
+		kinozalURL, err := buildTrackerURL(kinozalSearchURL, query, year)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(yield func(grpcapi.Batch, error) bool) {
+			ctx, span := startSearch(ctx, "grpc.search", query, year, movie)
+			var failed error
+			defer func() { endSpan(span, failed) }()
+
+			stream := executor.Init(*executor.InitVars([]string{rutorURL, kinozalURL}, "")).
+				WithContext(ctx).
+				SearchStream(movie)
+			for batch, err := range stream {
+				if err != nil {
+					failed = err
+				}
+				if !yield(grpcapi.Batch{Tracker: batch.Tracker, Torrents: batch.Torrents}, err) {
+					return
+				}
+			}
+		}, nil
+	}
+}
+
+// runGRPC implements "moviestracker grpc": the SearchService of
+// proto/moviestracker/v1 plus the standard gRPC health service.
+func runGRPC(args []string) int {
+	fs := flag.NewFlagSet("grpc", flag.ExitOnError)
+	var (
+		addr          = fs.String("addr", envOrDefault("GRPC_ADDR", ":9090"), "Listen address")
+		searchTimeout = fs.Duration("search-timeout", grpcapi.DefaultSearchTimeout, "Time limit of one Search call")
+	)
+	_ = fs.Parse(args)
+
+	rutorSearchURL := os.Getenv("RUTOR_SEARCH_URL")
+	kinozalSearchURL := os.Getenv("KZ_SEARCH_URL")
+	if rutorSearchURL == "" || kinozalSearchURL == "" {
+		slog.Error("missing required tracker urls", "required", []string{"RUTOR_SEARCH_URL", "KZ_SEARCH_URL"})
+		return 1
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		slog.Error("failed to listen", "addr", *addr, "error", err)
+		return 1
+	}
+
+	srv := grpc.NewServer()
+	grpcapi.New(streamSearch(rutorSearchURL, kinozalSearchURL), *searchTimeout).Register(srv)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("grpc server listening", "addr", lis.Addr().String())
+		serveErr <- srv.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("grpc server failed", "error", err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	slog.Info("grpc server shutting down")
+	healthServer.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	timer := time.NewTimer(shutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		slog.Warn("grpc graceful shutdown timed out; closing open streams")
+		srv.Stop()
+	}
+	return 0
+}
@@ -1,17 +1,22 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/metrics"
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
 	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"github.com/lieranderl/moviestracker-package/pkg/tracing"
 )
 
 func envOrDefault(key, fallback string) string {
@@ -45,9 +50,96 @@ func buildTrackerURL(template, query, year string) (string, error) {
 	return u.String(), nil
 }
 
+// openTMDBCache builds the cache selected by kind: "" or "none" disables it,
+// "memory" keeps responses for the lifetime of the process and "mongo" shares
+// them between runs. The returned close function is never nil.
+func openTMDBCache(ctx context.Context, kind, mongoURI string) (cache.Cache, func(), error) {
+	noop := func() {}
+
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "none":
+		return nil, noop, nil
+	case "memory":
+		return cache.NewMemory(cache.DefaultMemoryCapacity), noop, nil
+	case "mongo":
+		if mongoURI == "" {
+			return nil, noop, errors.New("mongo tmdb cache requires MONGO_URI")
+		}
+		c, err := cache.ConnectMongo(ctx, mongoURI, "movies", "tmdb_cache")
+		if err != nil {
+			return nil, noop, err
+		}
+		return c, func() { _ = c.Close(context.Background()) }, nil
+	default:
+		return nil, noop, fmt.Errorf("unsupported tmdb cache %q (none|memory|mongo)", kind)
+	}
+}
+
+// applyMetadataEnv configures the TMDB, Kinopoisk and OMDb clients of envVars
+// from the flags and environment shared by the one-shot run and serve, counting
+// TMDB cache reads in m. The returned close function releases the TMDB cache
+// and is never nil.
+func applyMetadataEnv(envVars *executor.EnvVars, m *metrics.Metrics, languages, tmdbCache, tmdbBaseURL, mongoURI string) (func(), error) {
+	cacheTTL, err := time.ParseDuration(envOrDefault("TMDB_CACHE_TTL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TMDB_CACHE_TTL: %w", err)
+	}
+	tmdbRate, err := strconv.ParseFloat(envOrDefault("TMDB_RATE_LIMIT", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TMDB_RATE_LIMIT: %w", err)
+	}
+	cacheCtx, cancelCache := context.WithTimeout(context.Background(), 15*time.Second)
+	responseCache, closeCache, err := openTMDBCache(cacheCtx, tmdbCache, mongoURI)
+	cancelCache()
+	if err != nil {
+		return nil, fmt.Errorf("open tmdb cache: %w", err)
+	}
+
+	if responseCache != nil {
+		envVars.WithTMDBCache(m.InstrumentCache(responseCache), cacheTTL)
+	}
+	envVars.WithLanguages(strings.Split(languages, ",")...)
+	envVars.WithTMDBBaseURL(tmdbBaseURL)
+	envVars.WithTMDBRateLimit(tmdbRate)
+	if kinopoiskKey := os.Getenv("KINOPOISK_API_KEY"); kinopoiskKey != "" {
+		envVars.WithKinopoisk(kinopoiskKey, os.Getenv("KINOPOISK_BASE_URL"))
+	}
+	envVars.WithOMDb(os.Getenv("OMDB_API_KEY"), os.Getenv("OMDB_BASE_URL"))
+	return closeCache, nil
+}
+
+var subcommands = map[string]func(args []string) int{
+	"watch":    runWatch,
+	"telegram": runTelegram,
+	"serve":    runServe,
+	"grpc":     runGRPC,
+}
+
 func main() {
-	logger := logging.Init()
 	_ = godotenv.Load()
+	logger := logging.Init()
+
+	shutdownTracing, err := tracing.Init(context.Background(), "moviestracker")
+	if err != nil {
+		logger.Error("invalid tracing settings", "error", err)
+		os.Exit(1)
+	}
+	flushTraces := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("failed to flush traces", "error", err)
+		}
+	}
+
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			code := run(os.Args[2:])
+			flushTraces()
+			os.Exit(code)
+		}
+	}
+	defer flushTraces()
 
 	var (
 		query       = flag.String("query", envOrDefault("MOVIE_QUERY", "Bad Boys"), "Movie/series search query")
@@ -55,13 +147,32 @@ func main() {
 		isMovie     = flag.Bool("movie", true, "Set false to search for series")
 		saveToMongo = flag.Bool("save", strings.EqualFold(envOrDefault("SAVE_TO_MONGO", "false"), "true"), "Persist enriched movies to MongoDB")
 		collection  = flag.String("collection", envOrDefault("MONGO_COLLECTION", "movies"), "MongoDB collection name")
+		languages   = flag.String("languages", envOrDefault("TMDB_LANGUAGES", "ru"), "Comma-separated TMDB languages; the first one is used for matching")
+		tmdbCache   = flag.String("tmdb-cache", envOrDefault("TMDB_CACHE", "none"), "TMDB response cache: none, memory or mongo")
+		tmdbBaseURL = flag.String("tmdb-base-url", os.Getenv("TMDB_BASE_URL"), "TMDB compatible API root, e.g. a local tmdbfake server; empty uses api.themoviedb.org")
+		fullDetails = flag.Bool("full", strings.EqualFold(envOrDefault("TMDB_FULL_DETAILS", "false"), "true"), "Fetch full TMDB details (credits, videos, release dates, external ids)")
+		migrate     = flag.Bool("migrate", false, "Convert legacy string fields of saved movies to typed values and exit (MONGO_URI required)")
+		details     = flag.Bool("details", strings.EqualFold(envOrDefault("RUTOR_DETAILS", "false"), "true"), "Fetch rutor details pages for ids, poster, description and files")
 	)
 	flag.Parse()
 
+	mongoURI := os.Getenv("MONGO_URI")
+	if *migrate {
+		if mongoURI == "" {
+			logger.Error("migration requires MONGO_URI")
+			os.Exit(1)
+		}
+		err := executor.Init(*executor.InitVars(nil, "").WithMongo(mongoURI)).MigrateMongo(*collection).HandleErrors()
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	rutorSearchURL := os.Getenv("RUTOR_SEARCH_URL")
 	kinozalSearchURL := os.Getenv("KZ_SEARCH_URL")
 	tmdbAPIKey := os.Getenv("TMDBAPIKEY")
-	mongoURI := os.Getenv("MONGO_URI")
 
 	if rutorSearchURL == "" || kinozalSearchURL == "" {
 		logger.Error("missing required tracker urls", "required", []string{"RUTOR_SEARCH_URL", "KZ_SEARCH_URL"})
@@ -85,19 +196,36 @@ func main() {
 	urls := []string{rutorURL, kinozalURL}
 
 	start := time.Now()
-	logger.Info("starting tracker pipeline", "query", *query, "year", *year, "is_movie", *isMovie)
-	logger.Debug("tracker urls", "rutor_url", rutorURL, "kinozal_url", kinozalURL)
+	ctx, span := startSearch(context.Background(), "search", *query, *year, *isMovie)
+	logger.InfoContext(ctx, "starting tracker pipeline", "year", *year, "is_movie", *isMovie)
+	logger.DebugContext(ctx, "tracker urls", "rutor_url", rutorURL, "kinozal_url", kinozalURL)
 
 	envVars := executor.InitVars(urls, tmdbAPIKey)
 	if mongoURI != "" {
 		envVars.WithMongo(mongoURI)
 	}
-	pipeline := executor.Init(*envVars)
+	m := metrics.New()
+	closeCache, err := applyMetadataEnv(envVars, m, *languages, *tmdbCache, *tmdbBaseURL, mongoURI)
+	if err != nil {
+		logger.Error("invalid metadata settings", "error", err)
+		os.Exit(1)
+	}
+	defer closeCache()
+	run := m.Run()
+	pipeline := executor.Init(*envVars).WithContext(ctx).WithObserver(run)
 
+	pipeline = pipeline.RunTrackersSearchPipeline(*isMovie)
+	if *details {
+		pipeline = pipeline.EnrichRutorDetails()
+	}
 	pipeline = pipeline.
-		RunTrackersSearchPipeline(*isMovie).
 		ConvertTorrentsToMovieShort().
-		Tmdb()
+		Tmdb().
+		Genres()
+	if *fullDetails {
+		pipeline = pipeline.Details()
+	}
+	pipeline = pipeline.Ratings()
 
 	if *saveToMongo {
 		if mongoURI == "" {
@@ -108,10 +236,13 @@ func main() {
 	}
 
 	err = pipeline.HandleErrors()
+	endSpan(span, err)
+	logger.InfoContext(ctx, "run summary", "summary", run.Done(err))
 	if err != nil {
-		logger.Error("pipeline failed", "error", err)
+		logger.ErrorContext(ctx, "pipeline failed", "error", err)
+		flushTraces()
 		os.Exit(1)
 	}
 
-	logger.Info("pipeline completed", "torrents", len(pipeline.GetTorrents()), "elapsed", time.Since(start).String())
+	logger.InfoContext(ctx, "pipeline completed", "torrents", len(pipeline.GetTorrents()), "skipped", len(pipeline.Skipped()), "elapsed", time.Since(start).String())
 }
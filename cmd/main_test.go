@@ -1,6 +1,11 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+)
 
 func TestBuildTrackerURLNormalizesKinozalQueryAndScheme(t *testing.T) {
 	template := "http://kinozal.tv/browse.php?s=%s (1080p|2160p)&g=3&c=0&v=0&d=%s&w=0&t=0&f=0"
@@ -29,3 +34,40 @@ func TestBuildTrackerURLNormalizesRutorPath(t *testing.T) {
 		t.Fatalf("unexpected URL\nwant: %s\ngot:  %s", want, got)
 	}
 }
+
+func TestOpenTMDBCache(t *testing.T) {
+	ctx := context.Background()
+
+	c, closeCache, err := openTMDBCache(ctx, "none", "")
+	if err != nil || c != nil || closeCache == nil {
+		t.Fatalf("none: got cache=%v err=%v", c, err)
+	}
+
+	c, _, err = openTMDBCache(ctx, "Memory", "")
+	if err != nil {
+		t.Fatalf("memory: unexpected error: %v", err)
+	}
+	if _, ok := c.(*cache.Memory); !ok {
+		t.Fatalf("memory: got %T", c)
+	}
+
+	if _, _, err := openTMDBCache(ctx, "mongo", ""); err == nil {
+		t.Fatal("mongo without uri: expected error")
+	}
+	if _, _, err := openTMDBCache(ctx, "redis", ""); err == nil {
+		t.Fatal("unknown kind: expected error")
+	}
+}
+
+func TestParseChatIDs(t *testing.T) {
+	ids, err := parseChatIDs(" 42, -100123 ,")
+	if err != nil {
+		t.Fatalf("parseChatIDs returned error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 42 || ids[1] != -100123 {
+		t.Fatalf("unexpected chat ids: %v", ids)
+	}
+	if _, err := parseChatIDs("@channel"); err == nil {
+		t.Fatal("expected an error for a non numeric chat id")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/api"
+	"github.com/lieranderl/moviestracker-package/internal/metrics"
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+// pipelineSearch runs the tracker and metadata pipeline for an API search on
+// a copy of base, optionally saving the movies to collection, records it in m
+// and forwards the executor progress to /search/events.
+func pipelineSearch(base executor.EnvVars, m *metrics.Metrics, rutorSearchURL, kinozalSearchURL string, fullDetails, save bool, collection string) api.SearchFunc {
+	return func(ctx context.Context, req api.SearchRequest, progress api.ProgressFunc) ([]*movies.Short, error) {
+		rutorURL, err := buildTrackerURL(rutorSearchURL, req.Query, req.Year)
+		if err != nil {
+			return nil, err
+		}
+		kinozalURL, err := buildTrackerURL(kinozalSearchURL, req.Query, req.Year)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, span := startSearch(ctx, "api.search", req.Query, req.Year, req.Movie)
+		env := base
+		env.WithURLs(rutorURL, kinozalURL)
+		run := m.Run()
+		pipeline := executor.Init(env).WithContext(ctx).WithObserver(run)
+		if progress != nil {
+			pipeline.WithProgress(func(e executor.Progress) {
+				progress(api.Event{Name: string(e.Type), Data: e})
+			})
+		}
+		pipeline = pipeline.
+			RunTrackersSearchPipeline(req.Movie).
+			ConvertTorrentsToMovieShort().
+			Tmdb().
+			Genres()
+		if fullDetails {
+			pipeline = pipeline.Details()
+		}
+		pipeline = pipeline.Ratings()
+		if save {
+			pipeline = pipeline.SaveToMongo(collection)
+		}
+		err = pipeline.HandleErrors()
+		endSpan(span, err)
+		slog.DebugContext(ctx, "search summary", "summary", run.Done(err))
+		if err != nil {
+			return nil, err
+		}
+		return pipeline.GetMovies(), nil
+	}
+}
+
+// runServe implements "moviestracker serve": the JSON API of internal/api and
+// the Prometheus metrics on /metrics.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		addr          = fs.String("addr", envOrDefault("SERVE_ADDR", ":8080"), "Listen address")
+		collection    = fs.String("collection", envOrDefault("MONGO_COLLECTION", "movies"), "MongoDB collection served by /movies")
+		save          = fs.Bool("save", strings.EqualFold(envOrDefault("SAVE_TO_MONGO", "false"), "true"), "Persist the movies of every search to MongoDB")
+		languages     = fs.String("languages", envOrDefault("TMDB_LANGUAGES", "ru"), "Comma-separated TMDB languages; the first one is used for matching")
+		tmdbCache     = fs.String("tmdb-cache", envOrDefault("TMDB_CACHE", "memory"), "TMDB response cache: none, memory or mongo")
+		tmdbBaseURL   = fs.String("tmdb-base-url", os.Getenv("TMDB_BASE_URL"), "TMDB compatible API root; empty uses api.themoviedb.org")
+		fullDetails   = fs.Bool("full", strings.EqualFold(envOrDefault("TMDB_FULL_DETAILS", "false"), "true"), "Fetch full TMDB details for searched movies")
+		searchTimeout = fs.Duration("search-timeout", api.DefaultSearchTimeout, "Time limit of one /search or /search/events request")
+	)
+	_ = fs.Parse(args)
+
+	rutorSearchURL := os.Getenv("RUTOR_SEARCH_URL")
+	kinozalSearchURL := os.Getenv("KZ_SEARCH_URL")
+	tmdbAPIKey := os.Getenv("TMDBAPIKEY")
+	mongoURI := os.Getenv("MONGO_URI")
+	if rutorSearchURL == "" || kinozalSearchURL == "" {
+		slog.Error("missing required tracker urls", "required", []string{"RUTOR_SEARCH_URL", "KZ_SEARCH_URL"})
+		return 1
+	}
+	if tmdbAPIKey == "" {
+		slog.Error("missing required tmdb api key", "required", "TMDBAPIKEY")
+		return 1
+	}
+	if *save && mongoURI == "" {
+		slog.Error("mongo save enabled but MONGO_URI is missing")
+		return 1
+	}
+
+	envVars := executor.InitVars(nil, tmdbAPIKey)
+	if mongoURI != "" {
+		envVars.WithMongo(mongoURI)
+	}
+	m := metrics.New()
+	closeCache, err := applyMetadataEnv(envVars, m, *languages, *tmdbCache, *tmdbBaseURL, mongoURI)
+	if err != nil {
+		slog.Error("invalid metadata settings", "error", err)
+		return 1
+	}
+	defer closeCache()
+
+	cfg := api.Config{
+		Search:        pipelineSearch(*envVars, m, rutorSearchURL, kinozalSearchURL, *fullDetails, *save, *collection),
+		SearchTimeout: *searchTimeout,
+	}
+	if mongoURI != "" {
+		connectCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		client, err := executor.ConnectMongo(connectCtx, mongoURI)
+		cancel()
+		if err != nil {
+			slog.Error("failed to connect to mongodb", "error", err)
+			return 1
+		}
+		defer func() { _ = client.Disconnect(context.Background()) }()
+		cfg.Store = api.NewMongoStore(client.Database("movies").Collection(*collection))
+	} else {
+		slog.Warn("MONGO_URI is not set; /movies endpoints are disabled")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	mux.Handle("/", api.New(cfg).Handler())
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      *searchTimeout + 15*time.Second,
+		IdleTimeout:       time.Minute,
+		MaxHeaderBytes:    64 << 10,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("api server listening", "addr", *addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("api server failed", "error", err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	slog.Info("api server shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("api server shutdown failed", "error", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/telegram"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+// parseChatIDs reads a comma separated list such as TELEGRAM_CHAT_ID.
+func parseChatIDs(value string) ([]int64, error) {
+	var ids []int64
+	for field := range strings.SplitSeq(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telegram chat id %q", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// telegramClient builds the Bot API client from TELEGRAM_BOT_TOKEN and
+// TELEGRAM_BASE_URL, together with the chats from TELEGRAM_CHAT_ID; the client
+// is nil when no token is set.
+func telegramClient() (*telegram.Client, []int64, error) {
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if token == "" {
+		return nil, nil, nil
+	}
+	chatIDs, err := parseChatIDs(os.Getenv("TELEGRAM_CHAT_ID"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(chatIDs) == 0 {
+		return nil, nil, errors.New("TELEGRAM_BOT_TOKEN requires TELEGRAM_CHAT_ID")
+	}
+	return telegram.New(token).WithBaseURL(os.Getenv("TELEGRAM_BASE_URL")), chatIDs, nil
+}
+
+// trackerSearch runs the tracker pipeline for a bot /search command.
+func trackerSearch(rutorSearchURL, kinozalSearchURL string, isMovie bool) telegram.SearchFunc {
+	return func(ctx context.Context, title, year string) (_ []*torrents.Torrent, err error) {
+		rutorURL, err := buildTrackerURL(rutorSearchURL, title, year)
+		if err != nil {
+			return nil, err
+		}
+		kinozalURL, err := buildTrackerURL(kinozalSearchURL, title, year)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, span := startSearch(ctx, "telegram.search", title, year, isMovie)
+		defer func() { endSpan(span, err) }()
+
+		pipeline := executor.Init(*executor.InitVars([]string{rutorURL, kinozalURL}, "")).
+			WithContext(ctx).
+			RunTrackersSearchPipeline(isMovie)
+		if err := pipeline.HandleErrors(); err != nil {
+			return nil, err
+		}
+		return pipeline.GetTorrents(), nil
+	}
+}
+
+// runTelegram implements "moviestracker telegram": a bot that answers
+// /search <title> <year> in the chats from TELEGRAM_CHAT_ID.
+func runTelegram(args []string) int {
+	fs := flag.NewFlagSet("telegram", flag.ExitOnError)
+	isMovie := fs.Bool("movie", true, "Set false to search for series")
+	_ = fs.Parse(args)
+
+	rutorSearchURL := os.Getenv("RUTOR_SEARCH_URL")
+	kinozalSearchURL := os.Getenv("KZ_SEARCH_URL")
+	if rutorSearchURL == "" || kinozalSearchURL == "" {
+		slog.Error("missing required tracker urls", "required", []string{"RUTOR_SEARCH_URL", "KZ_SEARCH_URL"})
+		return 1
+	}
+	client, chatIDs, err := telegramClient()
+	if err != nil {
+		slog.Error("invalid telegram settings", "error", err)
+		return 1
+	}
+	if client == nil {
+		slog.Error("missing required telegram bot token", "required", "TELEGRAM_BOT_TOKEN")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("telegram bot started", "chats", len(chatIDs))
+	bot := telegram.NewBot(client, trackerSearch(rutorSearchURL, kinozalSearchURL, *isMovie), chatIDs...)
+	if err := bot.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("telegram bot stopped", "error", err)
+		return 1
+	}
+	return 0
+}
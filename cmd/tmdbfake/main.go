@@ -0,0 +1,45 @@
+// Command tmdbfake serves TMDB fixtures over HTTP for offline runs:
+//
+//	go run ./cmd/tmdbfake -addr :8089
+//	TMDB_BASE_URL=http://localhost:8089 go run ./cmd
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/tmdbfake"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+)
+
+func main() {
+	logger := logging.Init()
+
+	var (
+		addr     = flag.String("addr", ":8089", "Listen address")
+		fixtures = flag.String("fixtures", "", "Fixture directory; empty serves the built-in fixtures")
+		apiKey   = flag.String("api-key", "", "Reject requests with another api_key; empty accepts any")
+	)
+	flag.Parse()
+
+	var files fs.FS = tmdbfake.DefaultFixtures()
+	if *fixtures != "" {
+		files = os.DirFS(*fixtures)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           tmdbfake.NewHandler(files, *apiKey),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	logger.Info("serving tmdb fixtures", "addr", *addr, "fixtures", *fixtures)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("tmdb fake server failed", "error", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"cmp"
+	"context"
+
+	"github.com/lieranderl/moviestracker-package/internal/api"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/lieranderl/moviestracker-package/cmd")
+
+// startSearch starts the root span of one search and tags its log lines with
+// a run id (the API request id when there is one) and the query; the executor
+// stages nest their spans and log attributes under it when the pipeline runs
+// with the returned context.
+func startSearch(ctx context.Context, name, query, year string, movie bool) (context.Context, trace.Span) {
+	ctx = logging.With(ctx, "run_id", cmp.Or(api.RequestID(ctx), logging.NewRunID()), "query", query)
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("search.query", query),
+		attribute.String("search.year", year),
+		attribute.Bool("search.movie", movie),
+	))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/download"
+	"github.com/lieranderl/moviestracker-package/internal/metrics"
+	"github.com/lieranderl/moviestracker-package/internal/notify"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// trackerCheck runs the tracker pipeline for a saved query, using the search
+// url templates from RUTOR_SEARCH_URL and KZ_SEARCH_URL, and diffs the
+// deduplicated torrents against store. Every run is recorded in m.
+func trackerCheck(m *metrics.Metrics, rutorSearchURL, kinozalSearchURL string, store watch.Store, minSeedDelta int32, notifier notify.Notifier, downloader download.Client, downloadOpts download.AddOptions) watch.CheckFunc {
+	return func(ctx context.Context, q watch.Query) ([]watch.Event, error) {
+		rutorURL, err := buildTrackerURL(rutorSearchURL, q.Query, q.Year)
+		if err != nil {
+			return nil, err
+		}
+		kinozalURL, err := buildTrackerURL(kinozalSearchURL, q.Query, q.Year)
+		if err != nil {
+			return nil, err
+		}
+
+		envVars := executor.InitVars([]string{rutorURL, kinozalURL}, "")
+		if notifier != nil {
+			envVars.WithNotifier(notifier)
+		}
+		if downloader != nil {
+			envVars.WithDownloadClient(downloader, downloadOpts)
+		}
+		ctx, span := startSearch(ctx, "watch.check", q.Query, q.Year, q.IsMovie())
+		span.SetAttributes(attribute.String("watch.query", q.Name))
+		run := m.Run()
+		pipeline := executor.Init(*envVars).
+			WithContext(ctx).
+			WithObserver(run).
+			RunTrackersSearchPipeline(q.IsMovie()).
+			Watch(q, store, minSeedDelta).
+			SendToClient(q)
+		err = pipeline.HandleErrors()
+		endSpan(span, err)
+		slog.DebugContext(ctx, "watch run summary", "summary", run.Done(err))
+		if err != nil {
+			return nil, err
+		}
+		return pipeline.Events(), nil
+	}
+}
+
+// watchNotifier combines the webhook (WEBHOOK_URL, WEBHOOK_SECRET,
+// WEBHOOK_TEMPLATE) and Telegram (TELEGRAM_BOT_TOKEN, TELEGRAM_CHAT_ID)
+// notifiers that are configured; it is nil when there are none.
+func watchNotifier() (notify.Notifier, error) {
+	var notifiers notify.Multi
+	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
+		tmpl, err := notify.ParseTemplate(os.Getenv("WEBHOOK_TEMPLATE"))
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notify.NewWebhook(webhookURL, os.Getenv("WEBHOOK_SECRET")).WithTemplate(tmpl))
+	}
+
+	client, chatIDs, err := telegramClient()
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		telegramNotifier := notify.NewTelegram(client, chatIDs...)
+		if text := os.Getenv("TELEGRAM_TEMPLATE"); text != "" {
+			tmpl, err := notify.ParseTemplate(text)
+			if err != nil {
+				return nil, err
+			}
+			telegramNotifier.WithTemplate(tmpl)
+		}
+		notifiers = append(notifiers, telegramNotifier)
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+	return notifiers, nil
+}
+
+// downloadClient builds the torrent client for watch rules: qBittorrent from
+// QBITTORRENT_URL, QBITTORRENT_USERNAME and QBITTORRENT_PASSWORD with default
+// QBITTORRENT_CATEGORY, QBITTORRENT_SAVE_PATH and QBITTORRENT_TAGS, or
+// Transmission from TRANSMISSION_URL, TRANSMISSION_USERNAME and
+// TRANSMISSION_PASSWORD with default TRANSMISSION_DOWNLOAD_DIR and
+// TRANSMISSION_LABELS. It is nil when neither is set.
+func downloadClient() (download.Client, download.AddOptions, error) {
+	qbURL := os.Getenv("QBITTORRENT_URL")
+	trURL := os.Getenv("TRANSMISSION_URL")
+	switch {
+	case qbURL != "" && trURL != "":
+		return nil, download.AddOptions{}, errors.New("set either QBITTORRENT_URL or TRANSMISSION_URL, not both")
+	case qbURL != "":
+		client := download.NewQBittorrent(qbURL, os.Getenv("QBITTORRENT_USERNAME"), os.Getenv("QBITTORRENT_PASSWORD"))
+		return client, download.AddOptions{
+			Category: os.Getenv("QBITTORRENT_CATEGORY"),
+			SavePath: os.Getenv("QBITTORRENT_SAVE_PATH"),
+			Tags:     download.SplitTags(os.Getenv("QBITTORRENT_TAGS")),
+		}, nil
+	case trURL != "":
+		client := download.NewTransmission(trURL, os.Getenv("TRANSMISSION_USERNAME"), os.Getenv("TRANSMISSION_PASSWORD"))
+		return client, download.AddOptions{
+			SavePath: os.Getenv("TRANSMISSION_DOWNLOAD_DIR"),
+			Tags:     download.SplitTags(os.Getenv("TRANSMISSION_LABELS")),
+		}, nil
+	default:
+		return nil, download.AddOptions{}, nil
+	}
+}
+
+// serveMetrics serves m on addr/metrics in the background.
+func serveMetrics(addr string, m *metrics.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		slog.Info("metrics server listening", "addr", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed", "error", err)
+		}
+	}()
+	return srv
+}
+
+func logEvents(ctx context.Context, q watch.Query, events []watch.Event) {
+	for _, e := range events {
+		slog.InfoContext(ctx, "watch event",
+			"type", e.Type,
+			"query", q.Name,
+			"torrent", e.Torrent.Name,
+			"magnet_hash", e.Torrent.MagnetHash,
+			"seeds", e.Torrent.Seeds,
+			"previous_seeds", e.PreviousSeeds,
+			"quality", e.Torrent.QualityRank(),
+			"previous_quality", e.PreviousQuality,
+		)
+	}
+}
+
+// runWatch implements "moviestracker watch": it reruns the saved queries of a
+// watch file on their schedules and logs what changed.
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	var (
+		configPath  = fs.String("config", envOrDefault("WATCH_CONFIG", "watch.json"), "Watch file with the saved queries")
+		statePath   = fs.String("state", envOrDefault("WATCH_STATE", "watch-state.json"), "File that keeps the results of previous runs")
+		once        = fs.Bool("once", false, "Check every query once and exit instead of running as a daemon")
+		metricsAddr = fs.String("metrics-addr", os.Getenv("METRICS_ADDR"), "Listen address of the Prometheus /metrics endpoint; empty disables it")
+	)
+	_ = fs.Parse(args)
+
+	rutorSearchURL := os.Getenv("RUTOR_SEARCH_URL")
+	kinozalSearchURL := os.Getenv("KZ_SEARCH_URL")
+	if rutorSearchURL == "" || kinozalSearchURL == "" {
+		slog.Error("missing required tracker urls", "required", []string{"RUTOR_SEARCH_URL", "KZ_SEARCH_URL"})
+		return 1
+	}
+
+	cfg, err := watch.LoadConfig(*configPath)
+	if err != nil {
+		slog.Error("failed to load watch config", "error", err)
+		return 1
+	}
+	notifier, err := watchNotifier()
+	if err != nil {
+		slog.Error("invalid notification settings", "error", err)
+		return 1
+	}
+	downloader, downloadOpts, err := downloadClient()
+	if err != nil {
+		slog.Error("invalid torrent client settings", "error", err)
+		return 1
+	}
+	m := metrics.New()
+	check := trackerCheck(m, rutorSearchURL, kinozalSearchURL, watch.NewFileStore(*statePath), cfg.MinSeedDelta, notifier, downloader, downloadOpts)
+	watcher, err := watch.New(cfg, check, logEvents)
+	if err != nil {
+		slog.Error("failed to start watch", "error", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		if err := watcher.RunOnce(ctx); err != nil {
+			slog.Error("watch run failed", "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *metricsAddr != "" {
+		srv := serveMetrics(*metricsAddr, m)
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+	}
+
+	slog.Info("watching saved queries", "queries", len(cfg.Queries), "config", *configPath, "state", *statePath)
+	if err := watcher.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("watch stopped", "error", err)
+		return 1
+	}
+	return 0
+}
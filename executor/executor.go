@@ -5,15 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/lieranderl/moviestracker-package/internal/kinozal"
+	"github.com/lieranderl/moviestracker-package/internal/download"
 	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/notify"
 	"github.com/lieranderl/moviestracker-package/internal/rutor"
 	"github.com/lieranderl/moviestracker-package/internal/torrents"
 	"github.com/lieranderl/moviestracker-package/internal/tracker"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
@@ -21,12 +25,31 @@ import (
 const (
 	DBTypeMongo = "mongo"
 	mongoDBName = "movies"
+
+	// detailsCollectionSuffix names the collection Full details are saved to,
+	// next to the Short movies collection.
+	detailsCollectionSuffix = "_details"
+
+	rutorDetailsConcurrency = 5
 )
 
 type config struct {
-	urls       []string
-	tmdbAPIKey string
-	mongoURI   string
+	urls         []string
+	tmdbAPIKey   string
+	mongoURI     string
+	tmdbCache    cache.Cache
+	tmdbCacheTTL time.Duration
+	tmdbBaseURL  string
+	tmdbRate     float64
+	languages    []string
+	kinopoiskKey string
+	kinopoiskURL string
+	omdbKey      string
+	omdbURL      string
+	notifier     notify.Notifier
+	downloader   download.Client
+	downloadOpts download.AddOptions
+	clients      *metadataClients
 }
 
 func initConfig(urls []string, tmdbKey string) *config {
@@ -42,37 +65,166 @@ func (c *config) WithMongo(mongoURI string) *config {
 }
 
 type TrackersPipeline struct {
-	torrents []*torrents.Torrent
-	movies   []*movies.Short
-	config   config
-	errors   []error
+	torrents  []*torrents.Torrent
+	movies    []*movies.Short
+	details   []*movies.Full
+	genres    *movies.GenreCatalog
+	config    config
+	ctx       context.Context
+	events    []watch.Event
+	baseline  []watch.Event
+	sent      []*torrents.Torrent
+	observers []Observer
+	errors    []error
+	skipped   []error
+	// stage is the running stage, for OnError.
+	stage string
 }
 
 func (p *TrackersPipeline) GetTorrents() []*torrents.Torrent {
 	return p.torrents
 }
 
+// GetMovies returns the movies built by ConvertTorrentsToMovieShort and
+// enriched by the later stages.
+func (p *TrackersPipeline) GetMovies() []*movies.Short {
+	return p.movies
+}
+
+// Skipped returns the per-movie TMDB failures that Tmdb and Details left out
+// instead of failing the pipeline.
+func (p *TrackersPipeline) Skipped() []error {
+	return p.skipped
+}
+
+// Events returns what the Watch stage detected, before notification rules.
+func (p *TrackersPipeline) Events() []watch.Event {
+	return p.events
+}
+
+// Sent returns the releases SendToClient added to the torrent client.
+func (p *TrackersPipeline) Sent() []*torrents.Torrent {
+	return p.sent
+}
+
 type EnvVars struct {
-	urls       []string
-	tmdbAPIKey string
-	mongoURI   string
+	urls         []string
+	tmdbAPIKey   string
+	mongoURI     string
+	tmdbCache    cache.Cache
+	tmdbCacheTTL time.Duration
+	tmdbBaseURL  string
+	tmdbRate     float64
+	languages    []string
+	kinopoiskKey string
+	kinopoiskURL string
+	omdbKey      string
+	omdbURL      string
+	notifier     notify.Notifier
+	downloader   download.Client
+	downloadOpts download.AddOptions
+	clients      *metadataClients
 }
 
+// InitVars returns the settings pipelines are built from. Pipelines built
+// from the same EnvVars, or from copies of it, share their TMDB, Kinopoisk and
+// OMDb clients, so configure those before the first pipeline runs.
 func InitVars(urls []string, tmdbKey string) *EnvVars {
 	return &EnvVars{
 		urls:       append([]string(nil), urls...),
 		tmdbAPIKey: tmdbKey,
+		clients:    new(metadataClients),
 	}
 }
 
+// WithURLs replaces the tracker search urls, e.g. on a copy of shared
+// settings for each search of a server.
+func (e *EnvVars) WithURLs(urls ...string) *EnvVars {
+	e.urls = append([]string(nil), urls...)
+	return e
+}
+
 func (e *EnvVars) WithMongo(mongoURI string) *EnvVars {
 	e.mongoURI = strings.TrimSpace(mongoURI)
 	return e
 }
 
+// WithTMDBCache makes the Tmdb stage reuse search, find and image responses
+// from c for ttl (movies.DefaultCacheTTL when ttl <= 0).
+func (e *EnvVars) WithTMDBCache(c cache.Cache, ttl time.Duration) *EnvVars {
+	e.tmdbCache = c
+	e.tmdbCacheTTL = ttl
+	return e
+}
+
+// WithTMDBBaseURL points the TMDB stages at a TMDB compatible server, such as
+// the local stand-in from cmd/tmdbfake. Empty means the public API.
+func (e *EnvVars) WithTMDBBaseURL(baseURL string) *EnvVars {
+	e.tmdbBaseURL = strings.TrimSpace(baseURL)
+	return e
+}
+
+// WithTMDBRateLimit caps TMDB requests per second across all workers of all
+// pipelines built from these settings (movies.DefaultRateLimit when
+// perSecond <= 0).
+func (e *EnvVars) WithTMDBRateLimit(perSecond float64) *EnvVars {
+	e.tmdbRate = perSecond
+	return e
+}
+
+// WithKinopoisk adds the unofficial Kinopoisk API as a fallback for movies TMDB
+// does not match and as a source of Kinopoisk and IMDb ratings. An empty
+// baseURL uses movies.KinopoiskBaseURL.
+func (e *EnvVars) WithKinopoisk(apiKey, baseURL string) *EnvVars {
+	e.kinopoiskKey = strings.TrimSpace(apiKey)
+	e.kinopoiskURL = strings.TrimSpace(baseURL)
+	return e
+}
+
+// WithOMDb enables the Ratings stage. An empty baseURL uses movies.OMDbBaseURL.
+func (e *EnvVars) WithOMDb(apiKey, baseURL string) *EnvVars {
+	e.omdbKey = strings.TrimSpace(apiKey)
+	e.omdbURL = strings.TrimSpace(baseURL)
+	return e
+}
+
+// WithNotifier makes the Watch stage send the events it detects through n.
+func (e *EnvVars) WithNotifier(n notify.Notifier) *EnvVars {
+	e.notifier = n
+	return e
+}
+
+// WithDownloadClient makes SendToClient add releases to c with defaults as the
+// options a query's download rule does not override.
+func (e *EnvVars) WithDownloadClient(c download.Client, defaults download.AddOptions) *EnvVars {
+	e.downloader = c
+	e.downloadOpts = defaults
+	return e
+}
+
+// WithLanguages sets the TMDB languages movie metadata is stored in. The first
+// language is used for matching; the default is "ru".
+func (e *EnvVars) WithLanguages(languages ...string) *EnvVars {
+	e.languages = append([]string(nil), languages...)
+	return e
+}
+
 func Init(env EnvVars) *TrackersPipeline {
 	tp := new(TrackersPipeline)
 	tp.config = *(initConfig(env.urls, env.tmdbAPIKey))
+	tp.config.tmdbCache = env.tmdbCache
+	tp.config.tmdbCacheTTL = env.tmdbCacheTTL
+	tp.config.tmdbBaseURL = env.tmdbBaseURL
+	tp.config.tmdbRate = env.tmdbRate
+	tp.config.kinopoiskKey = env.kinopoiskKey
+	tp.config.kinopoiskURL = env.kinopoiskURL
+	tp.config.omdbKey = env.omdbKey
+	tp.config.omdbURL = env.omdbURL
+	tp.config.notifier = env.notifier
+	tp.config.downloader = env.downloader
+	tp.config.downloadOpts = env.downloadOpts
+	tp.config.languages = env.languages
+	tp.config.clients = env.clients
 
 	if env.mongoURI != "" {
 		tp.config.WithMongo(env.mongoURI)
@@ -81,9 +233,24 @@ func Init(env EnvVars) *TrackersPipeline {
 	return tp
 }
 
+// WithContext makes the following stages stop when ctx is done, e.g. when the
+// HTTP request that started the pipeline goes away.
+func (p *TrackersPipeline) WithContext(ctx context.Context) *TrackersPipeline {
+	p.ctx = ctx
+	return p
+}
+
+func (p *TrackersPipeline) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
 func (p *TrackersPipeline) addError(err error) {
 	if err != nil {
 		p.errors = append(p.errors, err)
+		p.notify(func(ctx context.Context, o Observer) { o.OnError(ctx, p.stage, err) })
 	}
 }
 
@@ -97,11 +264,24 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
+// parseYear converts a tracker year such as "2024" to a number; anything that
+// is not a plausible year becomes 0 (unknown).
+func parseYear(value string) int {
+	year, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || year < 1870 || year > 2200 {
+		return 0
+	}
+	return year
+}
+
 func (p *TrackersPipeline) ConvertTorrentsToMovieShort() *TrackersPipeline {
 	if len(p.errors) > 0 {
 		return p
 	}
 
+	done := p.startStage(StageMovies, StageStats{Torrents: len(p.torrents)})
+	defer func() { done(StageStats{Movies: len(p.movies)}) }()
+
 	grouped := make(map[string]*movies.Short)
 	order := make([]string, 0, len(p.torrents))
 
@@ -118,15 +298,22 @@ func (p *TrackersPipeline) ConvertTorrentsToMovieShort() *TrackersPipeline {
 		movie, found := grouped[hash]
 		if !found {
 			movie = &movies.Short{
-				Hash:       hash,
-				Searchname: firstNonEmpty(movieTorrent.OriginalName, movieTorrent.RussianName, movieTorrent.Name),
-				Year:       movieTorrent.Year,
-				Torrents:   make([]*torrents.Torrent, 0, 1),
+				Hash:          hash,
+				Searchname:    firstNonEmpty(movieTorrent.OriginalName, movieTorrent.RussianName, movieTorrent.Name),
+				AltSearchname: strings.TrimSpace(movieTorrent.RussianName),
+				Year:          parseYear(movieTorrent.Year),
+				Torrents:      make([]*torrents.Torrent, 0, 1),
 			}
 			grouped[hash] = movie
 			order = append(order, hash)
-		} else if movie.Year == "" && movieTorrent.Year != "" {
-			movie.Year = movieTorrent.Year
+		} else if movie.Year == 0 {
+			movie.Year = parseYear(movieTorrent.Year)
+		}
+		if movie.ImdbID == "" {
+			movie.ImdbID = movieTorrent.ImdbID
+		}
+		if movie.KinopoiskID == "" {
+			movie.KinopoiskID = movieTorrent.KinopoiskID
 		}
 
 		movie.Torrents = append(movie.Torrents, movieTorrent)
@@ -136,7 +323,6 @@ func (p *TrackersPipeline) ConvertTorrentsToMovieShort() *TrackersPipeline {
 	for _, hash := range order {
 		movie := grouped[hash]
 		movie.UpdateMoviesAttribs()
-		movie.Torrents = nil
 		shortMovies = append(shortMovies, movie)
 	}
 
@@ -144,29 +330,191 @@ func (p *TrackersPipeline) ConvertTorrentsToMovieShort() *TrackersPipeline {
 	return p
 }
 
+// Tmdb matches movies with TMDB and, when WithKinopoisk is configured, falls
+// back to Kinopoisk and merges both sources' ratings into Short.Ratings.
+// Movies that matched the same id are merged into one with all their
+// torrents, see movies.MergeByID.
 func (p *TrackersPipeline) Tmdb() *TrackersPipeline {
 	if len(p.errors) > 0 {
 		return p
 	}
 
-	slog.Info("tmdb enrichment started", "movies", len(p.movies))
+	var skipped []error
+	done := p.startStage(StageTmdb, StageStats{Movies: len(p.movies)})
+	defer func() { done(StageStats{Movies: len(p.movies), Skipped: len(skipped)}) }()
+	slog.InfoContext(p.context(), "tmdb enrichment started", "movies", len(p.movies))
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(p.context())
 	defer cancel()
 
-	movieChan, errorChan := movies.MoviesPipelineStream(ctx, p.movies, p.config.tmdbAPIKey, 20)
-	enrichedMovies, err := movies.ChannelToMovies(ctx, cancel, movieChan, errorChan)
+	provider := p.metadataProvider()
+	if len(p.observers) > 0 {
+		provider = observedProvider{Provider: provider, matched: p.movieEnriched(StageTmdb)}
+	}
+	movieChan, errorChan := movies.MoviesPipelineStream(ctx, p.movies, provider, 20)
+	enrichedMovies, skipped, err := movies.ChannelToMovies(ctx, cancel, movieChan, errorChan)
+	p.skipped = append(p.skipped, skipped...)
 	if err != nil {
 		p.addError(err)
 		return p
 	}
 
-	p.movies = enrichedMovies
-	slog.Info("tmdb enrichment completed", "movies", len(p.movies))
+	p.movies = movies.MergeByID(enrichedMovies)
+	slog.InfoContext(p.context(), "tmdb enrichment completed", "movies", len(p.movies), "merged", len(enrichedMovies)-len(p.movies), "skipped", len(skipped))
 	return p
 }
 
-func connectMongo(ctx context.Context, mongoURI string) (*mongo.Client, error) {
+// Details fetches full TMDB details (credits, videos, release dates, external
+// ids) for every matched movie. SaveToMongo stores them in the
+// "<collection>_details" collection.
+func (p *TrackersPipeline) Details() *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+
+	var skipped []error
+	done := p.startStage(StageDetails, StageStats{Movies: len(p.movies)})
+	defer func() { done(StageStats{Movies: len(p.details), Skipped: len(skipped)}) }()
+	slog.InfoContext(p.context(), "tmdb details started", "movies", len(p.movies))
+
+	ctx, cancel := context.WithCancel(p.context())
+	defer cancel()
+
+	detailsChan, errorChan := movies.FullPipelineStream(ctx, p.movies, p.tmdbClient(), 20)
+	details, skipped, err := movies.ChannelToFull(ctx, cancel, detailsChan, errorChan)
+	p.skipped = append(p.skipped, skipped...)
+	if err != nil {
+		p.addError(err)
+		return p
+	}
+
+	p.details = details
+	slog.InfoContext(p.context(), "tmdb details completed", "details", len(p.details), "skipped", len(skipped))
+	return p
+}
+
+// Genres resolves the TMDB genre ids of every movie to names in each configured
+// language. The catalog is fetched once per pipeline and falls back to an
+// embedded ru/en table when TMDB is unavailable, so this stage never fails.
+func (p *TrackersPipeline) Genres() *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+
+	done := p.startStage(StageGenres, StageStats{Movies: len(p.movies)})
+	defer func() { done(StageStats{Movies: len(p.movies)}) }()
+
+	client := p.tmdbClient()
+	if p.genres == nil {
+		p.genres = client.LoadGenres(p.context())
+	}
+	for _, movie := range p.movies {
+		p.genres.Resolve(movie, client.Languages())
+	}
+
+	slog.InfoContext(p.context(), "genre names resolved", "movies", len(p.movies))
+	return p
+}
+
+// Ratings adds IMDb, Rotten Tomatoes and Metacritic ratings from OMDb to every
+// movie with an IMDb id, looking missing ids up in TMDB first. It is skipped
+// without an OMDb key. Responses share the TMDB cache. A rejected key or an
+// exhausted quota stops the stage but not the pipeline.
+func (p *TrackersPipeline) Ratings() *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+	if p.config.omdbKey == "" {
+		slog.InfoContext(p.context(), "omdb ratings skipped", "reason", "no api key")
+		return p
+	}
+
+	var skipped []error
+	done := p.startStage(StageRatings, StageStats{Movies: len(p.movies)})
+	defer func() { done(StageStats{Movies: len(p.movies), Skipped: len(skipped)}) }()
+	slog.InfoContext(p.context(), "omdb ratings started", "movies", len(p.movies))
+
+	client := p.tmdbClient()
+	omdb := p.metadataClients().omdb
+	rated := p.movieEnriched(StageRatings)
+	rate := func(ctx context.Context, m *movies.Short) error {
+		if err := client.FillIMDbID(ctx, m); err != nil {
+			return err
+		}
+		if err := omdb.Rate(ctx, m); err != nil {
+			return err
+		}
+		rated(ctx, m)
+		return nil
+	}
+
+	skipped, err := movies.RateMovies(p.context(), p.movies, rate, 10)
+	p.skipped = append(p.skipped, skipped...)
+	if err != nil && p.context().Err() != nil {
+		// The run was cancelled or timed out, unlike a rejected key or an
+		// exhausted quota, which only cost the movies their ratings.
+		p.addError(err)
+		return p
+	}
+	if err != nil {
+		slog.ErrorContext(p.context(), "omdb ratings stopped", "error", err)
+	}
+
+	slog.InfoContext(p.context(), "omdb ratings completed", "movies", len(p.movies), "skipped", len(skipped))
+	return p
+}
+
+// metadataProvider is TMDB alone or, with a Kinopoisk key, TMDB followed by
+// Kinopoisk.
+func (p *TrackersPipeline) metadataProvider() movies.Provider {
+	clients := p.metadataClients()
+	if clients.kinopoisk == nil {
+		return clients.tmdb
+	}
+	return movies.NewChain(clients.tmdb, clients.kinopoisk)
+}
+
+func (p *TrackersPipeline) tmdbClient() *movies.TMDb {
+	return p.metadataClients().tmdb
+}
+
+// metadataClients holds the TMDB, Kinopoisk and OMDb clients shared by the
+// pipelines of one EnvVars, so that their rate limits and Retry-After pauses
+// hold across concurrent searches instead of per pipeline. They are built
+// from the settings of the first pipeline that needs them.
+type metadataClients struct {
+	once      sync.Once
+	tmdb      *movies.TMDb
+	kinopoisk *movies.Kinopoisk
+	omdb      *movies.OMDb
+}
+
+func (p *TrackersPipeline) metadataClients() *metadataClients {
+	if p.config.clients == nil {
+		p.config.clients = new(metadataClients)
+	}
+	c, cfg := p.config.clients, p.config
+	c.once.Do(func() {
+		c.tmdb = movies.TMDBInit(cfg.tmdbAPIKey).
+			WithBaseURL(cfg.tmdbBaseURL).
+			WithRateLimit(cfg.tmdbRate).
+			WithLanguages(cfg.languages...).
+			WithCache(cfg.tmdbCache, cfg.tmdbCacheTTL)
+		if cfg.kinopoiskKey != "" {
+			c.kinopoisk = movies.KinopoiskInit(cfg.kinopoiskKey).WithBaseURL(cfg.kinopoiskURL)
+		}
+		if cfg.omdbKey != "" {
+			c.omdb = movies.OMDbInit(cfg.omdbKey).
+				WithBaseURL(cfg.omdbURL).
+				WithCache(cfg.tmdbCache, cfg.tmdbCacheTTL)
+		}
+	})
+	return c
+}
+
+// ConnectMongo connects with the pool and timeout settings the save stages
+// use and pings the server.
+func ConnectMongo(ctx context.Context, mongoURI string) (*mongo.Client, error) {
 	clientOptions := options.Client().
 		ApplyURI(mongoURI).
 		SetServerSelectionTimeout(10 * time.Second).
@@ -194,11 +542,14 @@ func (p *TrackersPipeline) SaveToMongo(collection string) *TrackersPipeline {
 		return p
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	done := p.startStage(StageSave, StageStats{Movies: len(p.movies)})
+	defer func() { done(StageStats{Movies: len(p.movies)}) }()
+
+	ctx, cancel := context.WithTimeout(p.context(), 60*time.Second)
 	defer cancel()
-	slog.Info("mongodb save started", "collection", collection, "movies", len(p.movies))
+	slog.InfoContext(p.context(), "mongodb save started", "collection", collection, "movies", len(p.movies))
 
-	client, err := connectMongo(ctx, p.config.mongoURI)
+	client, err := ConnectMongo(ctx, p.config.mongoURI)
 	if err != nil {
 		p.addError(err)
 		return p
@@ -208,11 +559,54 @@ func (p *TrackersPipeline) SaveToMongo(collection string) *TrackersPipeline {
 	}()
 
 	moviesCollection := client.Database(mongoDBName).Collection(collection)
+	writeCtx, written := p.traceMongoWrite(ctx, collection, len(p.movies))
 	for _, movie := range p.movies {
-		p.addError(movie.WriteMovieToMongo(ctx, moviesCollection))
+		p.addError(movie.WriteMovieToMongo(writeCtx, moviesCollection))
+	}
+	written()
+
+	detailsCollection := client.Database(mongoDBName).Collection(collection + detailsCollectionSuffix)
+	writeCtx, written = p.traceMongoWrite(ctx, collection+detailsCollectionSuffix, len(p.details))
+	for _, details := range p.details {
+		p.addError(details.WriteFullToMongo(writeCtx, detailsCollection))
+	}
+	written()
+
+	slog.InfoContext(p.context(), "mongodb save finished", "collection", collection, "movies", len(p.movies), "details", len(p.details))
+
+	return p
+}
+
+// MigrateMongo converts movies saved with string vote_average, vote_count, year
+// and release_date fields to the typed representation. It is safe to run more
+// than once.
+func (p *TrackersPipeline) MigrateMongo(collection string) *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+	if p.config.mongoURI == "" {
+		p.addError(errors.New("mongo uri is required to migrate movies"))
+		return p
 	}
 
-	slog.Info("mongodb save finished", "collection", collection, "movies", len(p.movies))
+	done := p.startStage(StageMigrate, StageStats{})
+	defer func() { done(StageStats{}) }()
+
+	ctx, cancel := context.WithTimeout(p.context(), 5*time.Minute)
+	defer cancel()
+
+	client, err := ConnectMongo(ctx, p.config.mongoURI)
+	if err != nil {
+		p.addError(err)
+		return p
+	}
+	defer func() {
+		p.addError(client.Disconnect(context.Background()))
+	}()
+
+	modified, err := movies.MigrateLegacyFields(ctx, client.Database(mongoDBName).Collection(collection))
+	p.addError(err)
+	slog.InfoContext(p.context(), "mongodb migration finished", "collection", collection, "modified_fields", modified)
 
 	return p
 }
@@ -235,36 +629,20 @@ func (p *TrackersPipeline) RunTrackersSearchPipeline(isMovie bool) *TrackersPipe
 	if len(p.errors) > 0 {
 		return p
 	}
-	if len(p.config.urls) < 2 {
-		p.addError(errors.New("at least two tracker urls are required: rutor and kinozal"))
-		return p
-	}
-
-	var rutorParser func(string) ([]*torrents.Torrent, error)
-	var kinozalParser func(string) ([]*torrents.Torrent, error)
+	done := p.startStage(StageTrackers, StageStats{})
+	defer func() { done(StageStats{Torrents: len(p.torrents)}) }()
 
-	if isMovie {
-		rutorParser = rutor.ParseMoviePage
-		kinozalParser = kinozal.ParseMoviePage
-	} else {
-		rutorParser = rutor.ParseSeriesPage
-		kinozalParser = kinozal.ParseSeriesPage
+	sources, err := p.trackerSources(isMovie)
+	if err != nil {
+		p.addError(err)
+		return p
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(p.context())
 	defer cancel()
 
-	rutorTracker := tracker.Init(tracker.Config{
-		Urls:          []string{p.config.urls[0]},
-		TrackerParser: rutorParser,
-	})
-	kinozalTracker := tracker.Init(tracker.Config{
-		Urls:          []string{p.config.urls[1]},
-		TrackerParser: kinozalParser,
-	})
-
-	rutorTorrents, rutorErrors := rutorTracker.TorrentsPipelineStream(ctx)
-	kinozalTorrents, kinozalErrors := kinozalTracker.TorrentsPipelineStream(ctx)
+	rutorTorrents, rutorErrors := sources[0].tracker.TorrentsPipelineStream(ctx)
+	kinozalTorrents, kinozalErrors := sources[1].tracker.TorrentsPipelineStream(ctx)
 
 	var (
 		rutorResult   []*torrents.Torrent
@@ -291,11 +669,12 @@ func (p *TrackersPipeline) RunTrackersSearchPipeline(isMovie bool) *TrackersPipe
 	if kinozalErr != nil && !errors.Is(kinozalErr, context.Canceled) {
 		p.addError(kinozalErr)
 	}
+	p.addError(p.context().Err())
 	if len(p.errors) > 0 {
 		return p
 	}
 
-	slog.Info(
+	slog.InfoContext(p.context(),
 		"tracker results",
 		"rutor_torrents", len(rutorResult),
 		"kinozal_torrents", len(kinozalResult),
@@ -304,12 +683,159 @@ func (p *TrackersPipeline) RunTrackersSearchPipeline(isMovie bool) *TrackersPipe
 	ts := append(rutorResult, kinozalResult...)
 	before := len(ts)
 	ts = torrents.RemoveDuplicatesInPlace(ts)
-	slog.Info("tracker search completed", "torrents_before_dedupe", before, "torrents_after_dedupe", len(ts))
+	slog.InfoContext(p.context(), "tracker search completed", "torrents_before_dedupe", before, "torrents_after_dedupe", len(ts))
 
 	p.torrents = ts
 	return p
 }
 
+// Watch diffs the deduplicated torrents against the snapshot store keeps for q
+// and sends the events that pass q.Notify through the configured notifier.
+// Rutor releases among them get their details page fetched first, unless
+// EnrichRutorDetails already ran, so alerts can show the poster. The first run
+// of a query only records a baseline. Failed deliveries are logged; they do
+// not fail the pipeline because the new state is already saved.
+func (p *TrackersPipeline) Watch(q watch.Query, store watch.Store, minSeedDelta int32) *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+	done := p.startStage(StageWatch, StageStats{Torrents: len(p.torrents)})
+	defer func() { done(StageStats{Torrents: len(p.events)}) }()
+
+	ctx, cancel := context.WithTimeout(p.context(), 5*time.Minute)
+	defer cancel()
+
+	events, baseline, err := watch.DetectWithBaseline(ctx, store, q.Name, p.torrents, minSeedDelta, time.Now())
+	if err != nil {
+		p.addError(fmt.Errorf("watch %q: %w", q.Name, err))
+		return p
+	}
+	p.events, p.baseline = events, baseline
+
+	matched := q.Notify.Filter(events)
+	if p.config.notifier == nil || len(matched) == 0 {
+		return p
+	}
+	p.enrichEventDetails(ctx, matched)
+	if err := p.config.notifier.Notify(ctx, matched); err != nil {
+		slog.ErrorContext(p.context(), "watch notification failed", "query", q.Name, "events", len(matched), "error", err)
+	}
+	return p
+}
+
+// SendToClient adds the new releases found by Watch that pass q.Download to
+// the configured torrent client, skipping torrents the client already has. On
+// the first run of a query every release found counts as new, so releases
+// that were out before the query was added are downloaded too. Seed count
+// changes are never downloaded. Like notifications, failures are logged
+// instead of failing the pipeline.
+func (p *TrackersPipeline) SendToClient(q watch.Query) *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+	if p.config.downloader == nil || q.Download == nil {
+		return p
+	}
+
+	releases := p.events
+	if p.baseline != nil {
+		releases = p.baseline
+	}
+	releases = q.Download.Filter(releases)
+	done := p.startStage(StageSendToClient, StageStats{Torrents: len(releases)})
+	sent := len(p.sent)
+	defer func() { done(StageStats{Torrents: len(p.sent) - sent}) }()
+
+	ctx, cancel := context.WithTimeout(p.context(), 2*time.Minute)
+	defer cancel()
+
+	client := p.config.downloader
+	opts := downloadOptions(p.config.downloadOpts, q.Download)
+	for _, e := range releases {
+		if e.Type == watch.EventSeedsChanged {
+			continue
+		}
+		t := e.Torrent
+		log := slog.With("query", q.Name, "client", client.Name(), "torrent", t.Name, "magnet_hash", t.MagnetHash)
+
+		_, known, err := client.Status(ctx, t.MagnetHash)
+		if err != nil {
+			log.ErrorContext(ctx, "torrent client lookup failed", "error", err)
+			if errors.Is(err, download.ErrUnauthorized) || ctx.Err() != nil {
+				return p
+			}
+			continue
+		}
+		if known {
+			log.DebugContext(ctx, "torrent already in client")
+			continue
+		}
+
+		magnet := t.Magnet
+		if magnet == "" {
+			magnet = "magnet:?xt=urn:btih:" + t.MagnetHash
+		}
+		if err := client.Add(ctx, magnet, opts); err != nil {
+			log.ErrorContext(ctx, "failed to send torrent to client", "error", err)
+			continue
+		}
+		log.InfoContext(ctx, "torrent sent to client", "category", opts.Category)
+		p.sent = append(p.sent, t)
+	}
+	return p
+}
+
+// enrichEventDetails fetches the rutor details pages of the event torrents that
+// have no poster yet. A failure only costs the alert its poster.
+func (p *TrackersPipeline) enrichEventDetails(ctx context.Context, events []watch.Event) {
+	pending := make([]*torrents.Torrent, 0, len(events))
+	for _, e := range events {
+		if e.Torrent != nil && e.Torrent.Poster == "" {
+			pending = append(pending, e.Torrent)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if err := rutor.EnrichDetails(ctx, pending, rutorDetailsConcurrency); err != nil {
+		slog.WarnContext(ctx, "rutor details for watch events failed", "events", len(pending), "error", err)
+	}
+}
+
+func downloadOptions(defaults download.AddOptions, rule *watch.DownloadRule) download.AddOptions {
+	opts := defaults
+	if rule.Category != "" {
+		opts.Category = rule.Category
+	}
+	if rule.SavePath != "" {
+		opts.SavePath = rule.SavePath
+	}
+	if len(rule.Tags) > 0 {
+		opts.Tags = rule.Tags
+	}
+	return opts
+}
+
+// EnrichRutorDetails visits rutor details pages of the found torrents to pick up
+// IMDb/Kinopoisk ids, poster, description, file list and exact upload time.
+// Failed pages are skipped, so the stage only fails on cancellation.
+func (p *TrackersPipeline) EnrichRutorDetails() *TrackersPipeline {
+	if len(p.errors) > 0 {
+		return p
+	}
+
+	done := p.startStage(StageRutorDetails, StageStats{Torrents: len(p.torrents)})
+	defer func() { done(StageStats{Torrents: len(p.torrents)}) }()
+	slog.InfoContext(p.context(), "rutor details enrichment started", "torrents", len(p.torrents))
+
+	ctx, cancel := context.WithCancel(p.context())
+	defer cancel()
+	p.addError(rutor.EnrichDetails(ctx, p.torrents, rutorDetailsConcurrency))
+	slog.InfoContext(p.context(), "rutor details enrichment completed", "torrents", len(p.torrents))
+
+	return p
+}
+
 func (p *TrackersPipeline) RunTrackersSearchPipilene(isMovie string) *TrackersPipeline {
 	return p.RunTrackersSearchPipeline(strings.EqualFold(strings.TrimSpace(isMovie), "true"))
 }
@@ -323,12 +849,16 @@ func (p *TrackersPipeline) RunRutorPipeline() *TrackersPipeline {
 		return p
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	done := p.startStage(StageTrackers, StageStats{})
+	defer func() { done(StageStats{Torrents: len(p.torrents)}) }()
+
+	ctx, cancel := context.WithCancel(p.context())
 	defer cancel()
 
 	rutorTracker := tracker.Init(tracker.Config{
+		Name:          "rutor",
 		Urls:          p.config.urls,
-		TrackerParser: rutor.ParseMoviePage,
+		TrackerParser: p.observeParser("rutor", rutor.ParseMoviePage),
 	})
 
 	torrentsResults, rutorErrors := rutorTracker.TorrentsPipelineStream(ctx)
@@ -347,20 +877,30 @@ func (p *TrackersPipeline) RunRutorPipiline() *TrackersPipeline {
 }
 
 func (p *TrackersPipeline) HandleErrors() error {
-	if len(p.errors) == 0 {
-		return nil
-	}
-
-	errorStrSlice := make([]string, 0, len(p.errors))
+	errs := make([]error, 0, len(p.errors))
 	for _, err := range p.errors {
 		if err != nil {
-			errorStrSlice = append(errorStrSlice, err.Error())
+			errs = append(errs, err)
 		}
 	}
-
-	if len(errorStrSlice) == 0 {
+	if len(errs) == 0 {
 		return nil
 	}
+	return pipelineErrors(errs)
+}
+
+// pipelineErrors joins stage errors with ",\n" and keeps them reachable for
+// errors.Is and errors.As.
+type pipelineErrors []error
+
+func (e pipelineErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, ",\n")
+}
 
-	return errors.New(strings.Join(errorStrSlice, ",\n"))
+func (e pipelineErrors) Unwrap() []error {
+	return e
 }
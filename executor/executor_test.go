@@ -1,12 +1,34 @@
 package executor
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/lieranderl/moviestracker-package/internal/download"
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/tmdbfake"
 	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/internal/tracker"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestHandleErrorsReturnsCombinedError(t *testing.T) {
@@ -45,19 +67,19 @@ func TestConvertTorrentsToMovieShortUsesStableGroupingKey(t *testing.T) {
 				Hash:         "hash-a",
 				OriginalName: "Movie A",
 				Year:         "2024",
-				Date:         "2024-06-12T00:00:00.000Z",
+				Date:         time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC),
 			},
 			{
 				Hash:         "hash-a",
 				OriginalName: "Movie A",
 				Year:         "2024",
-				Date:         "2024-07-12T00:00:00.000Z",
+				Date:         time.Date(2024, 7, 12, 0, 0, 0, 0, time.UTC),
 			},
 			{
 				MagnetHash:  "mh-b",
 				RussianName: "Фильм Б",
 				Year:        "2023",
-				Date:        "2023-06-12T00:00:00.000Z",
+				Date:        time.Date(2023, 6, 12, 0, 0, 0, 0, time.UTC),
 			},
 		},
 	}
@@ -67,10 +89,692 @@ func TestConvertTorrentsToMovieShortUsesStableGroupingKey(t *testing.T) {
 
 	first := pipeline.movies[0]
 	require.Equal(t, "Movie A", strings.TrimSpace(first.Searchname))
-	require.Equal(t, "2024", first.Year)
+	require.Equal(t, 2024, first.Year)
 	require.False(t, first.LastTimeFound.IsZero())
+	require.Len(t, first.Torrents, 2)
 
 	second := pipeline.movies[1]
 	require.Equal(t, "Фильм Б", strings.TrimSpace(second.Searchname))
-	require.Equal(t, "2023", second.Year)
+	require.Equal(t, 2023, second.Year)
+}
+
+func TestTmdbStagesAgainstFakeServer(t *testing.T) {
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	env := InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en")
+	pipeline := Init(*env)
+	pipeline.torrents = []*torrents.Torrent{
+		{MagnetHash: "mh-1", OriginalName: "Bad Boys: Ride or Die", RussianName: "Плохие парни до конца", Year: "2024", ImdbID: "tt4919268"},
+	}
+
+	pipeline = pipeline.ConvertTorrentsToMovieShort().Tmdb().Genres().Details()
+
+	require.NoError(t, pipeline.HandleErrors())
+	require.Len(t, pipeline.movies, 1)
+	require.Equal(t, "573435", pipeline.movies[0].ID)
+	require.Equal(t, []string{"Action", "Crime", "Thriller", "Comedy"}, pipeline.movies[0].Genres["en"])
+	require.Len(t, pipeline.details, 1)
+	require.Equal(t, "tt4919268", pipeline.details[0].ImdbID)
+	require.Len(t, pipeline.details[0].Cast, 2)
+}
+
+func TestTmdbMergesMoviesWithTheSameID(t *testing.T) {
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en"))
+	pipeline.torrents = []*torrents.Torrent{
+		{Hash: "rutor-spelling", MagnetHash: "mh-1", OriginalName: "Bad Boys: Ride or Die", Year: "2024", ImdbID: "tt4919268"},
+		{Hash: "kinozal-spelling", MagnetHash: "mh-2", OriginalName: "Bad Boys Ride or Die", Year: "2024", ImdbID: "tt4919268"},
+	}
+	pipeline = pipeline.ConvertTorrentsToMovieShort().Tmdb()
+
+	require.NoError(t, pipeline.HandleErrors())
+	require.Len(t, pipeline.movies, 1)
+	require.Equal(t, "573435", pipeline.movies[0].ID)
+	require.Len(t, pipeline.movies[0].Torrents, 2)
+}
+
+func TestSavedMoviesKeepTheirTorrents(t *testing.T) {
+	if os.Getenv("RUN_INTEGRATION_TESTS") != "1" {
+		t.Skip("integration test skipped: set RUN_INTEGRATION_TESTS=1 to enable")
+	}
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("integration test skipped: MONGO_URI is not configured")
+	}
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := ConnectMongo(ctx, uri)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, client.Disconnect(context.Background())) }()
+	collection := client.Database(mongoDBName).Collection("saved_movies_test")
+	require.NoError(t, collection.Drop(ctx))
+
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en").WithMongo(uri))
+	pipeline.torrents = []*torrents.Torrent{
+		{MagnetHash: "mh-1", Magnet: "magnet:?xt=urn:btih:mh-1", OriginalName: "Bad Boys: Ride or Die", Year: "2024", ImdbID: "tt4919268", Size: 1.5},
+		{MagnetHash: "mh-2", Magnet: "magnet:?xt=urn:btih:mh-2", OriginalName: "Bad Boys: Ride or Die", Year: "2024", ImdbID: "tt4919268", Size: 20},
+	}
+	require.NoError(t, pipeline.ConvertTorrentsToMovieShort().Tmdb().SaveToMongo("saved_movies_test").HandleErrors())
+
+	saved, found, err := movies.FindMovie(ctx, collection, "573435")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Len(t, saved.Torrents, 2)
+	require.Equal(t, "mh-1", saved.Torrents[0].MagnetHash)
+	require.Equal(t, "magnet:?xt=urn:btih:mh-2", saved.Torrents[1].Magnet)
+
+	pipeline = Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en").WithMongo(uri))
+	pipeline.torrents = []*torrents.Torrent{
+		{MagnetHash: "mh-3", OriginalName: "Bad Boys: Ride or Die", Year: "2024", ImdbID: "tt4919268"},
+	}
+	require.NoError(t, pipeline.ConvertTorrentsToMovieShort().Tmdb().SaveToMongo("saved_movies_test").HandleErrors())
+	saved, _, err = movies.FindMovie(ctx, collection, "573435")
+	require.NoError(t, err)
+	require.Len(t, saved.Torrents, 3, "a later search adds to the saved torrents")
+}
+
+func TestPipelinesShareMetadataClients(t *testing.T) {
+	env := InitVars(nil, "key").WithKinopoisk("kp", "")
+	first, second := Init(*env), Init(*env.WithURLs("u1"))
+
+	require.Same(t, first.tmdbClient(), second.tmdbClient(), "one TMDB rate limit for every search")
+	require.Same(t, first.metadataClients().kinopoisk, second.metadataClients().kinopoisk)
+	require.NotSame(t, first.tmdbClient(), Init(*InitVars(nil, "key")).tmdbClient())
+}
+
+func TestRatingsSkippedWithoutOMDbKey(t *testing.T) {
+	pipeline := Init(*InitVars(nil, "key"))
+	pipeline.movies = []*movies.Short{{ID: "693134", ImdbID: "tt15239678"}}
+
+	pipeline = pipeline.Ratings()
+
+	require.NoError(t, pipeline.HandleErrors())
+	require.Empty(t, pipeline.movies[0].Ratings)
+}
+
+func TestRatingsLooksUpMissingIMDbIDs(t *testing.T) {
+	tmdbSrv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer tmdbSrv.Close()
+	omdbSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("i") != "tt15239678" {
+			fmt.Fprint(w, `{"Response":"False","Error":"Incorrect IMDb ID."}`)
+			return
+		}
+		fmt.Fprint(w, `{"Response":"True","imdbVotes":"612,345","Ratings":[{"Source":"Internet Movie Database","Value":"8.5/10"},{"Source":"Rotten Tomatoes","Value":"92%"}]}`)
+	}))
+	defer omdbSrv.Close()
+
+	env := InitVars(nil, "key").WithTMDBBaseURL(tmdbSrv.URL).WithOMDb("omdb-key", omdbSrv.URL)
+	pipeline := Init(*env)
+	pipeline.movies = []*movies.Short{{ID: "693134", MatchStrategy: movies.MatchBySearch}}
+
+	pipeline = pipeline.Ratings()
+
+	require.NoError(t, pipeline.HandleErrors())
+	require.Equal(t, "tt15239678", pipeline.movies[0].ImdbID)
+	require.Equal(t, movies.Rating{Value: 8.5, Votes: 612345}, pipeline.movies[0].Ratings[movies.RatingIMDb])
+	require.Equal(t, 92.0, pipeline.movies[0].Ratings[movies.RatingRottenTomatoes].Value)
+}
+
+func TestRatingsFailWhenTheRunIsCancelled(t *testing.T) {
+	omdbSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer omdbSrv.Close()
+	quota := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"Response":"False","Error":"Request limit reached!"}`)
+	}))
+	defer quota.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var rec progressRecorder
+	pipeline := Init(*InitVars(nil, "key").WithOMDb("omdb-key", omdbSrv.URL)).WithContext(ctx).WithProgress(rec.record)
+	pipeline.movies = []*movies.Short{{ID: "693134", ImdbID: "tt15239678"}}
+
+	require.ErrorIs(t, pipeline.Ratings().HandleErrors(), context.DeadlineExceeded)
+	completed, ok := rec.find(ProgressStageCompleted, StageRatings, "")
+	require.True(t, ok)
+	require.True(t, completed.Failed)
+
+	pipeline = Init(*InitVars(nil, "key").WithOMDb("omdb-key", quota.URL))
+	pipeline.movies = []*movies.Short{{ID: "693134", ImdbID: "tt15239678"}}
+	require.NoError(t, pipeline.Ratings().HandleErrors(), "an exhausted quota only costs the ratings")
+}
+
+type recordingNotifier struct {
+	events []watch.Event
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, events []watch.Event) error {
+	n.events = append(n.events, events...)
+	return nil
+}
+
+func TestWatchNotifiesMatchingEvents(t *testing.T) {
+	store := watch.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	query := watch.Query{Name: "bad boys", Notify: &watch.Rule{Resolution: []string{"2160p"}, HDR: []string{"dv"}}}
+	notifier := &recordingNotifier{}
+
+	run := func(found ...*torrents.Torrent) *TrackersPipeline {
+		pipeline := Init(*InitVars(nil, "").WithNotifier(notifier))
+		pipeline.torrents = found
+		return pipeline.Watch(query, store, 10)
+	}
+
+	fhd := &torrents.Torrent{Name: "Bad Boys 1080p", Hash: "movie", MagnetHash: "aaa", FHD: true}
+	baseline := run(fhd)
+	require.NoError(t, baseline.HandleErrors())
+	require.Empty(t, baseline.Events())
+
+	uhdHDR := &torrents.Torrent{Name: "Bad Boys 2160p HDR", Hash: "movie", MagnetHash: "bbb", K4: true, HDR10: true}
+	uhdDV := &torrents.Torrent{Name: "Bad Boys 2160p DV", Hash: "movie", MagnetHash: "ccc", K4: true, DV: true}
+	pipeline := run(fhd, uhdHDR, uhdDV)
+	require.NoError(t, pipeline.HandleErrors())
+	require.Len(t, pipeline.Events(), 2)
+	require.Len(t, notifier.events, 1)
+	require.Equal(t, "ccc", notifier.events[0].Torrent.MagnetHash)
+}
+
+type fakeDownloadClient struct {
+	known map[string]bool
+	added map[string]download.AddOptions
+}
+
+func (c *fakeDownloadClient) Name() string { return "fake" }
+
+func (c *fakeDownloadClient) Add(_ context.Context, magnet string, opts download.AddOptions) error {
+	c.added[magnet] = opts
+	return nil
+}
+
+func (c *fakeDownloadClient) Status(_ context.Context, infoHash string) (*download.Status, bool, error) {
+	if c.known[infoHash] {
+		return &download.Status{Hash: infoHash}, true, nil
+	}
+	return nil, false, nil
+}
+
+func TestWatchFetchesPostersForNotifiedReleases(t *testing.T) {
+	var visits sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		visits.Store(r.URL.Path, true)
+		if r.URL.Path == "/torrent/2/bad-boys" {
+			fmt.Fprint(w, `<table id="details"><tr><td></td><td><img src="https://i.example/poster.jpg"></td></tr></table>`)
+		}
+	}))
+	defer srv.Close()
+
+	store := watch.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	query := watch.Query{Name: "bad boys"}
+	notifier := &recordingNotifier{}
+	run := func(found ...*torrents.Torrent) *TrackersPipeline {
+		pipeline := Init(*InitVars(nil, "").WithNotifier(notifier))
+		pipeline.torrents = found
+		return pipeline.Watch(query, store, 10)
+	}
+
+	old := &torrents.Torrent{Name: "Bad Boys 1080p", Hash: "movie", MagnetHash: "aaa", FHD: true, DetailsUrl: srv.URL + "/torrent/1/bad-boys"}
+	require.NoError(t, run(old).HandleErrors())
+	uhd := &torrents.Torrent{Name: "Bad Boys 2160p", Hash: "movie", MagnetHash: "bbb", K4: true, DetailsUrl: srv.URL + "/torrent/2/bad-boys"}
+	require.NoError(t, run(old, uhd).HandleErrors())
+
+	require.Len(t, notifier.events, 1)
+	require.Equal(t, "https://i.example/poster.jpg", notifier.events[0].Torrent.Poster)
+	_, visited := visits.Load("/torrent/1/bad-boys")
+	require.False(t, visited, "only the releases that are sent need details")
+}
+
+func TestSendToClientAddsMatchingReleases(t *testing.T) {
+	client := &fakeDownloadClient{known: map[string]bool{"known": true}, added: map[string]download.AddOptions{}}
+	defaults := download.AddOptions{Category: "movies", SavePath: "/data", Tags: []string{"watch"}}
+	pipeline := Init(*InitVars(nil, "").WithDownloadClient(client, defaults))
+
+	uhd := &torrents.Torrent{Name: "4K", MagnetHash: "uhd", K4: true, Magnet: "magnet:?xt=urn:btih:uhd&dn=4K"}
+	known := &torrents.Torrent{Name: "4K known", MagnetHash: "known", K4: true}
+	fhd := &torrents.Torrent{Name: "1080p", MagnetHash: "fhd", FHD: true}
+	seeds := &torrents.Torrent{Name: "4K seeds", MagnetHash: "seeds", K4: true}
+	pipeline.events = []watch.Event{
+		{Type: watch.EventNewRelease, Torrent: uhd},
+		{Type: watch.EventBetterQuality, Torrent: known},
+		{Type: watch.EventNewRelease, Torrent: fhd},
+		{Type: watch.EventSeedsChanged, Torrent: seeds},
+	}
+	query := watch.Query{Name: "q", Download: &watch.DownloadRule{Rule: watch.Rule{Resolution: []string{"2160p"}}, Category: "4k"}}
+
+	pipeline = pipeline.SendToClient(query)
+
+	require.NoError(t, pipeline.HandleErrors())
+	require.Equal(t, []*torrents.Torrent{uhd}, pipeline.Sent())
+	require.Equal(t, map[string]download.AddOptions{
+		"magnet:?xt=urn:btih:uhd&dn=4K": {Category: "4k", SavePath: "/data", Tags: []string{"watch"}},
+	}, client.added)
+}
+
+func TestSendToClientDownloadsMatchesOfTheFirstRun(t *testing.T) {
+	store := watch.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	query := watch.Query{Name: "q", Download: &watch.DownloadRule{Rule: watch.Rule{Resolution: []string{"2160p"}}}}
+	uhd := &torrents.Torrent{Name: "4K", Hash: "movie", MagnetHash: "uhd", K4: true}
+	fhd := &torrents.Torrent{Name: "1080p", Hash: "movie", MagnetHash: "fhd", FHD: true}
+	run := func(found ...*torrents.Torrent) *TrackersPipeline {
+		client := &fakeDownloadClient{added: map[string]download.AddOptions{}}
+		pipeline := Init(*InitVars(nil, "").WithDownloadClient(client, download.AddOptions{}))
+		pipeline.torrents = found
+		return pipeline.Watch(query, store, 10).SendToClient(query)
+	}
+
+	first := run(uhd, fhd)
+	require.NoError(t, first.HandleErrors())
+	require.Empty(t, first.Events(), "the first run only records a baseline")
+	require.Equal(t, []*torrents.Torrent{uhd}, first.Sent())
+
+	second := run(uhd, fhd)
+	require.NoError(t, second.HandleErrors())
+	require.Empty(t, second.Sent())
+}
+
+func TestWatchStagesReportProgress(t *testing.T) {
+	store := watch.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	query := watch.Query{Name: "q", Download: &watch.DownloadRule{Rule: watch.Rule{Resolution: []string{"2160p"}}}}
+	uhd := &torrents.Torrent{Name: "4K", Hash: "movie", MagnetHash: "uhd", K4: true}
+	fhd := &torrents.Torrent{Name: "1080p", Hash: "movie", MagnetHash: "fhd", FHD: true}
+
+	var rec progressRecorder
+	client := &fakeDownloadClient{added: map[string]download.AddOptions{}}
+	pipeline := Init(*InitVars(nil, "").WithDownloadClient(client, download.AddOptions{})).WithProgress(rec.record)
+	pipeline.torrents = []*torrents.Torrent{uhd, fhd}
+	require.NoError(t, pipeline.Watch(query, store, 10).SendToClient(query).HandleErrors())
+
+	started, ok := rec.find(ProgressStageStarted, StageWatch, "")
+	require.True(t, ok)
+	require.Equal(t, 2, started.Torrents)
+	watched, _ := rec.find(ProgressStageCompleted, StageWatch, "")
+	require.Zero(t, watched.Torrents, "the first run only records a baseline")
+	started, _ = rec.find(ProgressStageStarted, StageSendToClient, "")
+	require.Equal(t, 1, started.Torrents)
+	sent, ok := rec.find(ProgressStageCompleted, StageSendToClient, "")
+	require.True(t, ok)
+	require.Equal(t, 1, sent.Torrents)
+	require.False(t, sent.Failed)
+}
+
+func TestSendToClientNeedsDownloadRule(t *testing.T) {
+	client := &fakeDownloadClient{added: map[string]download.AddOptions{}}
+	pipeline := Init(*InitVars(nil, "").WithDownloadClient(client, download.AddOptions{}))
+	pipeline.events = []watch.Event{{Type: watch.EventNewRelease, Torrent: &torrents.Torrent{MagnetHash: "abc"}}}
+
+	pipeline = pipeline.SendToClient(watch.Query{Name: "q"})
+
+	require.Empty(t, pipeline.Sent())
+	require.Empty(t, client.added)
+}
+
+func TestRunTrackersSearchPipelineStopsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pipeline := Init(*InitVars([]string{srv.URL + "/rutor", srv.URL + "/kinozal"}, "")).
+		WithContext(ctx).
+		RunTrackersSearchPipeline(true)
+
+	require.ErrorIs(t, pipeline.HandleErrors(), context.Canceled)
+}
+
+func fakeSource(name string, parse func(context.Context, string) ([]*torrents.Torrent, error)) trackerSource {
+	return trackerSource{name: name, tracker: tracker.Init(tracker.Config{Name: name, Urls: []string{name}, TrackerParser: parse})}
+}
+
+func TestSearchStreamYieldsEachTrackerAsItResponds(t *testing.T) {
+	kinozalDone := make(chan struct{})
+	sources := []trackerSource{
+		fakeSource("rutor", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return []*torrents.Torrent{{Name: "Dune 2160p", MagnetHash: "AAA"}, {Name: "Dune 1080p", MagnetHash: "bbb"}}, nil
+		}),
+		fakeSource("kinozal", func(context.Context, string) ([]*torrents.Torrent, error) {
+			<-kinozalDone
+			return []*torrents.Torrent{{Name: "Dune 2160p (kinozal)", MagnetHash: "aaa"}, {Name: "Dune DV", MagnetHash: "ccc"}}, nil
+		}),
+	}
+	pipeline := Init(*InitVars(nil, ""))
+
+	var batches []SearchBatch
+	for batch, err := range pipeline.streamSources(sources) {
+		require.NoError(t, err)
+		batches = append(batches, batch)
+		if batch.Tracker == "rutor" {
+			close(kinozalDone)
+		}
+	}
+
+	require.Len(t, batches, 2)
+	require.Equal(t, "rutor", batches[0].Tracker)
+	require.Len(t, batches[0].Torrents, 2)
+	require.Equal(t, "kinozal", batches[1].Tracker)
+	require.Len(t, batches[1].Torrents, 1, "magnet hashes rutor already sent are left out")
+	require.Equal(t, "Dune DV", batches[1].Torrents[0].Name)
+	require.NoError(t, pipeline.HandleErrors())
+	require.Len(t, pipeline.GetTorrents(), 3)
+}
+
+func TestSearchStreamStopsWhenCallerBreaks(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	sources := []trackerSource{
+		fakeSource("rutor", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return []*torrents.Torrent{{Name: "Dune", MagnetHash: "aaa"}}, nil
+		}),
+		fakeSource("kinozal", func(context.Context, string) ([]*torrents.Torrent, error) {
+			<-release
+			return nil, nil
+		}),
+	}
+	pipeline := Init(*InitVars(nil, ""))
+
+	for batch, err := range pipeline.streamSources(sources) {
+		require.NoError(t, err)
+		require.Equal(t, "rutor", batch.Tracker)
+		break
+	}
+	require.NoError(t, pipeline.HandleErrors())
+	require.Empty(t, pipeline.GetTorrents(), "an abandoned stream is not a complete result")
+}
+
+func TestSearchStreamYieldsTrackerErrors(t *testing.T) {
+	sources := []trackerSource{
+		fakeSource("kinozal", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return nil, errors.New("blocked")
+		}),
+	}
+	pipeline := Init(*InitVars(nil, ""))
+
+	var errs []error
+	for _, err := range pipeline.streamSources(sources) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "kinozal: blocked")
+	require.Error(t, pipeline.HandleErrors())
+
+	var validation error
+	for _, err := range Init(*InitVars([]string{"only-rutor"}, "")).SearchStream(true) {
+		validation = err
+	}
+	require.EqualError(t, validation, "at least two tracker urls are required: rutor and kinozal")
+}
+
+func TestSearchMoviesStreamEnrichesBatches(t *testing.T) {
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	sources := []trackerSource{
+		fakeSource("rutor", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return []*torrents.Torrent{
+				{MagnetHash: "mh-1", OriginalName: "Bad Boys: Ride or Die", RussianName: "Плохие парни до конца", Year: "2024", ImdbID: "tt4919268"},
+			}, nil
+		}),
+	}
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en"))
+
+	var batches []SearchBatch
+	for batch, err := range pipeline.enrichBatches(pipeline.streamSources(sources)) {
+		require.NoError(t, err)
+		batches = append(batches, batch)
+	}
+
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0].Movies, 1)
+	require.Equal(t, "573435", batches[0].Movies[0].ID)
+	require.Equal(t, []string{"Action", "Crime", "Thriller", "Comedy"}, batches[0].Movies[0].Genres["en"])
+	require.Len(t, pipeline.GetTorrents(), 1)
+}
+
+type progressRecorder struct {
+	mu     sync.Mutex
+	events []Progress
+}
+
+func (r *progressRecorder) record(e Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// find returns the last event of the type, stage and tracker.
+func (r *progressRecorder) find(typ ProgressType, stage, tracker string) (Progress, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if e := r.events[i]; e.Type == typ && e.Stage == stage && e.Tracker == tracker {
+			return e, true
+		}
+	}
+	return Progress{}, false
+}
+
+func TestProgressReportsTrackersAndStages(t *testing.T) {
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	var rec progressRecorder
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en")).WithProgress(rec.record)
+	sources := []trackerSource{
+		fakeSource("rutor", pipeline.observeParser("rutor", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return []*torrents.Torrent{
+				{Hash: "bad-boys", MagnetHash: "mh-1", Magnet: "magnet:?xt=urn:btih:mh-1", OriginalName: "Bad Boys: Ride or Die", Year: "2024", ImdbID: "tt4919268"},
+				{Hash: "bad-boys", MagnetHash: "mh-2", OriginalName: "Bad Boys: Ride or Die", Year: "2024"},
+			}, nil
+		})),
+	}
+	for _, err := range pipeline.streamSources(sources) {
+		require.NoError(t, err)
+	}
+	pipeline.ConvertTorrentsToMovieShort().Tmdb()
+	require.NoError(t, pipeline.HandleErrors())
+
+	require.Equal(t, Progress{Type: ProgressStageStarted, Stage: StageTrackers, At: rec.events[0].At}, rec.events[0])
+	_, ok := rec.find(ProgressTrackerStarted, StageTrackers, "rutor")
+	require.True(t, ok)
+	page, _ := rec.find(ProgressTrackerResult, StageTrackers, "rutor")
+	require.Equal(t, 2, page.Torrents)
+	require.Equal(t, 1, page.Magnets)
+	trackers, _ := rec.find(ProgressStageCompleted, StageTrackers, "")
+	require.Equal(t, 2, trackers.Torrents)
+	require.False(t, trackers.Failed)
+	started, _ := rec.find(ProgressStageStarted, StageTmdb, "")
+	require.Equal(t, 1, started.Movies)
+	matched, _ := rec.find(ProgressStageCompleted, StageTmdb, "")
+	require.Equal(t, 1, matched.Movies)
+	require.False(t, matched.Failed)
+	enriched, ok := rec.find(ProgressMovieEnriched, StageTmdb, "")
+	require.True(t, ok)
+	require.NotEmpty(t, enriched.Movie)
+
+	failing := Init(*InitVars(nil, "")).WithProgress(rec.record)
+	sources = []trackerSource{
+		fakeSource("kinozal", failing.observeParser("kinozal", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return nil, errors.New("blocked")
+		})),
+	}
+	for range failing.streamSources(sources) {
+	}
+	page, _ = rec.find(ProgressTrackerResult, StageTrackers, "kinozal")
+	require.True(t, page.Failed)
+	trackers, _ = rec.find(ProgressStageCompleted, StageTrackers, "")
+	require.True(t, trackers.Failed)
+}
+
+type recordingObserver struct {
+	NopObserver
+	mu       sync.Mutex
+	calls    []string
+	enriched []string
+	errs     []error
+}
+
+func (o *recordingObserver) record(call string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls = append(o.calls, call)
+}
+
+func (o *recordingObserver) OnStageStart(_ context.Context, stage string, in StageStats) {
+	o.record(fmt.Sprintf("start %s movies=%d", stage, in.Movies))
+}
+
+func (o *recordingObserver) OnStageEnd(_ context.Context, stage string, out StageStats, elapsed time.Duration, err error) {
+	o.record(fmt.Sprintf("end %s movies=%d failed=%t", stage, out.Movies, err != nil))
+}
+
+func (o *recordingObserver) OnMovieEnriched(_ context.Context, stage string, movie *movies.Short) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.enriched = append(o.enriched, stage+" "+movie.ID)
+}
+
+func (o *recordingObserver) OnError(_ context.Context, stage string, err error) {
+	o.record("error " + stage)
+	o.errs = append(o.errs, err)
+}
+
+func TestObserverFollowsStages(t *testing.T) {
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	var obs recordingObserver
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en")).WithObserver(&obs)
+	pipeline.torrents = []*torrents.Torrent{
+		{MagnetHash: "mh-1", OriginalName: "Bad Boys: Ride or Die", RussianName: "Плохие парни до конца", Year: "2024", ImdbID: "tt4919268"},
+	}
+	pipeline.ConvertTorrentsToMovieShort().Tmdb().Genres()
+	require.NoError(t, pipeline.HandleErrors())
+
+	require.Equal(t, []string{
+		"start movies movies=0",
+		"end movies movies=1 failed=false",
+		"start tmdb movies=1",
+		"end tmdb movies=1 failed=false",
+		"start genres movies=1",
+		"end genres movies=1 failed=false",
+	}, obs.calls)
+	require.Equal(t, []string{"tmdb 573435"}, obs.enriched)
+
+	obs.calls = nil
+	pipeline.RunTrackersSearchPipeline(true)
+	require.Equal(t, []string{"start trackers movies=0", "error trackers", "end trackers movies=0 failed=true"}, obs.calls)
+	require.ErrorIs(t, pipeline.HandleErrors(), obs.errs[0])
+}
+
+// spanRecorder installs the global tracer provider once: the package tracers
+// only pick up the first one.
+var spanRecorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+})
+
+func TestStagesAreTraced(t *testing.T) {
+	recorder := spanRecorder()
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "search")
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en")).WithContext(ctx)
+	sources := []trackerSource{
+		fakeSource("rutor", func(context.Context, string) ([]*torrents.Torrent, error) {
+			return []*torrents.Torrent{{Hash: "bad-boys", MagnetHash: "mh-1", OriginalName: "Bad Boys: Ride or Die", Year: "2024"}}, nil
+		}),
+	}
+	for _, err := range pipeline.streamSources(sources) {
+		require.NoError(t, err)
+	}
+	pipeline.ConvertTorrentsToMovieShort().Tmdb()
+	require.NoError(t, pipeline.HandleErrors())
+	require.Equal(t, ctx, pipeline.context(), "stages restore the pipeline context")
+	root.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	byID := make(map[trace.SpanID]sdktrace.ReadOnlySpan)
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			continue
+		}
+		spans[s.Name()] = s
+		byID[s.SpanContext().SpanID()] = s
+	}
+	ancestors := func(name string) []string {
+		require.Contains(t, spans, name)
+		var names []string
+		for s := byID[spans[name].Parent().SpanID()]; s != nil; s = byID[s.Parent().SpanID()] {
+			names = append(names, s.Name())
+		}
+		return names
+	}
+	require.Equal(t, []string{"pipeline.step", "executor.trackers", "search"}, ancestors("tracker.visit"))
+	require.Equal(t, []string{"pipeline.step", "executor.tmdb", "search"}, ancestors("tmdb.match"))
+	require.Equal(t, []string{"tmdb.match", "pipeline.step", "executor.tmdb", "search"}, ancestors("tmdb.request"))
+	require.Equal(t, trace.SpanKindClient, spans["tmdb.request"].SpanKind())
+	for _, attr := range spans["tmdb.request"].Attributes() {
+		require.NotContains(t, attr.Value.Emit(), "key", "the api key stays out of traces")
+	}
+	require.Contains(t, spans["tracker.visit"].Attributes(), attribute.String("tracker.name", "rutor"))
+	require.Contains(t, spans["executor.tmdb"].Attributes(), attribute.Int("stage.out.movies", 1))
+}
+
+func TestStageLogsCarryRunAttributes(t *testing.T) {
+	var out syncBuffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(logging.Handler(slog.NewJSONHandler(&out, nil))))
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	ctx := logging.With(context.Background(), "run_id", "r1", "query", "Bad Boys")
+	pipeline := Init(*InitVars(nil, "key").WithTMDBBaseURL(srv.URL).WithLanguages("ru", "en")).WithContext(ctx)
+	pipeline.torrents = []*torrents.Torrent{
+		{Hash: "bad-boys", MagnetHash: "mh-1", OriginalName: "Bad Boys: Ride or Die", Year: "2024"},
+	}
+	pipeline.ConvertTorrentsToMovieShort().Tmdb()
+	require.NoError(t, pipeline.HandleErrors())
+
+	lines := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		lines[record["msg"].(string)] = record
+	}
+	for _, msg := range []string{"tmdb enrichment started", "tmdb enrichment completed"} {
+		require.Contains(t, lines, msg)
+		require.Equal(t, "r1", lines[msg]["run_id"])
+		require.Equal(t, "Bad Boys", lines[msg]["query"])
+		require.Equal(t, "tmdb", lines[msg]["stage"])
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of pipeline
+// workers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
 }
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StageStats is what a stage starts or ends with; counts that do not apply to
+// the stage are zero.
+type StageStats struct {
+	Torrents int
+	Movies   int
+	Skipped  int
+}
+
+// Observer follows a running pipeline, e.g. for metrics, progress bars or
+// notifications; register it with WithObserver. Methods may be called from
+// several goroutines at once and must return quickly. Embed NopObserver to
+// implement only some of them.
+type Observer interface {
+	// OnStageStart is called when stage (one of the Stage constants) begins.
+	OnStageStart(ctx context.Context, stage string, in StageStats)
+	// OnStageEnd is called when stage returns; err joins the errors it
+	// recorded.
+	OnStageEnd(ctx context.Context, stage string, out StageStats, elapsed time.Duration, err error)
+	// OnTrackerStart is called before a tracker search page is requested.
+	OnTrackerStart(ctx context.Context, tracker string)
+	// OnTrackerResult is called with the torrents of every parsed tracker
+	// page, before deduplication, or the error that failed it.
+	OnTrackerResult(ctx context.Context, tracker string, found []*torrents.Torrent, elapsed time.Duration, err error)
+	// OnMovieEnriched is called for every movie the tmdb stage matched or the
+	// ratings stage rated.
+	OnMovieEnriched(ctx context.Context, stage string, movie *movies.Short)
+	// OnMongoWrite is called when the save stage has written documents to
+	// collection; elapsed covers the writes only, not connecting.
+	OnMongoWrite(ctx context.Context, collection string, documents int, elapsed time.Duration, err error)
+	// OnError is called for every error that fails the pipeline; stage is
+	// empty for errors outside a stage.
+	OnError(ctx context.Context, stage string, err error)
+}
+
+// NopObserver ignores every call.
+type NopObserver struct{}
+
+func (NopObserver) OnStageStart(context.Context, string, StageStats) {}
+
+func (NopObserver) OnStageEnd(context.Context, string, StageStats, time.Duration, error) {}
+
+func (NopObserver) OnTrackerStart(context.Context, string) {}
+
+func (NopObserver) OnTrackerResult(context.Context, string, []*torrents.Torrent, time.Duration, error) {
+}
+
+func (NopObserver) OnMovieEnriched(context.Context, string, *movies.Short) {}
+
+func (NopObserver) OnMongoWrite(context.Context, string, int, time.Duration, error) {}
+
+func (NopObserver) OnError(context.Context, string, error) {}
+
+// WithObserver adds o to the observers of the following stages.
+func (p *TrackersPipeline) WithObserver(o Observer) *TrackersPipeline {
+	p.observers = append(p.observers, o)
+	return p
+}
+
+func (p *TrackersPipeline) notify(fn func(ctx context.Context, o Observer)) {
+	p.notifyContext(p.context(), fn)
+}
+
+// notifyContext is notify for worker goroutines, which must not read the
+// pipeline context: startStage swaps it.
+func (p *TrackersPipeline) notifyContext(ctx context.Context, fn func(ctx context.Context, o Observer)) {
+	for _, o := range p.observers {
+		fn(ctx, o)
+	}
+}
+
+// startStage reports the start of stage and returns the func that reports its
+// end; the stage failed when it recorded errors in between. Until then the
+// pipeline context carries the stage's span and "stage" log attribute.
+func (p *TrackersPipeline) startStage(stage string, in StageStats) func(out StageStats) {
+	errs, started, outer, outerCtx := len(p.errors), time.Now(), p.stage, p.ctx
+	ctx, span := tracer.Start(p.context(), "executor."+stage, trace.WithAttributes(statsAttributes("stage.in", in)...))
+	p.stage, p.ctx = stage, logging.With(ctx, "stage", stage)
+	p.notify(func(ctx context.Context, o Observer) { o.OnStageStart(ctx, stage, in) })
+	return func(out StageStats) {
+		p.stage, p.ctx = outer, outerCtx
+		err := errors.Join(p.errors[errs:]...)
+		elapsed := time.Since(started)
+		span.SetAttributes(statsAttributes("stage.out", out)...)
+		endSpan(span, err)
+		p.notify(func(ctx context.Context, o Observer) { o.OnStageEnd(ctx, stage, out, elapsed, err) })
+	}
+}
+
+// observeParser reports every page parse of the tracker name.
+func (p *TrackersPipeline) observeParser(name string, parse func(context.Context, string) ([]*torrents.Torrent, error)) func(context.Context, string) ([]*torrents.Torrent, error) {
+	if len(p.observers) == 0 {
+		return parse
+	}
+	return func(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+		p.notifyContext(ctx, func(ctx context.Context, o Observer) { o.OnTrackerStart(ctx, name) })
+		started := time.Now()
+		found, err := parse(ctx, url)
+		elapsed := time.Since(started)
+		p.notifyContext(ctx, func(ctx context.Context, o Observer) { o.OnTrackerResult(ctx, name, found, elapsed, err) })
+		return found, err
+	}
+}
+
+// observedProvider reports every movie its Provider matched.
+type observedProvider struct {
+	movies.Provider
+	matched func(context.Context, *movies.Short)
+}
+
+func (o observedProvider) Match(ctx context.Context, m *movies.Short) (bool, error) {
+	found, err := o.Provider.Match(ctx, m)
+	if err == nil && found {
+		o.matched(ctx, m)
+	}
+	return found, err
+}
+
+// movieEnriched reports a movie to the observers with the context of the
+// worker that enriched it.
+func (p *TrackersPipeline) movieEnriched(stage string) func(context.Context, *movies.Short) {
+	return func(ctx context.Context, m *movies.Short) {
+		p.notifyContext(ctx, func(ctx context.Context, o Observer) { o.OnMovieEnriched(ctx, stage, m) })
+	}
+}
@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+// ProgressType names a step of a running pipeline.
+type ProgressType string
+
+const (
+	ProgressStageStarted   ProgressType = "stage_started"
+	ProgressStageCompleted ProgressType = "stage_completed"
+	// ProgressTrackerStarted is a tracker search page being requested.
+	ProgressTrackerStarted ProgressType = "tracker_started"
+	// ProgressTrackerResult is a parsed tracker page; kinozal magnet links
+	// have been fetched by then.
+	ProgressTrackerResult ProgressType = "tracker_result"
+	// ProgressMovieEnriched is a movie the tmdb stage matched or the ratings
+	// stage rated.
+	ProgressMovieEnriched ProgressType = "movie_enriched"
+)
+
+// Stages reported in Progress.Stage.
+const (
+	StageTrackers     = "trackers"
+	StageRutorDetails = "rutor_details"
+	StageMovies       = "movies"
+	StageTmdb         = "tmdb"
+	StageDetails      = "details"
+	StageGenres       = "genres"
+	StageRatings      = "ratings"
+	StageSave         = "save"
+	StageMigrate      = "migrate"
+	StageWatch        = "watch"
+	StageSendToClient = "send_to_client"
+)
+
+// Progress is one step of a running pipeline, see WithProgress.
+type Progress struct {
+	Type    ProgressType `json:"type"`
+	Stage   string       `json:"stage"`
+	Tracker string       `json:"tracker,omitempty"`
+	// Movie is the title of a movie_enriched movie.
+	Movie string `json:"movie,omitempty"`
+	// Torrents counts the torrents of a tracker page or, when the trackers
+	// stage completes, the deduplicated result. The watch stage completes
+	// with its events, send_to_client with the torrents it added.
+	Torrents int `json:"torrents,omitempty"`
+	// Magnets counts the torrents of a tracker page that have a magnet link.
+	Magnets int `json:"magnets,omitempty"`
+	// Movies counts the movies a stage starts with and, on completion, the
+	// ones it kept (for tmdb: matched).
+	Movies  int `json:"movies,omitempty"`
+	Skipped int `json:"skipped,omitempty"`
+	// Failed marks a tracker page or stage that ended with an error; the
+	// error itself is returned by HandleErrors.
+	Failed bool      `json:"failed,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// WithProgress calls fn for every step of the following stages. fn may be
+// called from several goroutines at once and should return quickly.
+func (p *TrackersPipeline) WithProgress(fn func(Progress)) *TrackersPipeline {
+	return p.WithObserver(progressObserver(fn))
+}
+
+// progressObserver turns observer calls into Progress events.
+type progressObserver func(Progress)
+
+func (fn progressObserver) emit(e Progress) {
+	e.At = time.Now()
+	fn(e)
+}
+
+func (fn progressObserver) OnStageStart(_ context.Context, stage string, in StageStats) {
+	fn.emit(Progress{Type: ProgressStageStarted, Stage: stage, Torrents: in.Torrents, Movies: in.Movies})
+}
+
+func (fn progressObserver) OnStageEnd(_ context.Context, stage string, out StageStats, _ time.Duration, err error) {
+	fn.emit(Progress{Type: ProgressStageCompleted, Stage: stage, Torrents: out.Torrents, Movies: out.Movies, Skipped: out.Skipped, Failed: err != nil})
+}
+
+func (fn progressObserver) OnTrackerStart(_ context.Context, tracker string) {
+	fn.emit(Progress{Type: ProgressTrackerStarted, Stage: StageTrackers, Tracker: tracker})
+}
+
+func (fn progressObserver) OnTrackerResult(_ context.Context, tracker string, found []*torrents.Torrent, _ time.Duration, err error) {
+	e := Progress{Type: ProgressTrackerResult, Stage: StageTrackers, Tracker: tracker, Torrents: len(found), Failed: err != nil}
+	for _, t := range found {
+		if t != nil && t.Magnet != "" {
+			e.Magnets++
+		}
+	}
+	fn.emit(e)
+}
+
+func (fn progressObserver) OnMovieEnriched(_ context.Context, stage string, movie *movies.Short) {
+	fn.emit(Progress{Type: ProgressMovieEnriched, Stage: stage, Movie: firstNonEmpty(movie.Title, movie.Searchname, movie.Hash)})
+}
+
+func (progressObserver) OnMongoWrite(context.Context, string, int, time.Duration, error) {}
+
+func (progressObserver) OnError(context.Context, string, error) {}
@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/lieranderl/moviestracker-package/internal/kinozal"
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/rutor"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/internal/tracker"
+)
+
+// SearchBatch is one tracker page of a streaming search.
+type SearchBatch struct {
+	// Tracker is "rutor" or "kinozal".
+	Tracker string
+	// Torrents are the torrents of the page whose magnet hash no earlier
+	// batch had.
+	Torrents []*torrents.Torrent
+	// Movies are Torrents grouped into enriched movies; only
+	// SearchMoviesStream sets them. A movie both trackers found comes once per
+	// batch with that batch's torrents, so merge them by Hash.
+	Movies []*movies.Short
+}
+
+// trackerSource is the search of one tracker.
+type trackerSource struct {
+	name    string
+	tracker *tracker.Tracker
+}
+
+// trackerSources builds the rutor and kinozal searches from the first two
+// urls.
+func (p *TrackersPipeline) trackerSources(isMovie bool) ([]trackerSource, error) {
+	if len(p.config.urls) < 2 {
+		return nil, errors.New("at least two tracker urls are required: rutor and kinozal")
+	}
+
+	var rutorParser func(context.Context, string) ([]*torrents.Torrent, error)
+	var kinozalParser func(context.Context, string) ([]*torrents.Torrent, error)
+
+	if isMovie {
+		rutorParser = rutor.ParseMoviePage
+		kinozalParser = kinozal.ParseMoviePage
+	} else {
+		rutorParser = rutor.ParseSeriesPage
+		kinozalParser = kinozal.ParseSeriesPage
+	}
+
+	return []trackerSource{
+		{name: "rutor", tracker: tracker.Init(tracker.Config{Name: "rutor", Urls: []string{p.config.urls[0]}, TrackerParser: p.observeParser("rutor", rutorParser)})},
+		{name: "kinozal", tracker: tracker.Init(tracker.Config{Name: "kinozal", Urls: []string{p.config.urls[1]}, TrackerParser: p.observeParser("kinozal", kinozalParser)})},
+	}, nil
+}
+
+// SearchStream runs the tracker searches of RunTrackersSearchPipeline but
+// yields every page as soon as its tracker has parsed it, so a UI can show
+// rutor results before the kinozal magnet lookups finish. The stream ends
+// after the first tracker error, which it yields, or when the caller stops
+// iterating; the remaining searches are canceled either way. Once the stream
+// is drained without error, GetTorrents returns every streamed torrent and
+// the usual stages can follow.
+//
+//	for batch, err := range executor.Init(*env).WithContext(ctx).SearchStream(true) {
+//		if err != nil {
+//			return err
+//		}
+//		show(batch.Tracker, batch.Torrents)
+//	}
+func (p *TrackersPipeline) SearchStream(isMovie bool) iter.Seq2[SearchBatch, error] {
+	return func(yield func(SearchBatch, error) bool) {
+		if len(p.errors) > 0 {
+			yield(SearchBatch{}, p.HandleErrors())
+			return
+		}
+		sources, err := p.trackerSources(isMovie)
+		if err != nil {
+			p.addError(err)
+			yield(SearchBatch{}, err)
+			return
+		}
+		p.streamSources(sources)(yield)
+	}
+}
+
+// SearchMoviesStream is SearchStream with the torrents of every batch also
+// run through ConvertTorrentsToMovieShort, Tmdb, Genres and Ratings.
+func (p *TrackersPipeline) SearchMoviesStream(isMovie bool) iter.Seq2[SearchBatch, error] {
+	return p.enrichBatches(p.SearchStream(isMovie))
+}
+
+func (p *TrackersPipeline) enrichBatches(batches iter.Seq2[SearchBatch, error]) iter.Seq2[SearchBatch, error] {
+	return func(yield func(SearchBatch, error) bool) {
+		for batch, err := range batches {
+			if err != nil {
+				yield(batch, err)
+				return
+			}
+
+			stage := &TrackersPipeline{config: p.config, ctx: p.ctx, observers: p.observers, genres: p.genres, torrents: batch.Torrents}
+			stage.ConvertTorrentsToMovieShort().Tmdb().Genres().Ratings()
+			p.genres = stage.genres
+			p.skipped = append(p.skipped, stage.skipped...)
+			if err := stage.HandleErrors(); err != nil {
+				p.errors = append(p.errors, stage.errors...)
+				yield(batch, err)
+				return
+			}
+
+			batch.Movies = stage.movies
+			if !yield(batch, nil) {
+				return
+			}
+		}
+	}
+}
+
+type trackerPage struct {
+	tracker  string
+	torrents []*torrents.Torrent
+	err      error
+}
+
+func (p *TrackersPipeline) streamSources(sources []trackerSource) iter.Seq2[SearchBatch, error] {
+	return func(yield func(SearchBatch, error) bool) {
+		done := p.startStage(StageTrackers, StageStats{})
+		streamed := make([]*torrents.Torrent, 0)
+		defer func() { done(StageStats{Torrents: len(streamed)}) }()
+
+		ctx, cancel := context.WithCancel(p.context())
+		defer cancel()
+
+		pages := make(chan trackerPage)
+		var wg sync.WaitGroup
+		for _, src := range sources {
+			values, errs := src.tracker.TorrentsPipelineStream(ctx)
+			wg.Go(func() {
+				forwardPages(ctx, src.name, values, errs, pages)
+			})
+		}
+		go func() {
+			wg.Wait()
+			close(pages)
+		}()
+
+		seen := make(map[string]struct{})
+		counts := make(map[string]int, len(sources))
+		for page := range pages {
+			if page.err != nil {
+				err := p.context().Err()
+				if err == nil {
+					err = fmt.Errorf("%s: %w", page.tracker, page.err)
+				}
+				p.addError(err)
+				yield(SearchBatch{Tracker: page.tracker}, err)
+				return
+			}
+
+			batch := SearchBatch{Tracker: page.tracker}
+			for _, t := range page.torrents {
+				if t == nil {
+					continue
+				}
+				if t.MagnetHash != "" {
+					hash := strings.ToLower(t.MagnetHash)
+					if _, ok := seen[hash]; ok {
+						continue
+					}
+					seen[hash] = struct{}{}
+				}
+				batch.Torrents = append(batch.Torrents, t)
+			}
+			counts[page.tracker] += len(page.torrents)
+			if len(batch.Torrents) == 0 {
+				continue
+			}
+			streamed = append(streamed, batch.Torrents...)
+			if !yield(batch, nil) {
+				return
+			}
+		}
+
+		if err := p.context().Err(); err != nil {
+			p.addError(err)
+			yield(SearchBatch{}, err)
+			return
+		}
+		p.torrents = streamed
+		slog.InfoContext(p.context(), "tracker stream completed", "rutor_torrents", counts["rutor"], "kinozal_torrents", counts["kinozal"], "torrents_after_dedupe", len(streamed))
+	}
+}
+
+// forwardPages passes the results of one tracker to out until its channels
+// close, it fails or ctx is done.
+func forwardPages(ctx context.Context, name string, values <-chan []*torrents.Torrent, errs <-chan error, out chan<- trackerPage) {
+	send := func(page trackerPage) bool {
+		select {
+		case out <- page:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for values != nil || errs != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				send(trackerPage{tracker: name, err: err})
+				return
+			}
+		case res, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			if !send(trackerPage{tracker: name, torrents: res}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts the "executor.<stage>" spans; every stage runs with its span's
+// context, so the tracker, TMDB and MongoDB spans of a stage nest under it.
+// Spans are dropped unless the process installs a tracer provider, see
+// pkg/tracing.
+var tracer = otel.Tracer("github.com/lieranderl/moviestracker-package/executor")
+
+func statsAttributes(prefix string, s StageStats) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int(prefix+".torrents", s.Torrents),
+		attribute.Int(prefix+".movies", s.Movies),
+		attribute.Int(prefix+".skipped", s.Skipped),
+	}
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceMongoWrite starts the "mongo.write" span of saving documents to
+// collection and returns its context and the func that ends it with the errors
+// recorded in between and reports the write to the observers.
+func (p *TrackersPipeline) traceMongoWrite(ctx context.Context, collection string, documents int) (context.Context, func()) {
+	errs, started := len(p.errors), time.Now()
+	ctx, span := tracer.Start(ctx, "mongo.write", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system.name", "mongodb"),
+		attribute.String("db.namespace", mongoDBName),
+		attribute.String("db.collection.name", collection),
+		attribute.Int("db.operation.batch.size", documents),
+	))
+	return ctx, func() {
+		err, elapsed := errors.Join(p.errors[errs:]...), time.Since(started)
+		endSpan(span, err)
+		p.notifyContext(ctx, func(ctx context.Context, o Observer) { o.OnMongoWrite(ctx, collection, documents, elapsed, err) })
+	}
+}
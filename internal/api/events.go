@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+)
+
+// sseKeepAlive is how often an idle event stream gets a comment line, so
+// proxies do not drop it while a slow tracker is parsed.
+const sseKeepAlive = 15 * time.Second
+
+// Event is one step of a running search. GET /search/events sends it as
+// "event: <Name>" with Data encoded as JSON.
+type Event struct {
+	Name string
+	Data any
+}
+
+// ProgressFunc receives the progress of a search; it may be called from
+// several goroutines at once.
+type ProgressFunc func(Event)
+
+// handleSearchEvents runs a search like GET /search but answers with a
+// text/event-stream: the progress events of the search, then either a
+// "result" event with the SearchResponse or an "error" event with an
+// ErrorDetail.
+func (s *Server) handleSearchEvents(w http.ResponseWriter, r *http.Request) {
+	req, err := parseSearch(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.searchTimeout)
+	defer cancel()
+
+	type outcome struct {
+		movies []*movies.Short
+		err    error
+	}
+	events := make(chan Event, 32)
+	done := make(chan outcome, 1)
+	go func() {
+		// withRecovery does not see panics of this goroutine.
+		defer func() {
+			if v := recover(); v != nil {
+				slog.ErrorContext(r.Context(), "search panicked", "panic", v)
+				done <- outcome{err: fmt.Errorf("search panicked: %v", v)}
+			}
+		}()
+		found, err := s.search(ctx, req, func(e Event) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+		done <- outcome{found, err}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	stream := &eventWriter{w: w, rc: http.NewResponseController(w)}
+	stream.comment("search started")
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e := <-events:
+			stream.send(e)
+		case <-keepAlive.C:
+			stream.comment("keep-alive")
+		case res := <-done:
+			for pending := true; pending; {
+				select {
+				case e := <-events:
+					stream.send(e)
+				default:
+					pending = false
+				}
+			}
+			if res.err == nil {
+				res.err = ctx.Err()
+			}
+			if res.err != nil {
+				s.sendFailure(stream, r, ctx, res.err)
+				return
+			}
+			found := res.movies
+			if found == nil {
+				found = []*movies.Short{}
+			}
+			stream.send(Event{Name: "result", Data: SearchResponse{Query: req.Query, Year: req.Year, Type: searchType(req.Movie), Movies: found}})
+			return
+		}
+	}
+}
+
+// sendFailure is writeFailure for an event stream that has already started.
+func (s *Server) sendFailure(stream *eventWriter, r *http.Request, ctx context.Context, err error) {
+	slog.ErrorContext(r.Context(), "search failed", "error", err)
+	detail := ErrorDetail{Status: http.StatusBadGateway, Message: "search failed", RequestID: RequestID(r.Context())}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded):
+		detail.Status, detail.Message = http.StatusGatewayTimeout, "search failed: timed out"
+	case r.Context().Err() != nil:
+		return
+	}
+	stream.send(Event{Name: "error", Data: detail})
+}
+
+// eventWriter writes server-sent events and flushes each one. Write errors
+// mean the client is gone; they are ignored because the request context ends
+// the search then.
+type eventWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (e *eventWriter) send(event Event) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("unencodable %s event: %v", event.Name, err))
+	}
+	_, _ = fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event.Name, data)
+	_ = e.rc.Flush()
+}
+
+func (e *eventWriter) comment(text string) {
+	_, _ = fmt.Fprintf(e.w, ": %s\n\n", text)
+	_ = e.rc.Flush()
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+)
+
+// RequestIDHeader is echoed back or, when the client did not send one,
+// generated; it also tags the request's log lines and error bodies. The
+// request context carries it as the "request_id" log attribute (see
+// logging.With), so everything logged with that context has it.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID returns the id of the request ctx belongs to.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ErrorBody is the JSON body of every error response.
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Status    int    `json:"status"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.WarnContext(r.Context(), "write response failed", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeJSON(w, r, status, ErrorBody{Error: ErrorDetail{Status: status, Message: message, RequestID: RequestID(r.Context())}})
+}
+
+// writeFailure maps a failed search or store call to 504 when it ran out of
+// time and 502 otherwise; the cause is logged, not returned to the client.
+func writeFailure(w http.ResponseWriter, r *http.Request, ctx context.Context, message string, err error) {
+	slog.ErrorContext(r.Context(), message, "error", err)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded):
+		writeError(w, r, http.StatusGatewayTimeout, message+": timed out")
+	case r.Context().Err() != nil:
+		// The client is gone; nobody reads the response.
+	default:
+		writeError(w, r, http.StatusBadGateway, message)
+	}
+}
+
+// get only lets GET and HEAD requests through.
+func get(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h(w, r)
+	})
+}
+
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			var b [8]byte
+			_, _ = rand.Read(b[:])
+			id = hex.EncodeToString(b[:])
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logging.With(context.WithValue(r.Context(), requestIDKey{}, id), "request_id", id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				slog.ErrorContext(r.Context(), "handler panicked", "panic", v)
+				writeError(w, r, http.StatusInternalServerError, "internal error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func withLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		slog.InfoContext(r.Context(), "http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"elapsed", time.Since(start).String(),
+		)
+	})
+}
@@ -0,0 +1,315 @@
+// Package api serves tracker searches and saved movies over HTTP as JSON.
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+const (
+	DefaultSearchTimeout = 2 * time.Minute
+	defaultStoreTimeout  = 10 * time.Second
+
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// SearchRequest is a parsed GET /search.
+type SearchRequest struct {
+	Query string
+	Year  string
+	Movie bool
+}
+
+// SearchFunc runs the tracker and metadata pipeline for a search; it must stop
+// when ctx is done. progress is nil unless the client asked for live
+// progress (GET /search/events).
+type SearchFunc func(ctx context.Context, req SearchRequest, progress ProgressFunc) ([]*movies.Short, error)
+
+// MovieStore reads saved movies.
+type MovieStore interface {
+	List(ctx context.Context, opts movies.ListOptions) ([]*movies.Short, error)
+	Get(ctx context.Context, id string) (*movies.Short, bool, error)
+	Ping(ctx context.Context) error
+}
+
+// Config wires the server. Without a Store the /movies endpoints answer 503.
+type Config struct {
+	Search        SearchFunc
+	Store         MovieStore
+	SearchTimeout time.Duration
+	StoreTimeout  time.Duration
+}
+
+type Server struct {
+	search        SearchFunc
+	store         MovieStore
+	searchTimeout time.Duration
+	storeTimeout  time.Duration
+}
+
+func New(cfg Config) *Server {
+	s := &Server{
+		search:        cfg.Search,
+		store:         cfg.Store,
+		searchTimeout: cfg.SearchTimeout,
+		storeTimeout:  cfg.StoreTimeout,
+	}
+	if s.searchTimeout <= 0 {
+		s.searchTimeout = DefaultSearchTimeout
+	}
+	if s.storeTimeout <= 0 {
+		s.storeTimeout = defaultStoreTimeout
+	}
+	return s
+}
+
+// Handler routes the endpoints:
+//
+//	GET /search?q=&year=&type=movie|series
+//	GET /search/events?q=&year=&type=movie|series (text/event-stream)
+//	GET /movies?q=&year=&limit=&offset=&format=json|legacy
+//	GET /movies/{id}?format=json|legacy
+//	GET /movies/{id}/torrents
+//	GET /healthz, GET /readyz
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/search", get(s.handleSearch))
+	mux.Handle("/search/events", get(s.handleSearchEvents))
+	mux.Handle("/movies", get(s.handleMovies))
+	mux.Handle("/movies/{id}", get(s.handleMovie))
+	mux.Handle("/movies/{id}/torrents", get(s.handleTorrents))
+	mux.Handle("/healthz", get(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+	}))
+	mux.Handle("/readyz", get(s.handleReady))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, http.StatusNotFound, "no such endpoint")
+	})
+	return withRequestID(withRecovery(withLogging(mux)))
+}
+
+// SearchResponse is the body of GET /search.
+type SearchResponse struct {
+	Query  string          `json:"query"`
+	Year   string          `json:"year,omitempty"`
+	Type   string          `json:"type"`
+	Movies []*movies.Short `json:"movies"`
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	req, err := parseSearch(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.searchTimeout)
+	defer cancel()
+	found, err := s.search(ctx, req, nil)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		writeFailure(w, r, ctx, "search failed", err)
+		return
+	}
+	if found == nil {
+		found = []*movies.Short{}
+	}
+	writeJSON(w, r, http.StatusOK, SearchResponse{Query: req.Query, Year: req.Year, Type: searchType(req.Movie), Movies: found})
+}
+
+// parseSearch reads q (required), year (optional, four digits) and type
+// ("movie", the default, or "series").
+func parseSearch(r *http.Request) (SearchRequest, error) {
+	params := r.URL.Query()
+	req := SearchRequest{Query: strings.TrimSpace(params.Get("q")), Year: strings.TrimSpace(params.Get("year")), Movie: true}
+	if req.Query == "" {
+		return req, errors.New("q is required")
+	}
+	if req.Year != "" {
+		if y, err := strconv.Atoi(req.Year); err != nil || len(req.Year) != 4 || y < 1870 {
+			return req, fmt.Errorf("invalid year %q", req.Year)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(params.Get("type"))) {
+	case "", "movie":
+	case "series":
+		req.Movie = false
+	default:
+		return req, fmt.Errorf("invalid type %q (movie|series)", params.Get("type"))
+	}
+	return req, nil
+}
+
+func searchType(movie bool) string {
+	if movie {
+		return "movie"
+	}
+	return "series"
+}
+
+// MoviesResponse is the body of GET /movies.
+type MoviesResponse struct {
+	Movies []*movies.Short `json:"movies"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// legacyMoviesResponse is MoviesResponse for ?format=legacy.
+type legacyMoviesResponse struct {
+	Movies []movies.Legacy `json:"movies"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+func (s *Server) handleMovies(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w, r) {
+		return
+	}
+	opts, err := parseList(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	legacy, err := parseFormat(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.storeTimeout)
+	defer cancel()
+	found, err := s.store.List(ctx, opts)
+	if err != nil {
+		writeFailure(w, r, ctx, "reading movies failed", err)
+		return
+	}
+	if legacy {
+		writeJSON(w, r, http.StatusOK, legacyMoviesResponse{Movies: movies.LegacyMovies(found), Limit: opts.Limit, Offset: opts.Offset})
+		return
+	}
+	writeJSON(w, r, http.StatusOK, MoviesResponse{Movies: found, Limit: opts.Limit, Offset: opts.Offset})
+}
+
+// parseFormat reports whether the client asked for the legacy string shape of
+// movies (format=legacy) instead of the typed one (format=json, the default).
+func parseFormat(r *http.Request) (legacy bool, err error) {
+	switch format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))); format {
+	case "", "json":
+		return false, nil
+	case "legacy":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid format %q (json|legacy)", format)
+	}
+}
+
+func parseList(r *http.Request) (movies.ListOptions, error) {
+	params := r.URL.Query()
+	opts := movies.ListOptions{Query: strings.TrimSpace(params.Get("q")), Limit: defaultLimit}
+
+	for name, target := range map[string]*int{"year": &opts.Year, "limit": &opts.Limit, "offset": &opts.Offset} {
+		value := strings.TrimSpace(params.Get(name))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*target = n
+	}
+	if opts.Limit == 0 || opts.Limit > maxLimit {
+		return opts, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return opts, nil
+}
+
+func (s *Server) handleMovie(w http.ResponseWriter, r *http.Request) {
+	legacy, err := parseFormat(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	m, ok := s.findMovie(w, r)
+	switch {
+	case !ok:
+	case legacy:
+		writeJSON(w, r, http.StatusOK, movies.Legacy{Short: m})
+	default:
+		writeJSON(w, r, http.StatusOK, m)
+	}
+}
+
+// TorrentsResponse is the body of GET /movies/{id}/torrents.
+type TorrentsResponse struct {
+	ID       string              `json:"id"`
+	Torrents []*torrents.Torrent `json:"torrents"`
+}
+
+func (s *Server) handleTorrents(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.findMovie(w, r)
+	if !ok {
+		return
+	}
+	found := m.Torrents
+	if found == nil {
+		found = []*torrents.Torrent{}
+	}
+	writeJSON(w, r, http.StatusOK, TorrentsResponse{ID: m.ID, Torrents: found})
+}
+
+// findMovie writes the error response itself when it returns false.
+func (s *Server) findMovie(w http.ResponseWriter, r *http.Request) (*movies.Short, bool) {
+	if !s.requireStore(w, r) {
+		return nil, false
+	}
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.storeTimeout)
+	defer cancel()
+	m, found, err := s.store.Get(ctx, id)
+	if err != nil {
+		writeFailure(w, r, ctx, "reading movie failed", err)
+		return nil, false
+	}
+	if !found {
+		writeError(w, r, http.StatusNotFound, fmt.Sprintf("movie %q not found", id))
+		return nil, false
+	}
+	return m, true
+}
+
+func (s *Server) requireStore(w http.ResponseWriter, r *http.Request) bool {
+	if s.store == nil {
+		writeError(w, r, http.StatusServiceUnavailable, "movie storage is not configured")
+		return false
+	}
+	return true
+}
+
+// handleReady reports whether the storage answers; without storage the
+// server only searches and is always ready.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if s.store != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), s.storeTimeout)
+		defer cancel()
+		if err := s.store.Ping(ctx); err != nil {
+			slog.WarnContext(r.Context(), "readiness check failed", "error", err)
+			writeError(w, r, http.StatusServiceUnavailable, "movie storage is unavailable")
+			return
+		}
+	}
+	writeJSON(w, r, http.StatusOK, map[string]string{"status": "ready"})
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	movies []*movies.Short
+	err    error
+	opts   movies.ListOptions
+}
+
+func (s *memoryStore) List(_ context.Context, opts movies.ListOptions) ([]*movies.Short, error) {
+	s.opts = opts
+	return s.movies, s.err
+}
+
+func (s *memoryStore) Get(_ context.Context, id string) (*movies.Short, bool, error) {
+	for _, m := range s.movies {
+		if m.ID == id {
+			return m, true, nil
+		}
+	}
+	return nil, false, s.err
+}
+
+func (s *memoryStore) Ping(context.Context) error {
+	return s.err
+}
+
+func do(t *testing.T, h http.Handler, method, target string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return rec, body
+}
+
+func errorStatus(body map[string]any) any {
+	return body["error"].(map[string]any)["status"]
+}
+
+func TestSearch(t *testing.T) {
+	var got SearchRequest
+	search := func(_ context.Context, req SearchRequest, _ ProgressFunc) ([]*movies.Short, error) {
+		got = req
+		return []*movies.Short{{ID: "573435", Title: "Bad Boys: Ride or Die"}}, nil
+	}
+	h := New(Config{Search: search}).Handler()
+
+	rec, body := do(t, h, http.MethodGet, "/search?q=Bad+Boys&year=2024&type=series")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, SearchRequest{Query: "Bad Boys", Year: "2024", Movie: false}, got)
+	require.Equal(t, "series", body["type"])
+	require.Len(t, body["movies"], 1)
+	require.NotEmpty(t, rec.Header().Get(RequestIDHeader))
+
+	for _, target := range []string{"/search", "/search?q=a&year=24", "/search?q=a&type=music"} {
+		rec, body := do(t, h, http.MethodGet, target)
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+		require.EqualValues(t, http.StatusBadRequest, errorStatus(body))
+	}
+}
+
+func TestSearchFailures(t *testing.T) {
+	slow := func(ctx context.Context, _ SearchRequest, _ ProgressFunc) ([]*movies.Short, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	rec, body := do(t, New(Config{Search: slow, SearchTimeout: 10 * time.Millisecond}).Handler(), http.MethodGet, "/search?q=a")
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	require.Equal(t, "search failed: timed out", body["error"].(map[string]any)["message"])
+
+	broken := func(context.Context, SearchRequest, ProgressFunc) ([]*movies.Short, error) {
+		return nil, errors.New("rutor: status 503")
+	}
+	rec, body = do(t, New(Config{Search: broken}).Handler(), http.MethodGet, "/search?q=a")
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+	require.NotContains(t, body["error"].(map[string]any)["message"], "rutor", "causes stay in the logs")
+}
+
+type sentEvent struct {
+	name string
+	data map[string]any
+}
+
+func readEvents(t *testing.T, body string) []sentEvent {
+	t.Helper()
+	var events []sentEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var e sentEvent
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				e.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data), line)
+			}
+		}
+		if e.name != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestSearchEvents(t *testing.T) {
+	search := func(_ context.Context, req SearchRequest, progress ProgressFunc) ([]*movies.Short, error) {
+		if progress == nil {
+			return nil, errors.New("no progress callback")
+		}
+		progress(Event{Name: "tracker_result", Data: map[string]any{"tracker": "rutor", "torrents": 3}})
+		progress(Event{Name: "stage_completed", Data: map[string]any{"stage": "tmdb", "movies": 1}})
+		return []*movies.Short{{ID: "573435"}}, nil
+	}
+	rec := httptest.NewRecorder()
+	New(Config{Search: search}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search/events?q=Bad+Boys&year=2024", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	events := readEvents(t, rec.Body.String())
+	require.Len(t, events, 3)
+	require.Equal(t, "tracker_result", events[0].name)
+	require.EqualValues(t, 3, events[0].data["torrents"])
+	require.Equal(t, "stage_completed", events[1].name)
+	require.Equal(t, "result", events[2].name)
+	require.Equal(t, "Bad Boys", events[2].data["query"])
+	require.Len(t, events[2].data["movies"], 1)
+
+	rec, body := do(t, New(Config{Search: search}).Handler(), http.MethodGet, "/search/events?q=a&type=music")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.EqualValues(t, http.StatusBadRequest, errorStatus(body))
+}
+
+func TestSearchEventsFailures(t *testing.T) {
+	slow := func(ctx context.Context, _ SearchRequest, progress ProgressFunc) ([]*movies.Short, error) {
+		progress(Event{Name: "stage_started", Data: map[string]any{"stage": "trackers"}})
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	rec := httptest.NewRecorder()
+	New(Config{Search: slow, SearchTimeout: 10 * time.Millisecond}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search/events?q=a", nil))
+	events := readEvents(t, rec.Body.String())
+	require.Len(t, events, 2)
+	require.Equal(t, "error", events[1].name)
+	require.EqualValues(t, http.StatusGatewayTimeout, events[1].data["status"])
+
+	broken := func(context.Context, SearchRequest, ProgressFunc) ([]*movies.Short, error) {
+		panic("boom")
+	}
+	rec = httptest.NewRecorder()
+	New(Config{Search: broken}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search/events?q=a", nil))
+	events = readEvents(t, rec.Body.String())
+	require.Len(t, events, 1)
+	require.Equal(t, "error", events[0].name)
+	require.Equal(t, "search failed", events[0].data["message"], "causes stay in the logs")
+	require.NotEmpty(t, events[0].data["request_id"])
+}
+
+func TestMovies(t *testing.T) {
+	store := &memoryStore{movies: []*movies.Short{{
+		ID:       "573435",
+		Title:    "Bad Boys: Ride or Die",
+		Torrents: []*torrents.Torrent{{Name: "Bad Boys 2160p", MagnetHash: "abc"}},
+	}, {ID: "693134"}}}
+	h := New(Config{Store: store}).Handler()
+
+	rec, body := do(t, h, http.MethodGet, "/movies?q=bad&year=2024&offset=20")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, body["movies"], 2)
+	require.Equal(t, movies.ListOptions{Query: "bad", Year: 2024, Limit: defaultLimit, Offset: 20}, store.opts)
+
+	rec, body = do(t, h, http.MethodGet, "/movies/573435")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "Bad Boys: Ride or Die", body["title"])
+
+	rec, body = do(t, h, http.MethodGet, "/movies/573435?format=legacy")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "0.0", body["vote_average"])
+
+	rec, body = do(t, h, http.MethodGet, "/movies?format=legacy")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "0", body["movies"].([]any)[1].(map[string]any)["vote_count"])
+
+	rec, _ = do(t, h, http.MethodGet, "/movies?format=xml")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec, body = do(t, h, http.MethodGet, "/movies/573435/torrents")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "573435", body["id"])
+	require.Len(t, body["torrents"], 1)
+
+	rec, body = do(t, h, http.MethodGet, "/movies/693134/torrents")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []any{}, body["torrents"])
+
+	rec, body = do(t, h, http.MethodGet, "/movies/missing")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.EqualValues(t, http.StatusNotFound, errorStatus(body))
+
+	rec, _ = do(t, h, http.MethodGet, "/movies?limit=1000")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMoviesWithoutStore(t *testing.T) {
+	h := New(Config{}).Handler()
+
+	rec, _ := do(t, h, http.MethodGet, "/movies")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	rec, _ = do(t, h, http.MethodGet, "/readyz")
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRoutingErrors(t *testing.T) {
+	h := New(Config{Store: &memoryStore{err: errors.New("connection refused")}}).Handler()
+
+	rec, body := do(t, h, http.MethodPost, "/movies")
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+	require.EqualValues(t, http.StatusMethodNotAllowed, errorStatus(body))
+
+	rec, _ = do(t, h, http.MethodGet, "/nope")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, _ = do(t, h, http.MethodGet, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+}
+
+func TestRecoversFromPanics(t *testing.T) {
+	search := func(context.Context, SearchRequest, ProgressFunc) ([]*movies.Short, error) { panic("boom") }
+	rec, body := do(t, New(Config{Search: search}).Handler(), http.MethodGet, "/search?q=a")
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.NotEmpty(t, body["error"].(map[string]any)["request_id"])
+}
+
+func TestAccessLogCarriesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(logging.Handler(slog.NewJSONHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(RequestIDHeader, "req-7")
+	New(Config{}).Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line), buf.String())
+	require.Equal(t, "http request", line["msg"])
+	require.Equal(t, "req-7", line["request_id"])
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MongoStore reads the movies SaveToMongo writes.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+func (s *MongoStore) List(ctx context.Context, opts movies.ListOptions) ([]*movies.Short, error) {
+	return movies.FindMovies(ctx, s.collection, opts)
+}
+
+func (s *MongoStore) Get(ctx context.Context, id string) (*movies.Short, bool, error) {
+	return movies.FindMovie(ctx, s.collection, id)
+}
+
+func (s *MongoStore) Ping(ctx context.Context) error {
+	return s.collection.Database().Client().Ping(ctx, nil)
+}
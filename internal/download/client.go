@@ -0,0 +1,50 @@
+// Package download hands releases over to a torrent client.
+package download
+
+import (
+	"context"
+	"strings"
+)
+
+// AddOptions are applied to a torrent when it is added; empty values keep the
+// client's defaults.
+type AddOptions struct {
+	Category string
+	SavePath string
+	Tags     []string
+	Paused   bool
+}
+
+// Status is what a client reports about a torrent.
+type Status struct {
+	Hash     string
+	Name     string
+	State    string
+	Progress float64
+	Size     int64
+	Category string
+	SavePath string
+	Tags     []string
+}
+
+// Client is a torrent client that accepts magnet links.
+type Client interface {
+	// Name identifies the client in logs, e.g. "qbittorrent".
+	Name() string
+	Add(ctx context.Context, magnet string, opts AddOptions) error
+	// Status looks a torrent up by its info hash; ok is false when the client
+	// does not have it.
+	Status(ctx context.Context, infoHash string) (status *Status, ok bool, err error)
+}
+
+// SplitTags parses a comma separated tag list such as QBITTORRENT_TAGS or
+// the tags qBittorrent reports.
+func SplitTags(value string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
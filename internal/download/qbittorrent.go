@@ -0,0 +1,184 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnauthorized means the client rejected the configured credentials.
+var ErrUnauthorized = errors.New("torrent client rejected the credentials")
+
+// QBittorrent talks to the qBittorrent Web API (v2). It logs in on first use
+// and again whenever the session cookie expires.
+type QBittorrent struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+
+	mu       sync.Mutex
+	loggedIn bool
+}
+
+func NewQBittorrent(baseURL, username, password string) *QBittorrent {
+	jar, _ := cookiejar.New(nil)
+	return &QBittorrent{
+		baseURL:  strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 30 * time.Second, Jar: jar},
+	}
+}
+
+func (q *QBittorrent) Name() string {
+	return "qbittorrent"
+}
+
+// Add adds a magnet link. qBittorrent answers "Fails." when it rejects the
+// link, e.g. because the torrent is already there.
+func (q *QBittorrent) Add(ctx context.Context, magnet string, opts AddOptions) error {
+	form := url.Values{"urls": {magnet}}
+	if opts.Category != "" {
+		form.Set("category", opts.Category)
+	}
+	if opts.SavePath != "" {
+		form.Set("savepath", opts.SavePath)
+	}
+	if len(opts.Tags) > 0 {
+		form.Set("tags", strings.Join(opts.Tags, ","))
+	}
+	if opts.Paused {
+		// "paused" before qBittorrent 5, "stopped" since.
+		form.Set("paused", "true")
+		form.Set("stopped", "true")
+	}
+
+	body, err := q.do(ctx, http.MethodPost, "/api/v2/torrents/add", form)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) == "Fails." {
+		return errors.New("qbittorrent add: torrent rejected")
+	}
+	return nil
+}
+
+func (q *QBittorrent) Status(ctx context.Context, infoHash string) (*Status, bool, error) {
+	body, err := q.do(ctx, http.MethodGet, "/api/v2/torrents/info", url.Values{"hashes": {strings.ToLower(infoHash)}})
+	if err != nil {
+		return nil, false, err
+	}
+
+	var found []struct {
+		Hash     string  `json:"hash"`
+		Name     string  `json:"name"`
+		State    string  `json:"state"`
+		Progress float64 `json:"progress"`
+		Size     int64   `json:"size"`
+		Category string  `json:"category"`
+		SavePath string  `json:"save_path"`
+		Tags     string  `json:"tags"`
+	}
+	if err := json.Unmarshal(body, &found); err != nil {
+		return nil, false, fmt.Errorf("decode qbittorrent torrents: %w", err)
+	}
+	if len(found) == 0 {
+		return nil, false, nil
+	}
+	t := found[0]
+	return &Status{
+		Hash:     t.Hash,
+		Name:     t.Name,
+		State:    t.State,
+		Progress: t.Progress,
+		Size:     t.Size,
+		Category: t.Category,
+		SavePath: t.SavePath,
+		Tags:     SplitTags(t.Tags),
+	}, true, nil
+}
+
+// do sends an authenticated request and logs in again once when the session
+// has expired.
+func (q *QBittorrent) do(ctx context.Context, method, path string, params url.Values) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := q.login(ctx, attempt > 0); err != nil {
+			return nil, err
+		}
+		status, body, err := q.send(ctx, method, path, params)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusForbidden && attempt == 0 {
+			continue
+		}
+		if status < 200 || status > 299 {
+			return nil, fmt.Errorf("qbittorrent %s: status %d: %s", path, status, strings.TrimSpace(string(body)))
+		}
+		return body, nil
+	}
+}
+
+func (q *QBittorrent) login(ctx context.Context, force bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.loggedIn && !force {
+		return nil
+	}
+
+	status, body, err := q.send(ctx, http.MethodPost, "/api/v2/auth/login", url.Values{
+		"username": {q.username},
+		"password": {q.password},
+	})
+	if err != nil {
+		return err
+	}
+	if status == http.StatusForbidden || strings.TrimSpace(string(body)) == "Fails." {
+		return fmt.Errorf("qbittorrent login: %w", ErrUnauthorized)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("qbittorrent login: status %d", status)
+	}
+	q.loggedIn = true
+	return nil
+}
+
+func (q *QBittorrent) send(ctx context.Context, method, path string, params url.Values) (int, []byte, error) {
+	target := q.baseURL + path
+	var body io.Reader
+	if method == http.MethodGet {
+		target += "?" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	// qBittorrent's CSRF protection compares Referer with its own address.
+	req.Header.Set("Referer", q.baseURL)
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("qbittorrent %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return 0, nil, fmt.Errorf("qbittorrent %s: %w", path, err)
+	}
+	return resp.StatusCode, raw, nil
+}
@@ -0,0 +1,154 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQBittorrent implements the parts of the Web API the client uses:
+// cookie login, torrents/add and torrents/info.
+type fakeQBittorrent struct {
+	mu       sync.Mutex
+	sid      string
+	logins   int
+	torrents map[string]map[string]string
+}
+
+func newFakeQBittorrent(t *testing.T) (*fakeQBittorrent, string) {
+	fake := &fakeQBittorrent{torrents: map[string]map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/auth/login", fake.login)
+	mux.HandleFunc("POST /api/v2/torrents/add", fake.authorized(fake.add))
+	mux.HandleFunc("GET /api/v2/torrents/info", fake.authorized(fake.info))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return fake, srv.URL
+}
+
+func (f *fakeQBittorrent) login(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+		fmt.Fprint(w, "Fails.")
+		return
+	}
+	f.logins++
+	f.sid = fmt.Sprintf("sid-%d", f.logins)
+	http.SetCookie(w, &http.Cookie{Name: "SID", Value: f.sid, Path: "/"})
+	fmt.Fprint(w, "Ok.")
+}
+
+// expire drops the session, as qBittorrent does after its session timeout.
+func (f *fakeQBittorrent) expire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sid = ""
+}
+
+func (f *fakeQBittorrent) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		cookie, err := r.Cookie("SID")
+		ok := err == nil && f.sid != "" && cookie.Value == f.sid
+		f.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "Forbidden")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (f *fakeQBittorrent) add(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	magnet := r.FormValue("urls")
+	_, hash, ok := strings.Cut(magnet, "urn:btih:")
+	if !ok {
+		fmt.Fprint(w, "Fails.")
+		return
+	}
+	hash, _, _ = strings.Cut(strings.ToLower(hash), "&")
+	f.torrents[hash] = map[string]string{
+		"category":  r.FormValue("category"),
+		"save_path": r.FormValue("savepath"),
+		"tags":      r.FormValue("tags"),
+		"paused":    r.FormValue("paused"),
+	}
+	fmt.Fprint(w, "Ok.")
+}
+
+func (f *fakeQBittorrent) info(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	found := []map[string]any{}
+	hash := r.URL.Query().Get("hashes")
+	if t, ok := f.torrents[hash]; ok {
+		found = append(found, map[string]any{
+			"hash": hash, "name": "Bad Boys", "state": "downloading", "progress": 0.25, "size": 1024,
+			"category": t["category"], "save_path": t["save_path"], "tags": strings.ReplaceAll(t["tags"], ",", ", "),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(found)
+}
+
+func TestQBittorrentAddAndStatus(t *testing.T) {
+	ctx := context.Background()
+	fake, baseURL := newFakeQBittorrent(t)
+	client := NewQBittorrent(baseURL+"/", "admin", "secret")
+
+	_, ok, err := client.Status(ctx, "ABC")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	opts := AddOptions{Category: "movies", SavePath: "/data/movies", Tags: []string{"watch", "4k"}, Paused: true}
+	require.NoError(t, client.Add(ctx, "magnet:?xt=urn:btih:ABC&dn=Bad+Boys", opts))
+	require.Equal(t, "true", fake.torrents["abc"]["paused"])
+
+	status, ok, err := client.Status(ctx, "ABC")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, &Status{
+		Hash:     "abc",
+		Name:     "Bad Boys",
+		State:    "downloading",
+		Progress: 0.25,
+		Size:     1024,
+		Category: "movies",
+		SavePath: "/data/movies",
+		Tags:     []string{"watch", "4k"},
+	}, status)
+	require.Equal(t, 1, fake.logins)
+
+	require.ErrorContains(t, client.Add(ctx, "not a magnet", AddOptions{}), "torrent rejected")
+}
+
+func TestQBittorrentLogsInAgainAfterExpiry(t *testing.T) {
+	ctx := context.Background()
+	fake, baseURL := newFakeQBittorrent(t)
+	client := NewQBittorrent(baseURL, "admin", "secret")
+
+	_, _, err := client.Status(ctx, "abc")
+	require.NoError(t, err)
+	fake.expire()
+	_, _, err = client.Status(ctx, "abc")
+	require.NoError(t, err)
+	require.Equal(t, 2, fake.logins)
+}
+
+func TestQBittorrentWrongCredentials(t *testing.T) {
+	_, baseURL := newFakeQBittorrent(t)
+	client := NewQBittorrent(baseURL, "admin", "wrong")
+
+	err := client.Add(context.Background(), "magnet:?xt=urn:btih:abc", AddOptions{})
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
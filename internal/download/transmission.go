@@ -0,0 +1,173 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sessionIDHeader = "X-Transmission-Session-Id"
+
+// transmissionStates names the torrent status codes of the RPC protocol.
+var transmissionStates = map[int]string{
+	0: "stopped",
+	1: "check_wait",
+	2: "checking",
+	3: "download_wait",
+	4: "downloading",
+	5: "seed_wait",
+	6: "seeding",
+}
+
+// Transmission talks to the Transmission RPC endpoint, e.g.
+// http://localhost:9091/transmission/rpc. Transmission has no categories, so
+// AddOptions.Category is added as a label next to the tags (labels need
+// Transmission 4).
+type Transmission struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func NewTransmission(rpcURL, username, password string) *Transmission {
+	return &Transmission{
+		url:      strings.TrimSpace(rpcURL),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (t *Transmission) Name() string {
+	return "transmission"
+}
+
+// Add adds a magnet link; a torrent Transmission already has is not an error.
+func (t *Transmission) Add(ctx context.Context, magnet string, opts AddOptions) error {
+	args := map[string]any{"filename": magnet, "paused": opts.Paused}
+	if opts.SavePath != "" {
+		args["download-dir"] = opts.SavePath
+	}
+	var labels []string
+	if opts.Category != "" {
+		labels = append(labels, opts.Category)
+	}
+	labels = append(labels, opts.Tags...)
+	if len(labels) > 0 {
+		args["labels"] = labels
+	}
+
+	var added json.RawMessage
+	return t.call(ctx, "torrent-add", args, &added)
+}
+
+func (t *Transmission) Status(ctx context.Context, infoHash string) (*Status, bool, error) {
+	args := map[string]any{
+		"ids":    []string{strings.ToLower(infoHash)},
+		"fields": []string{"hashString", "name", "status", "percentDone", "totalSize", "downloadDir", "labels"},
+	}
+	var found struct {
+		Torrents []struct {
+			HashString  string   `json:"hashString"`
+			Name        string   `json:"name"`
+			Status      int      `json:"status"`
+			PercentDone float64  `json:"percentDone"`
+			TotalSize   int64    `json:"totalSize"`
+			DownloadDir string   `json:"downloadDir"`
+			Labels      []string `json:"labels"`
+		} `json:"torrents"`
+	}
+	if err := t.call(ctx, "torrent-get", args, &found); err != nil {
+		return nil, false, err
+	}
+	if len(found.Torrents) == 0 {
+		return nil, false, nil
+	}
+
+	tr := found.Torrents[0]
+	state, ok := transmissionStates[tr.Status]
+	if !ok {
+		state = fmt.Sprintf("status_%d", tr.Status)
+	}
+	return &Status{
+		Hash:     tr.HashString,
+		Name:     tr.Name,
+		State:    state,
+		Progress: tr.PercentDone,
+		Size:     tr.TotalSize,
+		SavePath: tr.DownloadDir,
+		Tags:     tr.Labels,
+	}, true, nil
+}
+
+// call sends one RPC request. Transmission answers 409 with a fresh session
+// id when the current one is missing or stale; the request is then repeated.
+func (t *Transmission) call(ctx context.Context, method string, args any, result any) error {
+	body, err := json.Marshal(map[string]any{"method": method, "arguments": args})
+	if err != nil {
+		return fmt.Errorf("encode transmission %s: %w", method, err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		t.mu.Lock()
+		if t.sessionID != "" {
+			req.Header.Set(sessionIDHeader, t.sessionID)
+		}
+		t.mu.Unlock()
+		if t.username != "" || t.password != "" {
+			req.SetBasicAuth(t.username, t.password)
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("transmission %s: %w", method, err)
+		}
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("transmission %s: %w", method, err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusConflict && attempt == 0:
+			t.mu.Lock()
+			t.sessionID = resp.Header.Get(sessionIDHeader)
+			t.mu.Unlock()
+			continue
+		case resp.StatusCode == http.StatusUnauthorized:
+			return fmt.Errorf("transmission %s: %w", method, ErrUnauthorized)
+		case resp.StatusCode != http.StatusOK:
+			return fmt.Errorf("transmission %s: status %d", method, resp.StatusCode)
+		}
+
+		var envelope struct {
+			Result    string          `json:"result"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			return fmt.Errorf("decode transmission %s: %w", method, err)
+		}
+		if envelope.Result != "success" {
+			return fmt.Errorf("transmission %s: %s", method, envelope.Result)
+		}
+		if err := json.Unmarshal(envelope.Arguments, result); err != nil {
+			return fmt.Errorf("decode transmission %s: %w", method, err)
+		}
+		return nil
+	}
+}
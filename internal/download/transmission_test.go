@@ -0,0 +1,138 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransmission implements torrent-add and torrent-get with the session id
+// handshake and basic auth of the Transmission RPC.
+type fakeTransmission struct {
+	mu        sync.Mutex
+	sessionID string
+	conflicts int
+	torrents  map[string]map[string]any
+}
+
+func newFakeTransmission(t *testing.T) (*fakeTransmission, string) {
+	fake := &fakeTransmission{sessionID: "session-1", torrents: map[string]map[string]any{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return fake, srv.URL + "/transmission/rpc"
+}
+
+func (f *fakeTransmission) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, pass, _ := r.BasicAuth(); user != "admin" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get(sessionIDHeader) != f.sessionID {
+		f.conflicts++
+		w.Header().Set(sessionIDHeader, f.sessionID)
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	var req struct {
+		Method    string         `json:"method"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	reply := func(result string, args any) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "arguments": args})
+	}
+	switch req.Method {
+	case "torrent-add":
+		magnet, _ := req.Arguments["filename"].(string)
+		_, hash, ok := strings.Cut(magnet, "urn:btih:")
+		if !ok {
+			reply("invalid or corrupt torrent file", map[string]any{})
+			return
+		}
+		hash, _, _ = strings.Cut(strings.ToLower(hash), "&")
+		if _, dup := f.torrents[hash]; dup {
+			reply("success", map[string]any{"torrent-duplicate": map[string]any{"hashString": hash}})
+			return
+		}
+		f.torrents[hash] = map[string]any{
+			"hashString": hash, "name": "Bad Boys", "status": 4, "percentDone": 0.5, "totalSize": 2048,
+			"downloadDir": req.Arguments["download-dir"], "labels": req.Arguments["labels"],
+		}
+		reply("success", map[string]any{"torrent-added": map[string]any{"hashString": hash}})
+	case "torrent-get":
+		found := []map[string]any{}
+		for _, id := range req.Arguments["ids"].([]any) {
+			if t, ok := f.torrents[id.(string)]; ok {
+				found = append(found, t)
+			}
+		}
+		reply("success", map[string]any{"torrents": found})
+	default:
+		reply("method name not recognized", map[string]any{})
+	}
+}
+
+func TestTransmissionAddAndStatus(t *testing.T) {
+	ctx := context.Background()
+	fake, rpcURL := newFakeTransmission(t)
+	var client Client = NewTransmission(rpcURL, "admin", "secret")
+
+	_, ok, err := client.Status(ctx, "ABC")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, 1, fake.conflicts, "the session id is fetched once")
+
+	opts := AddOptions{Category: "movies", SavePath: "/data/movies", Tags: []string{"watch"}}
+	require.NoError(t, client.Add(ctx, "magnet:?xt=urn:btih:ABC&dn=Bad+Boys", opts))
+	require.NoError(t, client.Add(ctx, "magnet:?xt=urn:btih:abc", opts), "duplicates are not an error")
+
+	status, ok, err := client.Status(ctx, "ABC")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, &Status{
+		Hash:     "abc",
+		Name:     "Bad Boys",
+		State:    "downloading",
+		Progress: 0.5,
+		Size:     2048,
+		SavePath: "/data/movies",
+		Tags:     []string{"movies", "watch"},
+	}, status)
+
+	require.ErrorContains(t, client.Add(ctx, "not a magnet", AddOptions{}), "invalid or corrupt torrent file")
+}
+
+func TestTransmissionRenewsSessionID(t *testing.T) {
+	ctx := context.Background()
+	fake, rpcURL := newFakeTransmission(t)
+	client := NewTransmission(rpcURL, "admin", "secret")
+
+	_, _, err := client.Status(ctx, "abc")
+	require.NoError(t, err)
+	fake.mu.Lock()
+	fake.sessionID = "session-2"
+	fake.mu.Unlock()
+
+	_, _, err = client.Status(ctx, "abc")
+	require.NoError(t, err)
+	require.Equal(t, 2, fake.conflicts)
+}
+
+func TestTransmissionWrongCredentials(t *testing.T) {
+	_, rpcURL := newFakeTransmission(t)
+	client := NewTransmission(rpcURL, "admin", "wrong")
+
+	_, _, err := client.Status(context.Background(), "abc")
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
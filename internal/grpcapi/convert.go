@@ -0,0 +1,97 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	pb "github.com/lieranderl/moviestracker-package/pkg/pb/moviestracker/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// TorrentToProto converts a tracker torrent to its protobuf message.
+func TorrentToProto(t *torrents.Torrent) *pb.Torrent {
+	if t == nil {
+		return nil
+	}
+	return &pb.Torrent{
+		Name:         t.Name,
+		DetailsUrl:   t.DetailsUrl,
+		OriginalName: t.OriginalName,
+		RussianName:  t.RussianName,
+		Year:         t.Year,
+		Size:         t.Size,
+		Magnet:       t.Magnet,
+		Date:         timestamp(t.Date),
+		K4:           t.K4,
+		Fhd:          t.FHD,
+		Hdr:          t.HDR,
+		Hdr10:        t.HDR10,
+		Hdr10Plus:    t.HDR10plus,
+		Dv:           t.DV,
+		Seeds:        t.Seeds,
+		Leeches:      t.Leeches,
+		Hash:         t.Hash,
+		MagnetHash:   t.MagnetHash,
+		ImdbId:       t.ImdbID,
+		KinopoiskId:  t.KinopoiskID,
+		Poster:       t.Poster,
+		Description:  t.Description,
+		Files:        t.Files,
+	}
+}
+
+// MovieToProto converts a movie and its torrents to its protobuf message.
+func MovieToProto(m *movies.Short) *pb.Movie {
+	if m == nil {
+		return nil
+	}
+	out := &pb.Movie{
+		Id:              m.ID,
+		Title:           m.Title,
+		OriginalTitle:   m.OriginalTitle,
+		Year:            int32(m.Year),
+		ReleaseDate:     timestamp(m.ReleaseDate),
+		VoteAverage:     m.VoteAverage,
+		VoteCount:       int32(m.VoteCount),
+		PosterPath:      m.PosterPath,
+		BackdropPath:    m.BackdropPath,
+		GenreIds:        m.GenreIDs,
+		Hash:            m.Hash,
+		Searchname:      m.Searchname,
+		LastTimeFound:   timestamp(m.LastTimeFound),
+		ImdbId:          m.ImdbID,
+		KinopoiskId:     m.KinopoiskID,
+		MatchStrategy:   m.MatchStrategy,
+		MatchConfidence: m.MatchConfidence,
+		LowConfidence:   m.LowConfidence,
+		Titles:          m.Titles,
+		Overviews:       m.Overviews,
+		Posters:         m.Posters,
+	}
+	if len(m.Genres) > 0 {
+		out.Genres = make(map[string]*pb.Genres, len(m.Genres))
+		for lang, names := range m.Genres {
+			out.Genres[lang] = &pb.Genres{Names: names}
+		}
+	}
+	if len(m.Ratings) > 0 {
+		out.Ratings = make(map[string]*pb.Rating, len(m.Ratings))
+		for source, r := range m.Ratings {
+			out.Ratings[source] = &pb.Rating{Value: r.Value, Votes: int32(r.Votes)}
+		}
+	}
+	for _, t := range m.Torrents {
+		if t != nil {
+			out.Torrents = append(out.Torrents, TorrentToProto(t))
+		}
+	}
+	return out
+}
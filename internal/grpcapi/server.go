@@ -0,0 +1,106 @@
+// Package grpcapi serves tracker searches over gRPC, see
+// proto/moviestracker/v1/search.proto.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	pb "github.com/lieranderl/moviestracker-package/pkg/pb/moviestracker/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const DefaultSearchTimeout = 2 * time.Minute
+
+// Batch is one tracker page of a search.
+type Batch struct {
+	Tracker  string
+	Torrents []*torrents.Torrent
+}
+
+// SearchFunc starts a search whose pages are yielded as each tracker answers,
+// without magnet hashes an earlier page had (see executor.SearchStream). The
+// stream must end when ctx is done. An error from SearchFunc itself means the
+// request is invalid.
+type SearchFunc func(ctx context.Context, query, year string, movie bool) (iter.Seq2[Batch, error], error)
+
+type Server struct {
+	pb.UnimplementedSearchServiceServer
+
+	search        SearchFunc
+	searchTimeout time.Duration
+}
+
+// New returns the SearchService implementation; searchTimeout <= 0 uses
+// DefaultSearchTimeout.
+func New(search SearchFunc, searchTimeout time.Duration) *Server {
+	if searchTimeout <= 0 {
+		searchTimeout = DefaultSearchTimeout
+	}
+	return &Server{search: search, searchTimeout: searchTimeout}
+}
+
+// Register adds the service to s.
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	pb.RegisterSearchServiceServer(r, s)
+}
+
+// Search sends every page of the search as one response.
+func (s *Server) Search(req *pb.SearchRequest, stream grpc.ServerStreamingServer[pb.SearchResponse]) error {
+	query := strings.TrimSpace(req.GetQuery())
+	if query == "" {
+		return status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	ctx, cancel := context.WithTimeout(stream.Context(), s.searchTimeout)
+	defer cancel()
+
+	batches, err := s.search(ctx, query, strings.TrimSpace(req.GetYear()), req.GetType() != pb.MediaType_MEDIA_TYPE_SERIES)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sent := 0
+	for batch, err := range batches {
+		if err != nil {
+			return searchError(ctx, query, err)
+		}
+		resp := &pb.SearchResponse{Tracker: batch.Tracker, Torrents: make([]*pb.Torrent, 0, len(batch.Torrents))}
+		for _, t := range batch.Torrents {
+			if t != nil {
+				resp.Torrents = append(resp.Torrents, TorrentToProto(t))
+			}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		sent += len(resp.Torrents)
+	}
+	if err := ctx.Err(); err != nil {
+		return searchError(ctx, query, err)
+	}
+
+	slog.InfoContext(ctx, "grpc search completed", "query", query, "torrents", sent)
+	return nil
+}
+
+// searchError maps a failed search to DEADLINE_EXCEEDED, CANCELED or, for
+// tracker failures, UNAVAILABLE.
+func searchError(ctx context.Context, query string, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "search timed out")
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	default:
+		slog.ErrorContext(ctx, "grpc search failed", "query", query, "error", err)
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
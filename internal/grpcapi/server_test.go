@@ -0,0 +1,178 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	pb "github.com/lieranderl/moviestracker-package/pkg/pb/moviestracker/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newClient(t *testing.T, srv *Server) pb.SearchServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	srv.Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewSearchServiceClient(conn)
+}
+
+// batches yields pages the way executor.SearchStream does.
+func batches(pages ...Batch) iter.Seq2[Batch, error] {
+	return func(yield func(Batch, error) bool) {
+		for _, page := range pages {
+			if !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
+
+func receiveAll(t *testing.T, stream grpc.ServerStreamingClient[pb.SearchResponse]) ([]*pb.SearchResponse, error) {
+	t.Helper()
+	var got []*pb.SearchResponse
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return got, nil
+		}
+		if err != nil {
+			return got, err
+		}
+		got = append(got, resp)
+	}
+}
+
+func TestSearchStreamsEachPage(t *testing.T) {
+	var gotMovie bool
+	srv := New(func(_ context.Context, query, year string, movie bool) (iter.Seq2[Batch, error], error) {
+		require.Equal(t, "Dune", query)
+		require.Equal(t, "2024", year)
+		gotMovie = movie
+		return batches(
+			Batch{Tracker: "rutor", Torrents: []*torrents.Torrent{
+				{Name: "Dune 2160p", MagnetHash: "aaa", K4: true, Seeds: 10},
+				{Name: "Dune 1080p", MagnetHash: "bbb", Seeds: 5},
+			}},
+			Batch{Tracker: "kinozal", Torrents: []*torrents.Torrent{{Name: "Dune DV", MagnetHash: "ccc", DV: true}}},
+		), nil
+	}, time.Minute)
+	client := newClient(t, srv)
+
+	stream, err := client.Search(context.Background(), &pb.SearchRequest{Query: " Dune ", Year: "2024"})
+	require.NoError(t, err)
+	got, err := receiveAll(t, stream)
+	require.NoError(t, err)
+	require.True(t, gotMovie)
+
+	require.Len(t, got, 2)
+	require.Equal(t, "rutor", got[0].GetTracker())
+	require.Len(t, got[0].GetTorrents(), 2)
+	require.True(t, got[0].GetTorrents()[0].GetK4())
+	require.Equal(t, int32(10), got[0].GetTorrents()[0].GetSeeds())
+	require.Equal(t, "kinozal", got[1].GetTracker())
+	require.True(t, got[1].GetTorrents()[0].GetDv())
+}
+
+func TestSearchSeries(t *testing.T) {
+	var gotMovie bool
+	srv := New(func(_ context.Context, _, _ string, movie bool) (iter.Seq2[Batch, error], error) {
+		gotMovie = movie
+		return batches(), nil
+	}, time.Minute)
+	client := newClient(t, srv)
+
+	stream, err := client.Search(context.Background(), &pb.SearchRequest{Query: "The Bear", Type: pb.MediaType_MEDIA_TYPE_SERIES})
+	require.NoError(t, err)
+	got, err := receiveAll(t, stream)
+	require.NoError(t, err)
+	require.Empty(t, got)
+	require.False(t, gotMovie)
+}
+
+func TestSearchErrors(t *testing.T) {
+	srv := New(func(_ context.Context, query, _ string, _ bool) (iter.Seq2[Batch, error], error) {
+		if query == "bad" {
+			return nil, errors.New("tracker url must include scheme and host")
+		}
+		return func(yield func(Batch, error) bool) {
+			if yield(Batch{Tracker: "rutor", Torrents: []*torrents.Torrent{{Name: "Dune"}}}, nil) {
+				yield(Batch{Tracker: "kinozal"}, errors.New("kinozal: blocked"))
+			}
+		}, nil
+	}, time.Minute)
+	client := newClient(t, srv)
+
+	for _, query := range []string{"", "bad"} {
+		stream, err := client.Search(context.Background(), &pb.SearchRequest{Query: query})
+		require.NoError(t, err)
+		_, err = receiveAll(t, stream)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), query)
+	}
+
+	stream, err := client.Search(context.Background(), &pb.SearchRequest{Query: "Dune"})
+	require.NoError(t, err)
+	got, err := receiveAll(t, stream)
+	require.Len(t, got, 1, "pages sent before the failure reach the client")
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, "kinozal: blocked", status.Convert(err).Message())
+}
+
+func TestSearchTimeout(t *testing.T) {
+	srv := New(func(ctx context.Context, _, _ string, _ bool) (iter.Seq2[Batch, error], error) {
+		return func(yield func(Batch, error) bool) {
+			<-ctx.Done()
+			yield(Batch{}, ctx.Err())
+		}, nil
+	}, 50*time.Millisecond)
+	client := newClient(t, srv)
+
+	stream, err := client.Search(context.Background(), &pb.SearchRequest{Query: "Dune"})
+	require.NoError(t, err)
+	_, err = receiveAll(t, stream)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestMovieToProto(t *testing.T) {
+	released := time.Date(2024, 2, 27, 0, 0, 0, 0, time.UTC)
+	m := &movies.Short{
+		ID:          "693134",
+		Title:       "Дюна: Часть вторая",
+		Year:        2024,
+		ReleaseDate: released,
+		VoteCount:   1200,
+		Genres:      map[string][]string{"ru": {"фантастика"}},
+		Ratings:     map[string]movies.Rating{"imdb": {Value: 8.5, Votes: 600000}},
+		Torrents:    []*torrents.Torrent{{Name: "Dune", MagnetHash: "aaa"}, nil},
+	}
+
+	got := MovieToProto(m)
+	require.Equal(t, "693134", got.GetId())
+	require.Equal(t, int32(2024), got.GetYear())
+	require.Equal(t, released, got.GetReleaseDate().AsTime())
+	require.Nil(t, got.GetLastTimeFound())
+	require.Equal(t, []string{"фантастика"}, got.GetGenres()["ru"].GetNames())
+	require.Equal(t, int32(600000), got.GetRatings()["imdb"].GetVotes())
+	require.Len(t, got.GetTorrents(), 1)
+	require.Equal(t, "aaa", got.GetTorrents()[0].GetMagnetHash())
+}
@@ -10,8 +10,15 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/lieranderl/moviestracker-package/internal/kinozal")
+
 type Cred struct {
 	Login    string
 	Password string
@@ -45,16 +52,27 @@ func Login(httpClient *http.Client, cred *Cred) (bool, *http.Client) {
 }
 
 func GetMagnet(httpClient *http.Client, id string, mc chan map[string]string) {
-	magnet, err := getMagnetForID(httpClient, id)
+	magnet, err := getMagnetForID(context.Background(), httpClient, id)
 	if err != nil {
 		slog.Warn("failed to resolve magnet", "details_id", id, "error", err)
 	}
 	mc <- map[string]string{id: magnet}
 }
 
-func getMagnetForID(httpClient *http.Client, id string) (string, error) {
-	var magnet string
-	bb, err := get(httpClient, "http://kinozal.tv/get_srv_details.php?id="+id+"&action=2")
+// getMagnetForID looks up the magnet of the details page id in a
+// "kinozal.magnet" span.
+func getMagnetForID(ctx context.Context, httpClient *http.Client, id string) (magnet string, err error) {
+	ctx, span := tracer.Start(ctx, "kinozal.magnet", trace.WithAttributes(attribute.String("kinozal.details_id", id)))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.SetAttributes(attribute.Bool("kinozal.magnet_found", magnet != ""))
+		span.End()
+	}()
+
+	bb, err := get(ctx, httpClient, "http://kinozal.tv/get_srv_details.php?id="+id+"&action=2")
 	time.Sleep(300 * time.Millisecond)
 	if err != nil {
 		return "", err
@@ -71,7 +89,7 @@ func getMagnetForID(httpClient *http.Client, id string) (string, error) {
 	return magnet, nil
 }
 
-func get(httpClient *http.Client, url1 string) ([]byte, error) {
+func get(ctx context.Context, httpClient *http.Client, url1 string) ([]byte, error) {
 	headers := http.Header{}
 	headers.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15")
 	headers.Set("Accept-Encoding", "gzip")
@@ -83,7 +101,7 @@ func get(httpClient *http.Client, url1 string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
 	defer cancel()
 	req = req.WithContext(ctx)
 	req.Header = headers
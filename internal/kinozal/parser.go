@@ -1,6 +1,7 @@
 package kinozal
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"log/slog"
@@ -51,17 +52,20 @@ func parseDetailsID(href string) string {
 	return strings.TrimSpace(parts[len(parts)-1])
 }
 
-func ParseMoviePage(url string) ([]*torrents.Torrent, error) {
-	return parsePage(url, false)
+func ParseMoviePage(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+	return parsePage(ctx, url, false)
 }
 
-func ParseSeriesPage(url string) ([]*torrents.Torrent, error) {
-	return parsePage(url, true)
+func ParseSeriesPage(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+	return parsePage(ctx, url, true)
 }
 
-func parsePage(url string, isSeries bool) ([]*torrents.Torrent, error) {
-	loggedIn, httpClient := kzLogin()
+func parsePage(ctx context.Context, url string, isSeries bool) ([]*torrents.Torrent, error) {
 	titles := make([]*torrents.Torrent, 0)
+	if err := ctx.Err(); err != nil {
+		return titles, err
+	}
+	loggedIn, httpClient := kzLogin()
 
 	c := colly.NewCollector()
 	c.SetRequestTimeout(defaultRequestTimeout)
@@ -94,7 +98,7 @@ func parsePage(url string, isSeries bool) ([]*torrents.Torrent, error) {
 	}
 
 	if loggedIn && len(titles) > 0 {
-		titles = fetchMagnetLinks(titles, httpClient)
+		titles = fetchMagnetLinks(ctx, titles, httpClient)
 	}
 
 	return titles, nil
@@ -170,6 +174,7 @@ func (m *kzTorrent) parseTitleMetadata() {
 func (m *kzTorrent) parseHtmlTor(tds *goquery.Selection) {
 	m.parseAttributes(m.Name)
 	m.parseTitleMetadata()
+	m.ImdbID = torrents.ExtractIMDbID(m.Name)
 
 	if s, err := strconv.ParseFloat(strings.Split(tds.Eq(3).Text(), " ГБ")[0], 32); err == nil {
 		m.Size = float32(s)
@@ -181,10 +186,7 @@ func (m *kzTorrent) parseHtmlTor(tds *goquery.Selection) {
 		m.Leeches = int32(s)
 	}
 
-	const (
-		layout       = "2006-01-02T00:00:00.000Z"
-		layoutParsed = "02.01.2006"
-	)
+	const layoutParsed = "02.01.2006"
 
 	timeNow := time.Now()
 	yesterday := time.Now().Add(-24 * time.Hour)
@@ -193,23 +195,30 @@ func (m *kzTorrent) parseHtmlTor(tds *goquery.Selection) {
 		d := strings.Fields(tds.Eq(6).Text())[0]
 		switch {
 		case strings.Contains(d, "сегодня"):
-			m.Date = monday.Format(timeNow, layout, monday.LocaleRuRU)
+			m.Date = startOfDayUTC(timeNow)
 		case strings.Contains(d, "вчера"):
-			m.Date = monday.Format(yesterday, layout, monday.LocaleRuRU)
+			m.Date = startOfDayUTC(yesterday)
 		default:
 			parsedMtime, err := monday.Parse(layoutParsed, d, monday.LocaleRuRU)
 			if err == nil {
-				m.Date = monday.Format(parsedMtime, layout, monday.LocaleRuRU)
+				m.Date = startOfDayUTC(parsedMtime)
 			}
 		}
 	}
 
-	if m.Date == "" {
-		m.Date = monday.Format(timeNow, layout, monday.LocaleRuRU)
+	if m.Date.IsZero() {
+		m.Date = startOfDayUTC(timeNow)
 	}
 }
 
-func fetchMagnetLinks(titles []*torrents.Torrent, httpClient *http.Client) []*torrents.Torrent {
+// startOfDayUTC keeps the calendar day of t and drops the time, because kinozal
+// lists only upload days.
+func startOfDayUTC(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func fetchMagnetLinks(ctx context.Context, titles []*torrents.Torrent, httpClient *http.Client) []*torrents.Torrent {
 	type magnetResult struct {
 		detailsID  string
 		magnetLink string
@@ -231,9 +240,9 @@ func fetchMagnetLinks(titles []*torrents.Torrent, httpClient *http.Client) []*to
 		}
 		requests++
 		go func(detailsID string) {
-			magnetLink, err := getMagnetForID(httpClient, detailsID)
+			magnetLink, err := getMagnetForID(ctx, httpClient, detailsID)
 			if err != nil {
-				slog.Warn("failed to fetch kinozal magnet", "details_id", detailsID, "error", err)
+				slog.WarnContext(ctx, "failed to fetch kinozal magnet", "details_id", detailsID, "error", err)
 			}
 			magnetChannel <- magnetResult{
 				detailsID:  detailsID,
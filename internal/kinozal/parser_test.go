@@ -1,6 +1,7 @@
 package kinozal
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -21,7 +22,7 @@ func TestParser(t *testing.T) {
 	name := "Вышка"
 	year := "2022"
 	test_link := "http://kinozal.tv/browse.php?s=" + name + "%281080p%7C2160p%29&g=3&c=0&v=0&d=" + year + "&w=0&t=0&f=0"
-	tors, err := ParseMoviePage(test_link)
+	tors, err := ParseMoviePage(context.Background(), test_link)
 
 	if err != nil {
 		t.Fatalf("ParseMoviePage failed: %v", err)
@@ -0,0 +1,141 @@
+// Package metrics exports Prometheus metrics for tracker searches, TMDB
+// lookups and storage, and summarizes single pipeline runs.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "moviestracker"
+
+// Metrics holds the collectors of one process; create it once with New and
+// observe every pipeline with a Run.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	trackerRequests *prometheus.CounterVec
+	trackerDuration *prometheus.HistogramVec
+	torrentsParsed  *prometheus.CounterVec
+	torrentsUnique  prometheus.Counter
+	dedupeRatio     prometheus.Gauge
+	tmdbLookups     *prometheus.CounterVec
+	cacheRequests   *prometheus.CounterVec
+	stageDuration   *prometheus.HistogramVec
+	mongoWrite      prometheus.Histogram
+	pipelineRuns    *prometheus.CounterVec
+	pipelineTime    prometheus.Histogram
+	errors          *prometheus.CounterVec
+
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		trackerRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "tracker_requests_total",
+			Help: "Tracker search pages requested, by tracker and result (ok, error).",
+		}, []string{"tracker", "result"}),
+		trackerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "tracker_request_duration_seconds",
+			Help:    "Time to fetch and parse one tracker search page, including kinozal magnet lookups.",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 8),
+		}, []string{"tracker"}),
+		torrentsParsed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "torrents_parsed_total",
+			Help: "Torrents parsed from tracker pages before deduplication.",
+		}, []string{"tracker"}),
+		torrentsUnique: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "torrents_unique_total",
+			Help: "Torrents left after deduplication by magnet hash.",
+		}),
+		dedupeRatio: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "dedupe_ratio",
+			Help: "Unique to parsed torrents of the last search (1 means no duplicates).",
+		}),
+		tmdbLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "tmdb_lookups_total",
+			Help: "Movie metadata lookups, by result (matched, not_found, error).",
+		}, []string{"result"}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "tmdb_cache_requests_total",
+			Help: "Metadata response cache reads, by result (hit, miss, error).",
+		}, []string{"result"}),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "stage_duration_seconds",
+			Help:    "Pipeline stage durations, by stage and result (ok, error).",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"stage", "result"}),
+		mongoWrite: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "mongo_write_duration_seconds",
+			Help:    "Time to write the movies or details of one search to a MongoDB collection, without connecting.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		pipelineRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "pipeline_runs_total",
+			Help: "Finished pipeline runs, by result (ok, error).",
+		}, []string{"result"}),
+		pipelineTime: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "pipeline_duration_seconds",
+			Help:    "Duration of whole pipeline runs.",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "pipeline_errors_total",
+			Help: "Errors that failed a pipeline, by stage.",
+		}, []string{"stage"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.trackerRequests, m.trackerDuration, m.torrentsParsed, m.torrentsUnique, m.dedupeRatio,
+		m.tmdbLookups, m.cacheRequests, m.stageDuration, m.mongoWrite,
+		m.pipelineRuns, m.pipelineTime, m.errors,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// InstrumentCache counts the hits and misses of the metadata response cache.
+func (m *Metrics) InstrumentCache(c cache.Cache) cache.Cache {
+	return &instrumentedCache{Cache: c, m: m}
+}
+
+type instrumentedCache struct {
+	cache.Cache
+	m *Metrics
+}
+
+func (c *instrumentedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, found, err := c.Cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		c.m.cacheRequests.WithLabelValues("error").Inc()
+	case found:
+		c.m.cacheRequests.WithLabelValues("hit").Inc()
+		c.m.cacheHits.Add(1)
+	default:
+		c.m.cacheRequests.WithLabelValues("miss").Inc()
+		c.m.cacheMisses.Add(1)
+	}
+	return value, found, err
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
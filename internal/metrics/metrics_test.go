@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func found(n int) []*torrents.Torrent {
+	return make([]*torrents.Torrent, n)
+}
+
+func TestRunRecordsPipeline(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	run := m.Run()
+
+	run.OnStageStart(ctx, executor.StageTrackers, executor.StageStats{})
+	run.OnTrackerResult(ctx, "rutor", found(3), 200*time.Millisecond, nil)
+	run.OnTrackerResult(ctx, "kinozal", found(1), time.Second, nil)
+	run.OnTrackerResult(ctx, "kinozal", nil, time.Second, errors.New("status 503"))
+	run.OnStageEnd(ctx, executor.StageTrackers, executor.StageStats{Torrents: 2}, time.Second, nil)
+
+	// Of 4 movies, 2 are matched, 1 fails and 1 is not found; the stage
+	// keeps only the matched ones.
+	run.OnStageStart(ctx, executor.StageTmdb, executor.StageStats{Movies: 4})
+	run.OnMovieEnriched(ctx, executor.StageTmdb, &movies.Short{})
+	run.OnMovieEnriched(ctx, executor.StageTmdb, &movies.Short{})
+	run.OnStageEnd(ctx, executor.StageTmdb, executor.StageStats{Movies: 2, Skipped: 1}, time.Second, nil)
+	run.OnStageStart(ctx, executor.StageRatings, executor.StageStats{Movies: 2})
+	run.OnMovieEnriched(ctx, executor.StageRatings, &movies.Short{})
+	run.OnStageEnd(ctx, executor.StageRatings, executor.StageStats{Movies: 2}, time.Second, nil)
+
+	run.OnStageStart(ctx, executor.StageSave, executor.StageStats{Movies: 2})
+	run.OnMongoWrite(ctx, "movies", 2, 50*time.Millisecond, nil)
+	run.OnMongoWrite(ctx, "movies_details", 0, time.Millisecond, nil)
+	run.OnStageEnd(ctx, executor.StageSave, executor.StageStats{Movies: 2}, 2*time.Second, nil)
+	s := run.Done(nil)
+
+	require.Equal(t, 4, s.TorrentsParsed)
+	require.Equal(t, 2, s.TorrentsUnique)
+	require.InDelta(t, 0.5, s.DedupeRatio(), 1e-9)
+	require.Equal(t, 2, s.TMDBMatched)
+	require.Equal(t, 1, s.TMDBNotFound)
+	require.Equal(t, 1, s.TMDBErrors)
+	require.Equal(t, []TrackerSummary{
+		{Name: "kinozal", Requests: 2, Errors: 1, Torrents: 1, Duration: 2 * time.Second},
+		{Name: "rutor", Requests: 1, Torrents: 3, Duration: 200 * time.Millisecond},
+	}, s.Trackers)
+	require.Len(t, s.Stages, 4)
+
+	require.InDelta(t, 1, testutil.ToFloat64(m.trackerRequests.WithLabelValues("kinozal", "error")), 0)
+	require.InDelta(t, 3, testutil.ToFloat64(m.torrentsParsed.WithLabelValues("rutor")), 0)
+	require.InDelta(t, 0.5, testutil.ToFloat64(m.dedupeRatio), 1e-9)
+	require.InDelta(t, 2, testutil.ToFloat64(m.tmdbLookups.WithLabelValues("matched")), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(m.tmdbLookups.WithLabelValues("not_found")), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(m.tmdbLookups.WithLabelValues("error")), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(m.pipelineRuns.WithLabelValues("ok")), 0)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, `moviestracker_tracker_requests_total{result="ok",tracker="rutor"} 1`)
+	require.Contains(t, body, "moviestracker_pipeline_duration_seconds_count 1")
+	require.Contains(t, body, "moviestracker_mongo_write_duration_seconds_sum 0.05", "connecting is not a write")
+	require.Contains(t, body, "moviestracker_mongo_write_duration_seconds_count 1")
+	require.Contains(t, body, "go_goroutines")
+}
+
+func TestRunRecordsFailure(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	run := m.Run()
+
+	err := errors.New("rutor: status 503")
+	run.OnTrackerResult(ctx, "rutor", found(2), time.Second, nil)
+	run.OnError(ctx, executor.StageTrackers, err)
+	run.OnStageEnd(ctx, executor.StageTrackers, executor.StageStats{}, time.Second, err)
+	s := run.Done(err)
+
+	require.True(t, s.Failed)
+	require.Zero(t, s.TorrentsParsed, "a failed search is not deduplicated")
+	require.Equal(t, []StageSummary{{Name: executor.StageTrackers, Duration: time.Second, Failed: true}}, s.Stages)
+	require.InDelta(t, 1, testutil.ToFloat64(m.errors.WithLabelValues(executor.StageTrackers)), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(m.pipelineRuns.WithLabelValues("error")), 0)
+}
+
+func TestInstrumentCache(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	c := m.InstrumentCache(cache.NewMemory(10))
+	run := m.Run()
+
+	_, ok, err := c.Get(ctx, "movie/573435")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, c.Set(ctx, "movie/573435", []byte("{}"), time.Hour))
+	_, ok, err = c.Get(ctx, "movie/573435")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	s := run.Done(nil)
+	require.EqualValues(t, 1, s.CacheHits)
+	require.EqualValues(t, 1, s.CacheMisses)
+	require.InDelta(t, 1, testutil.ToFloat64(m.cacheRequests.WithLabelValues("hit")), 0)
+}
+
+func TestSummaryLogValue(t *testing.T) {
+	var out strings.Builder
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+	logger.Info("run summary", "summary", Summary{
+		TorrentsParsed: 4,
+		TorrentsUnique: 3,
+		Trackers:       []TrackerSummary{{Name: "rutor", Requests: 1, Torrents: 4}},
+		Stages:         []StageSummary{{Name: executor.StageTmdb, Duration: time.Second, Failed: true}},
+	})
+	line := out.String()
+	require.Contains(t, line, "summary.dedupe_ratio=0.75")
+	require.Contains(t, line, "summary.rutor.torrents=4")
+	require.Contains(t, line, `summary.stages.tmdb="1s (failed)"`)
+}
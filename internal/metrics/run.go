@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/executor"
+	"github.com/lieranderl/moviestracker-package/internal/movies"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+// Run observes one pipeline: register it with TrackersPipeline.WithObserver
+// and call Done when the pipeline has finished.
+type Run struct {
+	m           *Metrics
+	started     time.Time
+	cacheHits   int64
+	cacheMisses int64
+
+	mu          sync.Mutex
+	trackers    map[string]*TrackerSummary
+	parsed      int
+	tmdbIn      int
+	tmdbMatched int
+	summary     Summary
+}
+
+// Run starts observing a pipeline run.
+func (m *Metrics) Run() *Run {
+	return &Run{
+		m:           m,
+		started:     time.Now(),
+		cacheHits:   m.cacheHits.Load(),
+		cacheMisses: m.cacheMisses.Load(),
+		trackers:    make(map[string]*TrackerSummary),
+	}
+}
+
+var _ executor.Observer = (*Run)(nil)
+
+func (r *Run) OnStageStart(_ context.Context, stage string, in executor.StageStats) {
+	if stage == executor.StageTmdb {
+		r.mu.Lock()
+		r.tmdbIn, r.tmdbMatched = in.Movies, 0
+		r.mu.Unlock()
+	}
+}
+
+func (r *Run) OnStageEnd(_ context.Context, stage string, out executor.StageStats, elapsed time.Duration, err error) {
+	r.m.stageDuration.WithLabelValues(stage, result(err)).Observe(elapsed.Seconds())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary.Stages = append(r.summary.Stages, StageSummary{Name: stage, Duration: elapsed, Failed: err != nil})
+
+	switch stage {
+	case executor.StageTrackers:
+		parsed := r.parsed
+		r.parsed = 0
+		if err != nil {
+			return
+		}
+		r.m.torrentsUnique.Add(float64(out.Torrents))
+		r.summary.TorrentsParsed += parsed
+		r.summary.TorrentsUnique += out.Torrents
+		if parsed > 0 {
+			r.m.dedupeRatio.Set(float64(out.Torrents) / float64(parsed))
+		}
+	case executor.StageTmdb:
+		// Movies TMDB did not find are dropped by the stage, so they are
+		// the ones neither matched nor skipped with an error.
+		notFound := max(r.tmdbIn-r.tmdbMatched-out.Skipped, 0)
+		r.m.tmdbLookups.WithLabelValues("matched").Add(float64(r.tmdbMatched))
+		r.m.tmdbLookups.WithLabelValues("not_found").Add(float64(notFound))
+		r.m.tmdbLookups.WithLabelValues("error").Add(float64(out.Skipped))
+		r.summary.TMDBMatched += r.tmdbMatched
+		r.summary.TMDBNotFound += notFound
+		r.summary.TMDBErrors += out.Skipped
+	}
+}
+
+func (r *Run) OnTrackerStart(context.Context, string) {}
+
+func (r *Run) OnTrackerResult(_ context.Context, tracker string, found []*torrents.Torrent, elapsed time.Duration, err error) {
+	r.m.trackerRequests.WithLabelValues(tracker, result(err)).Inc()
+	r.m.trackerDuration.WithLabelValues(tracker).Observe(elapsed.Seconds())
+	r.m.torrentsParsed.WithLabelValues(tracker).Add(float64(len(found)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.trackers[tracker]
+	if !ok {
+		t = &TrackerSummary{Name: tracker}
+		r.trackers[tracker] = t
+	}
+	t.Requests++
+	t.Torrents += len(found)
+	t.Duration += elapsed
+	if err != nil {
+		t.Errors++
+	}
+	r.parsed += len(found)
+}
+
+func (r *Run) OnMovieEnriched(_ context.Context, stage string, _ *movies.Short) {
+	if stage == executor.StageTmdb {
+		r.mu.Lock()
+		r.tmdbMatched++
+		r.mu.Unlock()
+	}
+}
+
+func (r *Run) OnMongoWrite(_ context.Context, _ string, documents int, elapsed time.Duration, _ error) {
+	if documents > 0 {
+		r.m.mongoWrite.Observe(elapsed.Seconds())
+	}
+}
+
+func (r *Run) OnError(_ context.Context, stage string, _ error) {
+	r.m.errors.WithLabelValues(stage).Inc()
+}
+
+// Done records the end of the run with the pipeline's error and returns its
+// summary.
+func (r *Run) Done(err error) Summary {
+	elapsed := time.Since(r.started)
+	r.m.pipelineRuns.WithLabelValues(result(err)).Inc()
+	r.m.pipelineTime.Observe(elapsed.Seconds())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.summary
+	s.Duration = elapsed
+	s.Failed = err != nil
+	s.CacheHits = r.m.cacheHits.Load() - r.cacheHits
+	s.CacheMisses = r.m.cacheMisses.Load() - r.cacheMisses
+	s.Stages = append([]StageSummary(nil), s.Stages...)
+	for _, t := range r.trackers {
+		s.Trackers = append(s.Trackers, *t)
+	}
+	sort.Slice(s.Trackers, func(i, j int) bool { return s.Trackers[i].Name < s.Trackers[j].Name })
+	return s
+}
+
+// Summary is what one run did; it logs as a group of attributes.
+type Summary struct {
+	Duration       time.Duration
+	Failed         bool
+	Trackers       []TrackerSummary
+	TorrentsParsed int
+	TorrentsUnique int
+	TMDBMatched    int
+	TMDBNotFound   int
+	TMDBErrors     int
+	// CacheHits and CacheMisses count the metadata cache reads of the process
+	// while the run was going on.
+	CacheHits   int64
+	CacheMisses int64
+	Stages      []StageSummary
+}
+
+type TrackerSummary struct {
+	Name     string
+	Requests int
+	Errors   int
+	Torrents int
+	// Duration adds up the time of every request.
+	Duration time.Duration
+}
+
+type StageSummary struct {
+	Name     string
+	Duration time.Duration
+	Failed   bool
+}
+
+// DedupeRatio is unique to parsed torrents, or 0 without torrents.
+func (s Summary) DedupeRatio() float64 {
+	if s.TorrentsParsed == 0 {
+		return 0
+	}
+	return float64(s.TorrentsUnique) / float64(s.TorrentsParsed)
+}
+
+func (s Summary) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("elapsed", s.Duration.Round(time.Millisecond).String()),
+		slog.Bool("failed", s.Failed),
+		slog.Int("torrents_parsed", s.TorrentsParsed),
+		slog.Int("torrents_unique", s.TorrentsUnique),
+		slog.String("dedupe_ratio", formatRatio(s.DedupeRatio())),
+		slog.Int("tmdb_matched", s.TMDBMatched),
+		slog.Int("tmdb_not_found", s.TMDBNotFound),
+		slog.Int("tmdb_errors", s.TMDBErrors),
+		slog.Int64("cache_hits", s.CacheHits),
+		slog.Int64("cache_misses", s.CacheMisses),
+	}
+	for _, t := range s.Trackers {
+		attrs = append(attrs, slog.Group(t.Name,
+			"requests", t.Requests,
+			"errors", t.Errors,
+			"torrents", t.Torrents,
+			"elapsed", t.Duration.Round(time.Millisecond).String(),
+		))
+	}
+	stages := make([]any, 0, len(s.Stages))
+	for _, st := range s.Stages {
+		value := st.Duration.Round(time.Millisecond).String()
+		if st.Failed {
+			value += " (failed)"
+		}
+		stages = append(stages, slog.String(st.Name, value))
+	}
+	attrs = append(attrs, slog.Group("stages", stages...))
+	return slog.GroupValue(attrs...)
+}
+
+func formatRatio(r float64) string {
+	return strconv.FormatFloat(r, 'f', 2, 64)
+}
@@ -2,17 +2,97 @@ package movies
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// WriteMovieToMongo upserts m by its TMDB id. The torrents saved by earlier
+// searches are kept and m's torrents merged into them (see MergeTorrents), so
+// a search that found fewer releases does not drop the others. m itself is
+// left unchanged.
 func (m *Short) WriteMovieToMongo(ctx context.Context, collection *mongo.Collection) error {
-	_, err := collection.UpdateOne(ctx, bson.M{"id": m.ID}, bson.M{"$set": m}, options.UpdateOne().SetUpsert(true))
+	doc := *m
+	stored, found, err := FindMovie(ctx, collection, m.ID)
+	if err != nil {
+		return err
+	}
+	if found {
+		doc.Torrents = stored.Torrents
+		doc.MergeTorrents(m.Torrents)
+	}
+	_, err = collection.UpdateOne(ctx, bson.M{"id": m.ID}, bson.M{"$set": &doc}, options.UpdateOne().SetUpsert(true))
 	if err != nil {
 		return fmt.Errorf("write mongo movie %s (%s): %w", m.ID, m.Title, err)
 	}
 	return nil
 }
+
+func (f *Full) WriteFullToMongo(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.UpdateOne(ctx, bson.M{"id": f.ID}, bson.M{"$set": f}, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("write mongo movie details %s (%s): %w", f.ID, f.Title, err)
+	}
+	return nil
+}
+
+// ListOptions filters and pages movies read back from Mongo. Query matches
+// titles case-insensitively; zero Year means any year.
+type ListOptions struct {
+	Query  string
+	Year   int
+	Limit  int
+	Offset int
+}
+
+// FindMovies returns saved movies, most recently found first.
+func FindMovies(ctx context.Context, collection *mongo.Collection, opts ListOptions) ([]*Short, error) {
+	filter := bson.M{}
+	if q := strings.TrimSpace(opts.Query); q != "" {
+		pattern := bson.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"title": pattern},
+			bson.M{"original_title": pattern},
+			bson.M{"searchname": pattern},
+		}
+	}
+	if opts.Year > 0 {
+		filter["year"] = opts.Year
+	}
+
+	find := options.Find().SetSort(bson.D{{Key: "lasttimefound", Value: -1}, {Key: "id", Value: 1}})
+	if opts.Limit > 0 {
+		find.SetLimit(int64(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		find.SetSkip(int64(opts.Offset))
+	}
+
+	cursor, err := collection.Find(ctx, filter, find)
+	if err != nil {
+		return nil, fmt.Errorf("find mongo movies: %w", err)
+	}
+	found := make([]*Short, 0)
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("read mongo movies: %w", err)
+	}
+	return found, nil
+}
+
+// FindMovie returns the saved movie with id; found is false when there is none.
+func FindMovie(ctx context.Context, collection *mongo.Collection, id string) (*Short, bool, error) {
+	var m Short
+	err := collection.FindOne(ctx, bson.M{"id": id}).Decode(&m)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("find mongo movie %s: %w", id, err)
+	}
+	return &m, true, nil
+}
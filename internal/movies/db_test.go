@@ -0,0 +1,54 @@
+package movies
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestFindMoviesRoundTrip(t *testing.T) {
+	if os.Getenv("RUN_INTEGRATION_TESTS") != "1" {
+		t.Skip("integration test skipped: set RUN_INTEGRATION_TESTS=1 to enable")
+	}
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("integration test skipped: MONGO_URI is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, client.Disconnect(context.Background())) }()
+	collection := client.Database("movies_test").Collection("find_movies_test")
+	require.NoError(t, collection.Drop(ctx))
+
+	older := &Short{ID: "1", Title: "Плохие парни", OriginalTitle: "Bad Boys", Year: 2024, LastTimeFound: time.Now().Add(-time.Hour)}
+	newer := &Short{ID: "2", Title: "Дюна", OriginalTitle: "Dune: Part Two", Year: 2024, LastTimeFound: time.Now()}
+	require.NoError(t, older.WriteMovieToMongo(ctx, collection))
+	require.NoError(t, newer.WriteMovieToMongo(ctx, collection))
+
+	found, err := FindMovies(ctx, collection, ListOptions{Year: 2024, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, "2", found[0].ID)
+
+	found, err = FindMovies(ctx, collection, ListOptions{Query: "bad boys"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	m, ok, err := FindMovie(ctx, collection, "1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "Bad Boys", m.OriginalTitle)
+
+	_, ok, err = FindMovie(ctx, collection, "missing")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,264 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"github.com/lieranderl/moviestracker-package/pkg/pipeline"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	fullAppendToResponse = "credits,videos,releases,external_ids"
+	maxCastMembers       = 20
+)
+
+// keyCrewJobs limits the stored crew to the credits frontends actually show.
+var keyCrewJobs = map[string]struct{}{
+	"Director":                {},
+	"Screenplay":              {},
+	"Writer":                  {},
+	"Novel":                   {},
+	"Producer":                {},
+	"Director of Photography": {},
+	"Original Music Composer": {},
+}
+
+// Full holds the TMDB movie details that do not fit the search result based
+// Short model. It is stored next to Short and shares its ID.
+type Full struct {
+	ID               string        `json:"id" bson:"id"`
+	ImdbID           string        `json:"imdb_id" bson:"imdb_id,omitempty"`
+	Language         string        `json:"language" bson:"language"`
+	Title            string        `json:"title" bson:"title"`
+	OriginalTitle    string        `json:"original_title" bson:"original_title"`
+	OriginalLanguage string        `json:"original_language" bson:"original_language"`
+	Overview         string        `json:"overview" bson:"overview"`
+	Tagline          string        `json:"tagline" bson:"tagline,omitempty"`
+	Runtime          int           `json:"runtime" bson:"runtime"`
+	Genres           []Genre       `json:"genres" bson:"genres"`
+	Popularity       float64       `json:"popularity" bson:"popularity"`
+	Status           string        `json:"status" bson:"status"`
+	Budget           int64         `json:"budget" bson:"budget"`
+	Revenue          int64         `json:"revenue" bson:"revenue"`
+	Homepage         string        `json:"homepage" bson:"homepage,omitempty"`
+	Cast             []CastMember  `json:"cast" bson:"cast"`
+	Crew             []CrewMember  `json:"crew" bson:"crew"`
+	Videos           []Video       `json:"videos" bson:"videos"`
+	ReleaseDates     []ReleaseDate `json:"release_dates" bson:"release_dates"`
+	ExternalIDs      ExternalIDs   `json:"external_ids" bson:"external_ids"`
+	UpdatedAt        time.Time     `json:"updated_at" bson:"updated_at"`
+}
+
+type Genre struct {
+	ID   int    `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+}
+
+type CastMember struct {
+	ID          int    `json:"id" bson:"id"`
+	Name        string `json:"name" bson:"name"`
+	Character   string `json:"character" bson:"character"`
+	ProfilePath string `json:"profile_path" bson:"profile_path,omitempty"`
+	Order       int    `json:"order" bson:"order"`
+}
+
+type CrewMember struct {
+	ID         int    `json:"id" bson:"id"`
+	Name       string `json:"name" bson:"name"`
+	Job        string `json:"job" bson:"job"`
+	Department string `json:"department" bson:"department"`
+}
+
+type Video struct {
+	Key      string `json:"key" bson:"key"`
+	Name     string `json:"name" bson:"name"`
+	Site     string `json:"site" bson:"site"`
+	Type     string `json:"type" bson:"type"`
+	Language string `json:"language" bson:"language,omitempty"`
+}
+
+type ReleaseDate struct {
+	Country       string    `json:"country" bson:"country"`
+	Certification string    `json:"certification" bson:"certification,omitempty"`
+	ReleaseDate   time.Time `json:"release_date" bson:"release_date"`
+}
+
+type ExternalIDs struct {
+	ImdbID      string `json:"imdb_id" bson:"imdb_id,omitempty"`
+	FacebookID  string `json:"facebook_id" bson:"facebook_id,omitempty"`
+	InstagramID string `json:"instagram_id" bson:"instagram_id,omitempty"`
+	TwitterID   string `json:"twitter_id" bson:"twitter_id,omitempty"`
+}
+
+// fetchFull loads movie details with credits, videos, release dates and
+// external ids in a single request. A missing IMDb id on m is filled from the
+// external ids.
+func (tmdbapi *TMDb) fetchFull(ctx context.Context, m *Short) (*Full, error) {
+	id, err := strconv.Atoi(m.ID)
+	if err != nil {
+		return nil, fmt.Errorf("tmdb details %q: invalid movie id: %w", m.ID, err)
+	}
+
+	options := map[string]string{
+		"language":           tmdbapi.primaryLanguage(),
+		"append_to_response": fullAppendToResponse,
+	}
+	info, err := tmdbapi.tmdb.GetMovieInfo(ctx, id, options)
+	if err != nil {
+		return nil, fmt.Errorf("tmdb details %s (%s): %w", m.ID, m.OriginalTitle, err)
+	}
+
+	full := newFull(info, tmdbapi.primaryLanguage())
+	if m.ImdbID == "" {
+		m.ImdbID = full.ImdbID
+	}
+	return full, nil
+}
+
+func newFull(info *tmdb.Movie, language string) *Full {
+	full := &Full{
+		ID:               fmt.Sprint(info.ID),
+		ImdbID:           info.ImdbID,
+		Language:         language,
+		Title:            info.Title,
+		OriginalTitle:    info.OriginalTitle,
+		OriginalLanguage: info.OriginalLanguage,
+		Overview:         info.Overview,
+		Tagline:          info.Tagline,
+		Runtime:          int(info.Runtime),
+		Genres:           make([]Genre, 0, len(info.Genres)),
+		Popularity:       float64(info.Popularity),
+		Status:           info.Status,
+		Budget:           int64(info.Budget),
+		Revenue:          int64(info.Revenue),
+		Homepage:         info.Homepage,
+		Cast:             make([]CastMember, 0),
+		Crew:             make([]CrewMember, 0),
+		Videos:           make([]Video, 0),
+		ReleaseDates:     make([]ReleaseDate, 0),
+		UpdatedAt:        time.Now().UTC(),
+	}
+
+	for _, g := range info.Genres {
+		full.Genres = append(full.Genres, Genre{ID: g.ID, Name: g.Name})
+	}
+
+	if info.Credits != nil {
+		for _, c := range info.Credits.Cast {
+			if len(full.Cast) == maxCastMembers {
+				break
+			}
+			full.Cast = append(full.Cast, CastMember{ID: c.ID, Name: c.Name, Character: c.Character, ProfilePath: c.ProfilePath, Order: c.Order})
+		}
+		for _, c := range info.Credits.Crew {
+			if _, ok := keyCrewJobs[c.Job]; ok {
+				full.Crew = append(full.Crew, CrewMember{ID: c.ID, Name: c.Name, Job: c.Job, Department: c.Department})
+			}
+		}
+	}
+
+	if info.Videos != nil {
+		for _, v := range info.Videos.Results {
+			full.Videos = append(full.Videos, Video{Key: v.Key, Name: v.Name, Site: v.Site, Type: v.Type, Language: v.Iso639_1})
+		}
+	}
+
+	if info.Releases != nil {
+		for _, r := range info.Releases.Countries {
+			full.ReleaseDates = append(full.ReleaseDates, ReleaseDate{Country: r.Iso3166_1, Certification: r.Certification, ReleaseDate: parseReleaseDate(r.ReleaseDate)})
+		}
+	}
+
+	if info.ExternalIDs != nil {
+		full.ExternalIDs = ExternalIDs{
+			ImdbID:      info.ExternalIDs.ImdbID,
+			FacebookID:  info.ExternalIDs.FacebookID,
+			InstagramID: info.ExternalIDs.InstagramID,
+			TwitterID:   info.ExternalIDs.TwitterID,
+		}
+		if full.ImdbID == "" {
+			full.ImdbID = info.ExternalIDs.ImdbID
+		}
+	}
+
+	return full
+}
+
+// FullPipelineStream fetches Full details for every matched movie with at most
+// limit concurrent requests. Movies without a TMDB id, including Kinopoisk-only
+// matches, are skipped.
+func FullPipelineStream(ctx context.Context, movies []*Short, mytmdb *TMDb, limit int64) (chan *Full, chan error) {
+	matched := make([]*Short, 0, len(movies))
+	for _, m := range movies {
+		if m != nil && m.ID != "" && m.MatchStrategy != MatchByKinopoisk {
+			matched = append(matched, m)
+		}
+	}
+
+	m, err := pipeline.Producer(ctx, matched)
+	if err != nil {
+		fc := make(chan *Full)
+		ec := make(chan error, 1)
+		close(fc)
+		ec <- err
+		close(ec)
+		return fc, ec
+	}
+	fetch := func(ctx context.Context, m *Short) (*Full, error) {
+		ctx, span := tracer.Start(logging.With(ctx, "movie_hash", m.Hash), "tmdb.details", trace.WithAttributes(attribute.String("movie.id", m.ID)))
+		full, err := mytmdb.fetchFull(ctx, m)
+		endSpan(span, err)
+		if err != nil && !IsFatal(err) {
+			slog.WarnContext(ctx, "skipping movie details after tmdb failure", "error", err)
+		}
+		return full, err
+	}
+	return pipeline.StepContext(ctx, m, fetch, limit)
+}
+
+// ChannelToFull collects the fetched details, skipping movies that failed
+// with a non-fatal error the same way ChannelToMovies does.
+func ChannelToFull(ctx context.Context, cancelFunc context.CancelFunc, values <-chan *Full, errors <-chan error) (details []*Full, skipped []error, err error) {
+	details = make([]*Full, 0)
+
+	for values != nil || errors != nil {
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return details, skipped, err
+			}
+			return details, skipped, ctx.Err()
+		case e, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+			if e == nil {
+				continue
+			}
+			if !IsFatal(e) {
+				skipped = append(skipped, e)
+				continue
+			}
+			cancelFunc()
+			if err == nil {
+				err = e
+			}
+		case f, ok := <-values:
+			if ok {
+				details = append(details, f)
+			} else {
+				values = nil
+			}
+		}
+	}
+
+	return details, skipped, err
+}
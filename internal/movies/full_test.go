@@ -0,0 +1,73 @@
+package movies
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/stretchr/testify/require"
+)
+
+const movieDetailsJSON = `{
+  "id": 693134,
+  "imdb_id": "",
+  "title": "Дюна: Часть вторая",
+  "original_title": "Dune: Part Two",
+  "original_language": "en",
+  "overview": "Пол Атрейдес объединяется с Чани.",
+  "runtime": 167,
+  "popularity": 250.5,
+  "status": "Released",
+  "genres": [{"id": 878, "name": "фантастика"}],
+  "credits": {
+    "cast": [{"id": 1190668, "name": "Timothée Chalamet", "character": "Paul Atreides", "order": 0}],
+    "crew": [
+      {"id": 137427, "name": "Denis Villeneuve", "job": "Director", "department": "Directing"},
+      {"id": 1, "name": "Someone", "job": "Grip", "department": "Crew"}
+    ]
+  },
+  "videos": {"results": [{"key": "Way9Dexny3w", "name": "Trailer", "site": "YouTube", "type": "Trailer", "iso_639_1": "en"}]},
+  "releases": {"countries": [{"iso_3166_1": "RU", "certification": "12+", "release_date": "2024-02-29"}]},
+  "external_ids": {"imdb_id": "tt15239678"}
+}`
+
+func TestFetchFullConvertsAppendedResponses(t *testing.T) {
+	var info tmdb.Movie
+	require.NoError(t, json.Unmarshal([]byte(movieDetailsJSON), &info))
+	api := &fakeAPI{info: map[string]tmdb.Movie{"ru": info}}
+	m := &Short{ID: "693134", OriginalTitle: "Dune: Part Two"}
+
+	full, err := newTestTMDb(api).fetchFull(context.Background(), m)
+
+	require.NoError(t, err)
+	require.Equal(t, "693134", full.ID)
+	require.Equal(t, "ru", full.Language)
+	require.Equal(t, 167, full.Runtime)
+	require.Equal(t, []Genre{{ID: 878, Name: "фантастика"}}, full.Genres)
+	require.Len(t, full.Cast, 1)
+	require.Equal(t, []CrewMember{{ID: 137427, Name: "Denis Villeneuve", Job: "Director", Department: "Directing"}}, full.Crew)
+	require.Equal(t, "Way9Dexny3w", full.Videos[0].Key)
+	require.Equal(t, []ReleaseDate{{Country: "RU", Certification: "12+", ReleaseDate: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}}, full.ReleaseDates)
+	require.Equal(t, "tt15239678", full.ImdbID)
+	require.Equal(t, "tt15239678", m.ImdbID)
+}
+
+func TestFetchFullRejectsInvalidID(t *testing.T) {
+	_, err := newTestTMDb(&fakeAPI{}).fetchFull(context.Background(), &Short{ID: "abc"})
+	require.Error(t, err)
+}
+
+func TestFullPipelineStreamSkipsUnmatchedMovies(t *testing.T) {
+	api := &fakeAPI{info: map[string]tmdb.Movie{"ru": {Title: "Фильм"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	values, errs := FullPipelineStream(ctx, []*Short{{ID: "1"}, {ID: ""}, nil, {ID: "2"}}, newTestTMDb(api), 2)
+	got, skipped, err := ChannelToFull(ctx, cancel, values, errs)
+
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+	require.Len(t, got, 2)
+}
@@ -0,0 +1,101 @@
+package movies
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/lieranderl/go-tmdb"
+)
+
+//go:embed data/genres.json
+var fallbackGenresJSON []byte
+
+// GenreCatalog maps TMDB genre ids to names per media type and language.
+type GenreCatalog struct {
+	Movie map[string]map[int]string `json:"movie"`
+	TV    map[string]map[int]string `json:"tv"`
+}
+
+var fallbackGenres = sync.OnceValue(func() *GenreCatalog {
+	catalog := newGenreCatalog()
+	if err := json.Unmarshal(fallbackGenresJSON, catalog); err != nil {
+		slog.Error("embedded genre catalog is invalid", "error", err)
+	}
+	return catalog
+})
+
+// FallbackGenreCatalog returns the ru/en genre lists embedded in the binary,
+// used when TMDB cannot be reached.
+func FallbackGenreCatalog() *GenreCatalog {
+	return fallbackGenres()
+}
+
+func newGenreCatalog() *GenreCatalog {
+	return &GenreCatalog{
+		Movie: make(map[string]map[int]string),
+		TV:    make(map[string]map[int]string),
+	}
+}
+
+// Names resolves ids to genre names in language. Movie genres take precedence
+// over TV genres; unknown ids are skipped.
+func (c *GenreCatalog) Names(ids []int32, language string) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := c.Movie[language][int(id)]; ok {
+			names = append(names, name)
+		} else if name, ok := c.TV[language][int(id)]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Resolve stores the genre names of m for every language the catalog knows.
+func (c *GenreCatalog) Resolve(m *Short, languages []string) {
+	if len(m.GenreIDs) == 0 {
+		return
+	}
+	m.Genres = make(map[string][]string, len(languages))
+	for _, language := range languages {
+		if names := c.Names(m.GenreIDs, language); len(names) > 0 {
+			m.Genres[language] = names
+		}
+	}
+}
+
+// LoadGenres fetches the movie and TV genre lists for every configured
+// language. Lists that cannot be fetched are taken from FallbackGenreCatalog,
+// so the returned catalog is always usable.
+func (tmdbapi *TMDb) LoadGenres(ctx context.Context) *GenreCatalog {
+	catalog := newGenreCatalog()
+	fallback := FallbackGenreCatalog()
+
+	for _, language := range tmdbapi.Languages() {
+		options := map[string]string{"language": language}
+
+		movie, err := tmdbapi.tmdb.GetMovieGenres(ctx, options)
+		catalog.Movie[language] = genreMap(ctx, movie, err, fallback.Movie[language], "movie", language)
+
+		tv, err := tmdbapi.tmdb.GetTvGenres(ctx, options)
+		catalog.TV[language] = genreMap(ctx, tv, err, fallback.TV[language], "tv", language)
+	}
+
+	return catalog
+}
+
+func genreMap(ctx context.Context, list *tmdb.Genre, err error, fallback map[int]string, media, language string) map[int]string {
+	if err != nil || list == nil || len(list.Genres) == 0 {
+		slog.WarnContext(ctx, "tmdb genre list unavailable, using embedded fallback", "media", media, "language", language, "error", err)
+		return fallback
+	}
+
+	genres := make(map[int]string, len(list.Genres))
+	for _, g := range list.Genres {
+		genres[g.ID] = g.Name
+	}
+	return genres
+}
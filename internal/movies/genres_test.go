@@ -0,0 +1,53 @@
+package movies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackGenreCatalogIsEmbedded(t *testing.T) {
+	catalog := FallbackGenreCatalog()
+
+	require.Equal(t, "фантастика", catalog.Movie["ru"][878])
+	require.Equal(t, "Science Fiction", catalog.Movie["en"][878])
+	require.Equal(t, "Sci-Fi & Fantasy", catalog.TV["en"][10765])
+}
+
+func TestGenreCatalogNamesPrefersMovieAndSkipsUnknown(t *testing.T) {
+	catalog := &GenreCatalog{
+		Movie: map[string]map[int]string{"en": {18: "Drama"}},
+		TV:    map[string]map[int]string{"en": {18: "TV Drama", 10765: "Sci-Fi & Fantasy"}},
+	}
+
+	require.Equal(t, []string{"Drama", "Sci-Fi & Fantasy"}, catalog.Names([]int32{18, 10765, 1}, "en"))
+	require.Empty(t, catalog.Names([]int32{18}, "de"))
+}
+
+func TestLoadGenresFallsBackPerList(t *testing.T) {
+	list := &tmdb.Genre{}
+	list.Genres = append(list.Genres, struct {
+		ID   int
+		Name string
+	}{ID: 28, Name: "Action!"})
+	api := &fakeAPI{genres: map[string]*tmdb.Genre{"movie:en": list}}
+
+	catalog := newTestTMDb(api).WithLanguages("ru", "en").LoadGenres(context.Background())
+
+	require.Equal(t, "Action!", catalog.Movie["en"][28])
+	require.Equal(t, "боевик", catalog.Movie["ru"][28])
+	require.Equal(t, "Kids", catalog.TV["en"][10762])
+}
+
+func TestGenreCatalogResolve(t *testing.T) {
+	m := &Short{GenreIDs: []int32{28, 12}}
+
+	FallbackGenreCatalog().Resolve(m, []string{"ru", "en", "uk"})
+
+	require.Equal(t, map[string][]string{
+		"ru": {"боевик", "приключения"},
+		"en": {"Action", "Adventure"},
+	}, m.Genres)
+}
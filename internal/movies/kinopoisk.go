@@ -0,0 +1,261 @@
+package movies
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// KinopoiskBaseURL is the root of the unofficial Kinopoisk API.
+	KinopoiskBaseURL = "https://kinopoiskapiunofficial.tech"
+	// KinopoiskRateLimit stays below the API's 20 requests per second.
+	KinopoiskRateLimit = 10
+
+	MatchByKinopoisk = "kinopoisk"
+
+	// kinopoiskIDPrefix marks Short.ID values of movies only Kinopoisk knows,
+	// so they never collide with TMDB ids.
+	kinopoiskIDPrefix = "kp"
+	kinopoiskLanguage = "ru"
+)
+
+// Kinopoisk is a Provider backed by the unofficial Kinopoisk API. It matches
+// Russian-only releases TMDB does not know and rates movies with their
+// Kinopoisk and IMDb scores.
+type Kinopoisk struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	limits  *requestLimiter
+}
+
+func KinopoiskInit(apiKey string) *Kinopoisk {
+	return &Kinopoisk{
+		baseURL: KinopoiskBaseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: httpAPITimeout},
+		limits:  newRequestLimiter(rate.NewLimiter(KinopoiskRateLimit, defaultRateBurst)),
+	}
+}
+
+// WithBaseURL sends requests to a Kinopoisk compatible server, e.g. a test
+// stand-in. An empty baseURL keeps the public API.
+func (k *Kinopoisk) WithBaseURL(baseURL string) *Kinopoisk {
+	if baseURL != "" {
+		k.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	return k
+}
+
+// kinopoiskFilm is the film object of the v2.2 API. Search results use filmId
+// and a string year, both of which are accepted too.
+type kinopoiskFilm struct {
+	KinopoiskID              int             `json:"kinopoiskId"`
+	FilmID                   int             `json:"filmId"`
+	ImdbID                   string          `json:"imdbId"`
+	NameRu                   string          `json:"nameRu"`
+	NameEn                   string          `json:"nameEn"`
+	NameOriginal             string          `json:"nameOriginal"`
+	PosterURL                string          `json:"posterUrl"`
+	Year                     kinopoiskYear   `json:"year"`
+	Description              string          `json:"description"`
+	RatingKinopoisk          float64         `json:"ratingKinopoisk"`
+	RatingKinopoiskVoteCount int             `json:"ratingKinopoiskVoteCount"`
+	RatingImdb               float64         `json:"ratingImdb"`
+	RatingImdbVoteCount      int             `json:"ratingImdbVoteCount"`
+	Genres                   []kinopoiskName `json:"genres"`
+
+	// detailed is set for films loaded from /films/{id}; list items lack the
+	// description and sometimes the ratings.
+	detailed bool
+}
+
+type kinopoiskName struct {
+	Genre string `json:"genre"`
+}
+
+// kinopoiskYear accepts 2024, "2024" and series ranges like "2019-2023".
+type kinopoiskYear int
+
+func (y *kinopoiskYear) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if len(raw) > 4 {
+		raw = raw[:4]
+	}
+	year, err := strconv.Atoi(raw)
+	if err != nil {
+		*y = 0
+		return nil
+	}
+	*y = kinopoiskYear(year)
+	return nil
+}
+
+func (f *kinopoiskFilm) id() int {
+	return cmp.Or(f.KinopoiskID, f.FilmID)
+}
+
+func (k *Kinopoisk) Name() string {
+	return "kinopoisk"
+}
+
+// Match looks m up by Kinopoisk id, then IMDb id, then by title and year. Title
+// matches are scored like TMDB search results and rejected below
+// MinMatchConfidence.
+func (k *Kinopoisk) Match(ctx context.Context, m *Short) (bool, error) {
+	film, confidence, err := k.lookup(ctx, m, MinMatchConfidence)
+	if err != nil || film == nil {
+		return false, err
+	}
+	if !film.detailed {
+		if film, err = k.film(ctx, film.id()); err != nil {
+			return false, err
+		}
+	}
+
+	m.KinopoiskID = strconv.Itoa(film.id())
+	m.ID = kinopoiskIDPrefix + m.KinopoiskID
+	m.OriginalTitle = cmp.Or(film.NameOriginal, film.NameEn, film.NameRu)
+	m.Title = cmp.Or(film.NameRu, m.OriginalTitle)
+	m.PosterPath = film.PosterURL
+	if m.ImdbID == "" {
+		m.ImdbID = film.ImdbID
+	}
+	if m.Year == 0 {
+		m.Year = int(film.Year)
+	}
+	m.MatchStrategy = MatchByKinopoisk
+	m.MatchConfidence = confidence
+	m.LowConfidence = confidence < LowMatchConfidence
+	m.Titles = map[string]string{kinopoiskLanguage: m.Title}
+	m.Overviews = map[string]string{kinopoiskLanguage: film.Description}
+	m.Posters = map[string]string{kinopoiskLanguage: film.PosterURL}
+	if len(film.Genres) > 0 {
+		genres := make([]string, 0, len(film.Genres))
+		for _, g := range film.Genres {
+			genres = append(genres, g.Genre)
+		}
+		m.Genres = map[string][]string{kinopoiskLanguage: genres}
+	}
+	k.applyRatings(m, film)
+	return true, nil
+}
+
+// Rate adds the Kinopoisk and IMDb ratings of a movie TMDB matched. Title
+// lookups must reach LowMatchConfidence so a wrong film is not rated.
+func (k *Kinopoisk) Rate(ctx context.Context, m *Short) error {
+	film, _, err := k.lookup(ctx, m, LowMatchConfidence)
+	if err != nil || film == nil {
+		return err
+	}
+	if film.RatingKinopoisk == 0 && !film.detailed {
+		if film, err = k.film(ctx, film.id()); err != nil {
+			return err
+		}
+	}
+	m.KinopoiskID = strconv.Itoa(film.id())
+	k.applyRatings(m, film)
+	return nil
+}
+
+func (k *Kinopoisk) applyRatings(m *Short, film *kinopoiskFilm) {
+	m.setRating(RatingKinopoisk, film.RatingKinopoisk, film.RatingKinopoiskVoteCount)
+	m.setRating(RatingIMDb, film.RatingImdb, film.RatingImdbVoteCount)
+}
+
+func (k *Kinopoisk) lookup(ctx context.Context, m *Short, minConfidence float64) (*kinopoiskFilm, float64, error) {
+	if m.KinopoiskID != "" {
+		id, err := strconv.Atoi(m.KinopoiskID)
+		if err == nil {
+			film, err := k.film(ctx, id)
+			return film, 1, err
+		}
+	}
+
+	if m.ImdbID != "" {
+		var r struct {
+			Items []kinopoiskFilm `json:"items"`
+		}
+		if err := k.get(ctx, "/api/v2.2/films", url.Values{"imdbId": {m.ImdbID}}, &r); err != nil {
+			return nil, 0, fmt.Errorf("kinopoisk imdb %s: %w", m.ImdbID, err)
+		}
+		if len(r.Items) > 0 {
+			return &r.Items[0], 1, nil
+		}
+	}
+
+	names := []string{m.Searchname, m.AltSearchname}
+	var best *kinopoiskFilm
+	bestScore := 0.0
+	for _, keyword := range names {
+		if keyword == "" || (best != nil && bestScore >= LowMatchConfidence) {
+			continue
+		}
+		var r struct {
+			Films []kinopoiskFilm `json:"films"`
+		}
+		if err := k.get(ctx, "/api/v2.1/films/search-by-keyword", url.Values{"keyword": {keyword}}, &r); err != nil {
+			return nil, 0, fmt.Errorf("kinopoisk search %q: %w", keyword, err)
+		}
+		for i := range r.Films {
+			if score := scoreKinopoiskFilm(names, m.Year, &r.Films[i]); score > bestScore {
+				best, bestScore = &r.Films[i], score
+			}
+		}
+	}
+
+	if best == nil || bestScore < minConfidence {
+		return nil, 0, nil
+	}
+	if bestScore < LowMatchConfidence {
+		slog.WarnContext(ctx, "low confidence kinopoisk match", "searchname", m.Searchname, "year", m.Year, "title", best.NameRu, "confidence", bestScore)
+	}
+	return best, bestScore, nil
+}
+
+// scoreKinopoiskFilm weighs title and year like scoreCandidate. Kinopoisk has
+// no popularity, so an exact match scores titleWeight + yearWeight.
+func scoreKinopoiskFilm(names []string, year int, f *kinopoiskFilm) float64 {
+	title := 0.0
+	for _, name := range names {
+		title = max(title,
+			titleSimilarity(name, f.NameRu),
+			titleSimilarity(name, f.NameEn),
+			titleSimilarity(name, f.NameOriginal))
+	}
+	years := releaseYearScore(year, int(f.Year))
+	if years == 0 {
+		return 0
+	}
+	return titleWeight*title + yearWeight*years
+}
+
+func (k *Kinopoisk) film(ctx context.Context, id int) (*kinopoiskFilm, error) {
+	var film kinopoiskFilm
+	if err := k.get(ctx, "/api/v2.2/films/"+strconv.Itoa(id), url.Values{}, &film); err != nil {
+		return nil, fmt.Errorf("kinopoisk film %d: %w", id, err)
+	}
+	film.detailed = true
+	return &film, nil
+}
+
+func (k *Kinopoisk) get(ctx context.Context, path string, query url.Values, out any) error {
+	_, err := retry(ctx, k.limits, func() (struct{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.baseURL+path+"?"+query.Encode(), nil)
+		if err != nil {
+			return struct{}{}, err
+		}
+		req.Header.Set("X-API-KEY", k.apiKey)
+		req.Header.Set("Accept", "application/json")
+		return struct{}{}, getJSON(k.client, req, "kinopoisk", path, out)
+	})
+	return err
+}
@@ -0,0 +1,177 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	kinopoiskSearch = `{"keyword":"Холоп 2","pagesCount":1,"films":[
+		{"filmId":5304403,"nameRu":"Холоп 2","type":"FILM","year":"2024","rating":"6.8","ratingVoteCount":48000},
+		{"filmId":1122114,"nameRu":"Холоп","type":"FILM","year":"2019","rating":"7.0","ratingVoteCount":420000}]}`
+	kinopoiskFilmJSON = `{"kinopoiskId":5304403,"imdbId":"tt27466426","nameRu":"Холоп 2","nameOriginal":null,
+		"posterUrl":"https://kinopoiskapiunofficial.tech/images/posters/kp/5304403.jpg","year":2024,
+		"description":"Гриша снова отправляется в прошлое.","ratingKinopoisk":6.8,"ratingKinopoiskVoteCount":48000,
+		"ratingImdb":5.9,"ratingImdbVoteCount":1200,"genres":[{"genre":"комедия"}]}`
+	kinopoiskByIMDb = `{"total":1,"totalPages":1,"items":[{"kinopoiskId":1009536,"imdbId":"tt4919268","nameRu":"Плохие парни до конца",
+		"year":2024,"ratingKinopoisk":7.5,"ratingImdb":6.6}]}`
+)
+
+// newKinopoiskStandIn serves the unofficial API endpoints Kinopoisk uses and
+// rejects requests without the test key.
+func newKinopoiskStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "kp-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"You don't have permissions. See https://kinopoiskapiunofficial.tech"}`)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/v2.1/films/search-by-keyword" && r.URL.Query().Get("keyword") == "Холоп 2":
+			fmt.Fprint(w, kinopoiskSearch)
+		case r.URL.Path == "/api/v2.1/films/search-by-keyword":
+			fmt.Fprint(w, `{"films":[]}`)
+		case r.URL.Path == "/api/v2.2/films" && r.URL.Query().Get("imdbId") == "tt4919268":
+			fmt.Fprint(w, kinopoiskByIMDb)
+		case r.URL.Path == "/api/v2.2/films":
+			fmt.Fprint(w, `{"total":0,"items":[]}`)
+		case r.URL.Path == "/api/v2.2/films/5304403":
+			fmt.Fprint(w, kinopoiskFilmJSON)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Film not found"}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestKinopoiskMatchByKeyword(t *testing.T) {
+	kp := KinopoiskInit("kp-key").WithBaseURL(newKinopoiskStandIn(t).URL)
+	m := &Short{Searchname: "Холоп 2", Year: 2024}
+
+	matched, err := kp.Match(context.Background(), m)
+
+	require.NoError(t, err)
+	require.True(t, matched)
+	require.Equal(t, "kp5304403", m.ID)
+	require.Equal(t, "5304403", m.KinopoiskID)
+	require.Equal(t, "tt27466426", m.ImdbID)
+	require.Equal(t, "Холоп 2", m.OriginalTitle)
+	require.Equal(t, MatchByKinopoisk, m.MatchStrategy)
+	require.False(t, m.LowConfidence)
+	require.Equal(t, map[string][]string{"ru": {"комедия"}}, m.Genres)
+	require.Equal(t, map[string]Rating{
+		RatingKinopoisk: {Value: 6.8, Votes: 48000},
+		RatingIMDb:      {Value: 5.9, Votes: 1200},
+	}, m.Ratings)
+}
+
+func TestKinopoiskRateByIMDbID(t *testing.T) {
+	kp := KinopoiskInit("kp-key").WithBaseURL(newKinopoiskStandIn(t).URL)
+	m := &Short{ImdbID: "tt4919268"}
+
+	require.NoError(t, kp.Rate(context.Background(), m))
+	require.Equal(t, "1009536", m.KinopoiskID)
+	require.Equal(t, 7.5, m.Ratings[RatingKinopoisk].Value)
+	require.Equal(t, 6.6, m.Ratings[RatingIMDb].Value)
+}
+
+func TestKinopoiskRejectsInvalidKey(t *testing.T) {
+	kp := KinopoiskInit("wrong").WithBaseURL(newKinopoiskStandIn(t).URL)
+
+	_, err := kp.Match(context.Background(), &Short{Searchname: "Холоп 2"})
+
+	require.True(t, IsFatal(err))
+	require.ErrorContains(t, err, "You don't have permissions")
+}
+
+func TestChainFallsBackToKinopoisk(t *testing.T) {
+	api := &fakeAPI{}
+	chain := NewChain(newTestTMDb(api), KinopoiskInit("kp-key").WithBaseURL(newKinopoiskStandIn(t).URL))
+	m := &Short{Searchname: "Холоп 2", Year: 2024}
+
+	matched, err := chain.Match(context.Background(), m)
+
+	require.NoError(t, err)
+	require.True(t, matched)
+	require.Equal(t, MatchByKinopoisk, m.MatchStrategy)
+	require.Equal(t, 2, api.searched)
+}
+
+func TestChainTracesEachProvider(t *testing.T) {
+	// The package tracer only picks up the first global tracer provider, so
+	// this is the one test of the package that records spans.
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	api := &fakeAPI{}
+	chain := NewChain(newTestTMDb(api), KinopoiskInit("kp-key").WithBaseURL(newKinopoiskStandIn(t).URL))
+	movieChan, errorChan := MoviesPipelineStream(context.Background(), []*Short{{Hash: "holop", Searchname: "Холоп 2", Year: 2024}}, chain, 1)
+	_, skipped, err := ChannelToMovies(context.Background(), func() {}, movieChan, errorChan)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+
+	parents := make(map[string]string)
+	names := make(map[trace.SpanID]string)
+	for _, s := range recorder.Ended() {
+		names[s.SpanContext().SpanID()] = s.Name()
+	}
+	for _, s := range recorder.Ended() {
+		parents[s.Name()] = names[s.Parent().SpanID()]
+	}
+	require.Equal(t, "tmdb+kinopoisk.match", parents["tmdb.match"])
+	require.Equal(t, "tmdb+kinopoisk.match", parents["kinopoisk.match"])
+	require.Equal(t, "kinopoisk.match", parents["kinopoisk.request"])
+}
+
+func TestChainMergesRatings(t *testing.T) {
+	api := &fakeAPI{
+		find: []tmdb.MovieShort{{ID: 573435, OriginalTitle: "Bad Boys: Ride or Die", VoteAverage: 7.6, VoteCount: 2841}},
+	}
+	chain := NewChain(newTestTMDb(api), KinopoiskInit("kp-key").WithBaseURL(newKinopoiskStandIn(t).URL))
+	m := &Short{Searchname: "Bad Boys: Ride or Die", Year: 2024, ImdbID: "tt4919268"}
+
+	matched, err := chain.Match(context.Background(), m)
+
+	require.NoError(t, err)
+	require.True(t, matched)
+	require.Equal(t, "573435", m.ID)
+	require.Equal(t, MatchByIMDbID, m.MatchStrategy)
+	require.Equal(t, map[string]Rating{
+		RatingTMDB:      {Value: 7.6, Votes: 2841},
+		RatingKinopoisk: {Value: 7.5},
+		RatingIMDb:      {Value: 6.6},
+	}, m.Ratings)
+}
+
+func TestChainDisablesFallbackAfterFatalError(t *testing.T) {
+	api := &fakeAPI{}
+	kp := KinopoiskInit("wrong").WithBaseURL(newKinopoiskStandIn(t).URL)
+	chain := NewChain(newTestTMDb(api), kp)
+
+	matched, err := chain.Match(context.Background(), &Short{Searchname: "Холоп 2", Year: 2024})
+	require.NoError(t, err)
+	require.False(t, matched)
+	require.True(t, chain.disabled[1].Load())
+}
+
+func TestChainStopsOnFatalPrimaryError(t *testing.T) {
+	api := &fakeAPI{findErr: &APIError{StatusCode: http.StatusUnauthorized}}
+	chain := NewChain(newTestTMDb(api), KinopoiskInit("kp-key").WithBaseURL(newKinopoiskStandIn(t).URL))
+
+	_, err := chain.Match(context.Background(), &Short{ImdbID: "tt4919268"})
+
+	require.True(t, IsFatal(err))
+}
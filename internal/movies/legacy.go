@@ -0,0 +1,121 @@
+package movies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// legacyTorrentDateLayout is the string layout Torrent.Date used before it
+// became a time.Time.
+const legacyTorrentDateLayout = "2006-01-02T15:04:05.000Z"
+
+// Legacy encodes a movie in the JSON shape used before vote average, vote
+// count, year and dates were typed: "7.1", "1234", "2024", "2024-06-05" and
+// "2024-06-05T00:00:00.000Z". It exists for consumers that still parse strings
+// and is served by the API for ?format=legacy.
+type Legacy struct {
+	*Short
+}
+
+func (l Legacy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newLegacyShort(l.Short))
+}
+
+// LegacyMovies wraps every non-nil movie in Legacy.
+func LegacyMovies(movies []*Short) []Legacy {
+	legacy := make([]Legacy, 0, len(movies))
+	for _, m := range movies {
+		if m != nil {
+			legacy = append(legacy, Legacy{Short: m})
+		}
+	}
+	return legacy
+}
+
+type shortFields Short
+
+// legacyShort shadows the typed fields of Short with their old string forms;
+// encoding/json prefers the shallower fields over the embedded ones.
+type legacyShort struct {
+	*shortFields
+	ReleaseDate string          `json:"release_date"`
+	VoteAverage string          `json:"vote_average"`
+	VoteCount   string          `json:"vote_count"`
+	Year        string          `json:"Year"`
+	Torrents    []legacyTorrent `json:"torrents"`
+}
+
+type torrentFields torrents.Torrent
+
+type legacyTorrent struct {
+	*torrentFields
+	Date string
+}
+
+func newLegacyShort(m *Short) legacyShort {
+	legacy := legacyShort{
+		shortFields: (*shortFields)(m),
+		ReleaseDate: formatLegacyTime(m.ReleaseDate, time.DateOnly),
+		VoteAverage: fmt.Sprintf("%.1f", m.VoteAverage),
+		VoteCount:   strconv.Itoa(m.VoteCount),
+	}
+	if m.Year > 0 {
+		legacy.Year = strconv.Itoa(m.Year)
+	}
+	if m.Torrents != nil {
+		legacy.Torrents = make([]legacyTorrent, 0, len(m.Torrents))
+		for _, t := range m.Torrents {
+			if t != nil {
+				legacy.Torrents = append(legacy.Torrents, legacyTorrent{
+					torrentFields: (*torrentFields)(t),
+					Date:          formatLegacyTime(t.Date, legacyTorrentDateLayout),
+				})
+			}
+		}
+	}
+	return legacy
+}
+
+func formatLegacyTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(layout)
+}
+
+// legacyFieldMigrations converts one string field per entry to its typed form.
+// Values that cannot be converted become 0 (numbers) or null (dates).
+var legacyFieldMigrations = []struct {
+	field      string
+	conversion bson.M
+}{
+	{"vote_average", bson.M{"$convert": bson.M{"input": "$vote_average", "to": "double", "onError": 0.0, "onNull": 0.0}}},
+	{"vote_count", bson.M{"$convert": bson.M{"input": "$vote_count", "to": "int", "onError": 0, "onNull": 0}}},
+	{"year", bson.M{"$convert": bson.M{"input": "$year", "to": "int", "onError": 0, "onNull": 0}}},
+	{"release_date", bson.M{"$convert": bson.M{"input": "$release_date", "to": "date", "onError": nil, "onNull": nil}}},
+}
+
+// MigrateLegacyFields rewrites documents saved before the typed fields were
+// introduced, converting string vote_average, vote_count, year and release_date
+// values in place. It is idempotent and returns the number of updated fields.
+func MigrateLegacyFields(ctx context.Context, collection *mongo.Collection) (int64, error) {
+	var modified int64
+	for _, migration := range legacyFieldMigrations {
+		filter := bson.M{migration.field: bson.M{"$type": "string"}}
+		update := mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: migration.field, Value: migration.conversion}}}}}
+
+		result, err := collection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return modified, fmt.Errorf("migrate mongo field %s: %w", migration.field, err)
+		}
+		modified += result.ModifiedCount
+	}
+	return modified, nil
+}
@@ -0,0 +1,51 @@
+package movies
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyWritesStringFields(t *testing.T) {
+	m := &Short{
+		ID:          "573435",
+		Title:       "Плохие парни до конца",
+		ReleaseDate: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC),
+		VoteAverage: 7.1,
+		VoteCount:   1234,
+		Year:        2024,
+		Torrents: []*torrents.Torrent{
+			{Name: "Bad Boys", Date: time.Date(2024, 6, 18, 14, 22, 11, 0, time.UTC)},
+		},
+	}
+
+	data, err := json.Marshal(Legacy{Short: m})
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, "573435", got["id"])
+	require.Equal(t, "2024-06-05", got["release_date"])
+	require.Equal(t, "7.1", got["vote_average"])
+	require.Equal(t, "1234", got["vote_count"])
+	require.Equal(t, "2024", got["Year"])
+
+	torrent := got["torrents"].([]any)[0].(map[string]any)
+	require.Equal(t, "Bad Boys", torrent["Name"])
+	require.Equal(t, "2024-06-18T14:22:11.000Z", torrent["Date"])
+}
+
+func TestLegacyMoviesEncodesArrayAndEmptyValues(t *testing.T) {
+	data, err := json.Marshal(LegacyMovies([]*Short{{ID: "1"}, nil}))
+	require.NoError(t, err)
+
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Len(t, got, 1)
+	require.Equal(t, "", got[0]["release_date"])
+	require.Equal(t, "", got[0]["Year"])
+	require.Nil(t, got[0]["torrents"])
+}
@@ -0,0 +1,146 @@
+package movies
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/lieranderl/go-tmdb"
+)
+
+const (
+	// MinMatchConfidence is the lowest score a TMDB candidate needs to be
+	// attached to a movie at all.
+	MinMatchConfidence = 0.5
+	// LowMatchConfidence is the score below which an attached match is flagged
+	// with Short.LowConfidence for review.
+	LowMatchConfidence = 0.8
+
+	titleWeight      = 0.7
+	yearWeight       = 0.25
+	popularityWeight = 0.05
+)
+
+var leadingArticles = map[string]struct{}{
+	"the": {}, "a": {}, "an": {},
+	"le": {}, "la": {}, "les": {},
+	"der": {}, "die": {}, "das": {},
+}
+
+type candidate struct {
+	result tmdb.MovieShort
+	score  float64
+}
+
+// bestCandidate scores every search result against the names the tracker
+// reported and returns the highest scoring one.
+func bestCandidate(names []string, year int, results []tmdb.MovieShort) (candidate, bool) {
+	var maxPopularity float32
+	for _, r := range results {
+		if r.Popularity > maxPopularity {
+			maxPopularity = r.Popularity
+		}
+	}
+
+	best := candidate{score: -1}
+	for _, r := range results {
+		score := scoreCandidate(names, year, r, maxPopularity)
+		if score > best.score {
+			best = candidate{result: r, score: score}
+		}
+	}
+	return best, best.score >= 0
+}
+
+func scoreCandidate(names []string, year int, r tmdb.MovieShort, maxPopularity float32) float64 {
+	title := 0.0
+	for _, name := range names {
+		title = max(title, titleSimilarity(name, r.Title), titleSimilarity(name, r.OriginalTitle))
+	}
+
+	popularity := 0.0
+	if maxPopularity > 0 {
+		popularity = float64(r.Popularity / maxPopularity)
+	}
+
+	years := yearScore(year, r.ReleaseDate)
+	if years == 0 {
+		// A title released two or more years apart is another film, e.g. the
+		// original of a remake, however well the title matches.
+		return 0
+	}
+	return titleWeight*title + yearWeight*years + popularityWeight*popularity
+}
+
+// titleSimilarity is 1 for titles that are equal after normalization and the
+// Dice coefficient of their words otherwise.
+func titleSimilarity(a, b string) float64 {
+	na, nb := normalizeTitle(a), normalizeTitle(b)
+	if na == "" || nb == "" {
+		return 0
+	}
+	if na == nb {
+		return 1
+	}
+
+	wa, wb := strings.Fields(na), strings.Fields(nb)
+	counts := make(map[string]int, len(wa))
+	for _, w := range wa {
+		counts[w]++
+	}
+	common := 0
+	for _, w := range wb {
+		if counts[w] > 0 {
+			counts[w]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(wa)+len(wb))
+}
+
+// normalizeTitle lowercases, folds ё into е, replaces punctuation with spaces
+// and drops a leading article, so "The Matrix:" and "matrix" compare equal.
+func normalizeTitle(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+
+	words := strings.Fields(s)
+	if len(words) > 1 {
+		if _, ok := leadingArticles[words[0]]; ok {
+			words = words[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// yearScore tolerates a one year difference, which is common between festival
+// premieres, local releases and the year trackers put in release names. It is
+// 0 only when both years are known and further apart, which rules the
+// candidate out.
+func yearScore(year int, releaseDate string) float64 {
+	released := parseReleaseDate(releaseDate)
+	if released.IsZero() {
+		return releaseYearScore(year, 0)
+	}
+	return releaseYearScore(year, released.Year())
+}
+
+// releaseYearScore is yearScore for sources that only report a release year.
+func releaseYearScore(year, released int) float64 {
+	if year <= 0 || released <= 0 {
+		return 0.6
+	}
+
+	switch diff := released - year; {
+	case diff == 0:
+		return 1
+	case diff == 1 || diff == -1:
+		return 0.8
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,75 @@
+package movies
+
+import (
+	"testing"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"The Matrix", "matrix"},
+		{"  Ёлки-палки!  ", "елки палки"},
+		{"Bad Boys: Ride or Die", "bad boys ride or die"},
+		{"The", "the"},
+		{"Léon", "léon"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, normalizeTitle(tt.in), tt.in)
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, titleSimilarity("Ёлки", "елки"))
+	require.Equal(t, 1.0, titleSimilarity("The Fall Guy", "Fall Guy"))
+	require.InDelta(t, 0.571, titleSimilarity("Bad Boys", "Bad Boys: Ride or Die"), 0.001)
+	require.Zero(t, titleSimilarity("Dune", "Arrival"))
+	require.Zero(t, titleSimilarity("", "Arrival"))
+}
+
+func TestYearScore(t *testing.T) {
+	require.Equal(t, 1.0, yearScore(2024, "2024-06-05"))
+	require.Equal(t, 0.8, yearScore(2024, "2023-12-25"))
+	require.Equal(t, 0.8, yearScore(2024, "2025-01-10"))
+	require.Zero(t, yearScore(2024, "1995-04-07"))
+	require.Equal(t, 0.6, yearScore(0, "1995-04-07"))
+	require.Equal(t, 0.6, yearScore(2024, ""))
+}
+
+func TestBestCandidatePrefersRussianTitleMatchOverFirstResult(t *testing.T) {
+	results := []tmdb.MovieShort{
+		{ID: 1, Title: "Плохие парни", OriginalTitle: "Bad Boys", ReleaseDate: "1995-04-07", Popularity: 80},
+		{ID: 2, Title: "Плохие парни до конца", OriginalTitle: "Bad Boys: Ride or Die", ReleaseDate: "2024-06-05", Popularity: 40},
+	}
+
+	best, ok := bestCandidate([]string{"Bad Boys Ride or Die", "Плохие парни до конца"}, 2024, results)
+
+	require.True(t, ok)
+	require.Equal(t, 2, best.result.ID)
+	require.GreaterOrEqual(t, best.score, LowMatchConfidence)
+}
+
+func TestBestCandidateRejectsTitlesFromAnotherYear(t *testing.T) {
+	// A remake TMDB does not list yet: only the 1994 original comes back.
+	results := []tmdb.MovieShort{
+		{ID: 9603, Title: "Ворон", OriginalTitle: "The Crow", ReleaseDate: "1994-05-11", Popularity: 30},
+	}
+
+	best, ok := bestCandidate([]string{"The Crow", "Ворон"}, 2024, results)
+
+	require.True(t, ok)
+	require.Less(t, best.score, MinMatchConfidence)
+
+	best, _ = bestCandidate([]string{"The Crow"}, 0, results)
+	require.GreaterOrEqual(t, best.score, MinMatchConfidence, "without a year the title decides")
+}
+
+func TestBestCandidateEmpty(t *testing.T) {
+	_, ok := bestCandidate([]string{"Dune"}, 2021, nil)
+	require.False(t, ok)
+}
@@ -1,26 +1,62 @@
 package movies
 
 import (
+	"strings"
 	"time"
 
 	"github.com/lieranderl/moviestracker-package/internal/torrents"
 )
 
 type Short struct {
-	BackdropPath  string  `json:"backdrop_path" bson:"backdrop_path"`
-	ID            string  `json:"id" bson:"id"`
-	OriginalTitle string  `json:"original_title" bson:"original_title"`
-	GenreIDs      []int32 `json:"genre_ids" bson:"genre_ids"`
-	PosterPath    string  `json:"poster_path" bson:"poster_path"`
-	ReleaseDate   string  `json:"release_date" bson:"release_date"`
-	Title         string  `json:"title"`
-	VoteAverage   string  `json:"vote_average" bson:"vote_average"`
-	VoteCount     string  `json:"vote_count" bson:"vote_count"`
-	Year          string
-	Torrents      []*torrents.Torrent `json:"torrents" bson:"torrents,omitempty"`
-	Hash          string              `json:"hash" bson:"hash,omitempty"`
-	Searchname    string              `json:"searchname" bson:"searchname,omitempty"`
-	LastTimeFound time.Time           `json:"lasttimefound" bson:"lasttimefound"`
+	BackdropPath    string    `json:"backdrop_path" bson:"backdrop_path"`
+	ID              string    `json:"id" bson:"id"`
+	OriginalTitle   string    `json:"original_title" bson:"original_title"`
+	GenreIDs        []int32   `json:"genre_ids" bson:"genre_ids"`
+	PosterPath      string    `json:"poster_path" bson:"poster_path"`
+	ReleaseDate     time.Time `json:"release_date" bson:"release_date"`
+	Title           string    `json:"title"`
+	VoteAverage     float64   `json:"vote_average" bson:"vote_average"`
+	VoteCount       int       `json:"vote_count" bson:"vote_count"`
+	Year            int
+	Torrents        []*torrents.Torrent `json:"torrents" bson:"torrents,omitempty"`
+	Hash            string              `json:"hash" bson:"hash,omitempty"`
+	Searchname      string              `json:"searchname" bson:"searchname,omitempty"`
+	LastTimeFound   time.Time           `json:"lasttimefound" bson:"lasttimefound"`
+	ImdbID          string              `json:"imdb_id" bson:"imdb_id,omitempty"`
+	MatchStrategy   string              `json:"match_strategy" bson:"match_strategy,omitempty"`
+	MatchConfidence float64             `json:"match_confidence" bson:"match_confidence,omitempty"`
+	LowConfidence   bool                `json:"low_confidence" bson:"low_confidence,omitempty"`
+	AltSearchname   string              `json:"-" bson:"-"`
+	Titles          map[string]string   `json:"titles" bson:"titles,omitempty"`
+	Overviews       map[string]string   `json:"overviews" bson:"overviews,omitempty"`
+	Posters         map[string]string   `json:"posters" bson:"posters,omitempty"`
+	Genres          map[string][]string `json:"genres" bson:"genres,omitempty"`
+	KinopoiskID     string              `json:"kinopoisk_id" bson:"kinopoisk_id,omitempty"`
+	Ratings         map[string]Rating   `json:"ratings" bson:"ratings,omitempty"`
+}
+
+// Rating sources used as Short.Ratings keys.
+const (
+	RatingTMDB      = "tmdb"
+	RatingKinopoisk = "kinopoisk"
+	RatingIMDb      = "imdb"
+)
+
+// Rating is one source's score on that source's own scale.
+type Rating struct {
+	Value float64 `json:"value" bson:"value"`
+	Votes int     `json:"votes,omitempty" bson:"votes,omitempty"`
+}
+
+// setRating records a rating; missing (zero) values are ignored.
+func (m *Short) setRating(source string, value float64, votes int) {
+	if value <= 0 {
+		return
+	}
+	if m.Ratings == nil {
+		m.Ratings = make(map[string]Rating)
+	}
+	m.Ratings[source] = Rating{Value: value, Votes: votes}
 }
 
 func (m *Short) UpdateMoviesAttribs() {
@@ -30,18 +66,56 @@ func (m *Short) UpdateMoviesAttribs() {
 }
 
 func (m *Short) setLastTimeFound(t *torrents.Torrent) {
-	const layout = "2006-01-02T15:04:05.000Z"
+	if t.Date.IsZero() {
+		t.Date = time.Now().UTC()
+	}
 
-	if t.Date == "" {
-		t.Date = time.Now().UTC().Format(layout)
+	if t.Date.After(m.LastTimeFound) {
+		m.LastTimeFound = t.Date
 	}
+}
 
-	timeformat, err := time.Parse(layout, t.Date)
-	if err != nil {
-		timeformat = time.Now().UTC()
+// MergeTorrents adds more to m's torrents. A torrent with the magnet hash of
+// one m already has replaces it, so the later sighting (e.g. with fresh seed
+// counts) wins; torrents without a magnet hash are always added.
+func (m *Short) MergeTorrents(more []*torrents.Torrent) {
+	index := make(map[string]int, len(m.Torrents))
+	for i, t := range m.Torrents {
+		if key := strings.ToLower(t.MagnetHash); key != "" {
+			index[key] = i
+		}
+	}
+	for _, t := range more {
+		key := strings.ToLower(t.MagnetHash)
+		if i, ok := index[key]; ok && key != "" {
+			m.Torrents[i] = t
+			continue
+		}
+		if key != "" {
+			index[key] = len(m.Torrents)
+		}
+		m.Torrents = append(m.Torrents, t)
 	}
+	m.UpdateMoviesAttribs()
+}
 
-	if timeformat.After(m.LastTimeFound) {
-		m.LastTimeFound = timeformat
+// MergeByID folds movies that matched the same TMDB id into the first of
+// them, e.g. when rutor and kinozal spell a release differently and so group
+// its torrents into two movies. Movies without an id are kept as they are.
+func MergeByID(found []*Short) []*Short {
+	merged := make([]*Short, 0, len(found))
+	byID := make(map[string]*Short, len(found))
+	for _, m := range found {
+		if m.ID == "" {
+			merged = append(merged, m)
+			continue
+		}
+		if first, ok := byID[m.ID]; ok {
+			first.MergeTorrents(m.Torrents)
+			continue
+		}
+		byID[m.ID] = m
+		merged = append(merged, m)
 	}
+	return merged
 }
@@ -11,8 +11,8 @@ import (
 func TestUpdateMoviesAttribsUsesLatestDate(t *testing.T) {
 	m := &Short{
 		Torrents: []*torrents.Torrent{
-			{Date: "2024-01-01T00:00:00.000Z"},
-			{Date: "2024-02-01T00:00:00.000Z"},
+			{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
 		},
 	}
 
@@ -21,11 +21,32 @@ func TestUpdateMoviesAttribsUsesLatestDate(t *testing.T) {
 	require.Equal(t, "2024-02-01T00:00:00.000Z", m.LastTimeFound.UTC().Format("2006-01-02T15:04:05.000Z"))
 }
 
-func TestSetLastTimeFoundFallbackOnInvalidDate(t *testing.T) {
+func TestSetLastTimeFoundFallbackOnMissingDate(t *testing.T) {
 	m := &Short{}
 	before := time.Now().UTC().Add(-2 * time.Second)
 
-	m.setLastTimeFound(&torrents.Torrent{Date: "invalid"})
+	m.setLastTimeFound(&torrents.Torrent{})
 
 	require.True(t, m.LastTimeFound.After(before))
 }
+
+func TestMergeByIDCombinesTorrentsOfTheSameMovie(t *testing.T) {
+	rutor := &torrents.Torrent{Name: "Плохие парни до конца 2160p", MagnetHash: "AAA", Date: time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)}
+	kinozal := &torrents.Torrent{Name: "Плохие парни: До конца 1080p", MagnetHash: "bbb", Date: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}
+	both := &torrents.Torrent{Name: "Плохие парни до конца 2160p (kinozal)", MagnetHash: "aaa", Seeds: 40, Date: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)}
+	found := []*Short{
+		{ID: "573435", Hash: "rutor-group", Torrents: []*torrents.Torrent{rutor}},
+		{ID: "693134", Hash: "dune"},
+		{ID: "573435", Hash: "kinozal-group", Torrents: []*torrents.Torrent{kinozal, both}},
+		{Hash: "unmatched"},
+	}
+
+	merged := MergeByID(found)
+
+	require.Len(t, merged, 3)
+	require.Equal(t, "rutor-group", merged[0].Hash)
+	require.Equal(t, []*torrents.Torrent{both, kinozal}, merged[0].Torrents, "a magnet hash is kept once, the later sighting wins")
+	require.Equal(t, kinozal.Date, merged[0].LastTimeFound)
+	require.Equal(t, "dune", merged[1].Hash)
+	require.Equal(t, "unmatched", merged[2].Hash)
+}
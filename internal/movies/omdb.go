@@ -0,0 +1,217 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"github.com/lieranderl/moviestracker-package/pkg/pipeline"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// OMDbBaseURL is the root of the OMDb API.
+	OMDbBaseURL = "https://www.omdbapi.com"
+	// OMDbRateLimit spreads the free tier's 1000 daily requests less than it
+	// protects the service; OMDb has no published per-second limit.
+	OMDbRateLimit = 10
+
+	RatingRottenTomatoes = "rotten_tomatoes"
+	RatingMetacritic     = "metacritic"
+)
+
+// omdbSources maps OMDb's Ratings[].Source to Short.Ratings keys.
+var omdbSources = map[string]string{
+	"Internet Movie Database": RatingIMDb,
+	"Rotten Tomatoes":         RatingRottenTomatoes,
+	"Metacritic":              RatingMetacritic,
+}
+
+// OMDb reads IMDb, Rotten Tomatoes and Metacritic ratings by IMDb id.
+type OMDb struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	limits  *requestLimiter
+	cache   cache.Cache
+	ttl     time.Duration
+}
+
+func OMDbInit(apiKey string) *OMDb {
+	return &OMDb{
+		baseURL: OMDbBaseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: httpAPITimeout},
+		limits:  newRequestLimiter(rate.NewLimiter(OMDbRateLimit, defaultRateBurst)),
+	}
+}
+
+// WithBaseURL sends requests to an OMDb compatible server. An empty baseURL
+// keeps the public API.
+func (o *OMDb) WithBaseURL(baseURL string) *OMDb {
+	if baseURL != "" {
+		o.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	return o
+}
+
+// WithCache keeps ratings in c for ttl (DefaultCacheTTL when ttl <= 0). A nil
+// cache leaves the client unchanged.
+func (o *OMDb) WithCache(c cache.Cache, ttl time.Duration) *OMDb {
+	if c == nil {
+		return o
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	o.cache, o.ttl = c, ttl
+	return o
+}
+
+type omdbRatings struct {
+	Ratings map[string]Rating `json:"ratings"`
+}
+
+// Rate adds the OMDb ratings of m, which needs an IMDb id; movies without one
+// and ids OMDb does not know are left unchanged.
+func (o *OMDb) Rate(ctx context.Context, m *Short) error {
+	if m.ImdbID == "" {
+		return nil
+	}
+
+	fetch := func() (*omdbRatings, error) { return o.fetch(ctx, m.ImdbID) }
+	var (
+		ratings *omdbRatings
+		err     error
+	)
+	if o.cache != nil {
+		ratings, err = cached(ctx, o.cache, o.ttl, "omdb:"+m.ImdbID, fetch)
+	} else {
+		ratings, err = fetch()
+	}
+	if err != nil {
+		return fmt.Errorf("omdb ratings %s: %w", m.ImdbID, err)
+	}
+
+	for source, r := range ratings.Ratings {
+		m.setRating(source, r.Value, r.Votes)
+	}
+	return nil
+}
+
+func (o *OMDb) fetch(ctx context.Context, imdbID string) (*omdbRatings, error) {
+	var r struct {
+		Response  string `json:"Response"`
+		Error     string `json:"Error"`
+		ImdbVotes string `json:"imdbVotes"`
+		Ratings   []struct {
+			Source string `json:"Source"`
+			Value  string `json:"Value"`
+		} `json:"Ratings"`
+	}
+	query := url.Values{"i": {imdbID}, "apikey": {o.apiKey}}
+	_, err := retry(ctx, o.limits, func() (struct{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+"/?"+query.Encode(), nil)
+		if err != nil {
+			return struct{}{}, err
+		}
+		return struct{}{}, getJSON(o.client, req, "omdb", "/?i="+imdbID, &r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := &omdbRatings{Ratings: make(map[string]Rating)}
+	if r.Response == "False" {
+		// "Movie not found!" and "Incorrect IMDb ID." come with status 200.
+		slog.DebugContext(ctx, "omdb has no ratings", "imdb_id", imdbID, "error", r.Error)
+		return ratings, nil
+	}
+	for _, rating := range r.Ratings {
+		source, ok := omdbSources[rating.Source]
+		if !ok {
+			continue
+		}
+		if value, ok := parseOMDbRating(rating.Value); ok {
+			ratings.Ratings[source] = Rating{Value: value}
+		}
+	}
+	if imdb, ok := ratings.Ratings[RatingIMDb]; ok {
+		imdb.Votes, _ = strconv.Atoi(strings.ReplaceAll(r.ImdbVotes, ",", ""))
+		ratings.Ratings[RatingIMDb] = imdb
+	}
+	return ratings, nil
+}
+
+// parseOMDbRating reads "7.1/10", "94%" and "70/100" as 7.1, 94 and 70.
+func parseOMDbRating(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSuffix(value, "%")
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f <= 0 {
+		return 0, false
+	}
+	return f, true
+}
+
+// RateMovies runs rater for every movie with at most limit calls at a time.
+// Failed movies keep their ratings so far and are returned in skipped; the
+// first fatal error (see IsFatal) or ctx's error stops the run and is returned
+// as err. It returns only after every rater call has.
+func RateMovies(ctx context.Context, movies []*Short, rater func(context.Context, *Short) error, limit int64) (skipped []error, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	in, err := pipeline.Producer(ctx, movies)
+	if err != nil {
+		return nil, err
+	}
+	values, errs := pipeline.StepContext(ctx, in, func(ctx context.Context, m *Short) (*Short, error) {
+		err := rater(ctx, m)
+		if err != nil && !IsFatal(err) {
+			slog.WarnContext(logging.With(ctx, "movie_hash", m.Hash), "skipping movie ratings", "error", err)
+		}
+		return m, err
+	}, limit)
+
+	// Drain both channels even once ctx is done: StepContext closes them only
+	// after its workers, which write the movies' ratings, have returned.
+	for values != nil || errs != nil {
+		select {
+		case e, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if e == nil {
+				continue
+			}
+			if IsFatal(e) || ctx.Err() != nil {
+				cancel()
+				if err == nil {
+					err = e
+				}
+				continue
+			}
+			skipped = append(skipped, e)
+		case _, ok := <-values:
+			if !ok {
+				values = nil
+			}
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return skipped, err
+}
@@ -0,0 +1,127 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+	"github.com/stretchr/testify/require"
+)
+
+const omdbDune = `{"Title":"Dune: Part Two","Year":"2024","imdbRating":"8.5","imdbVotes":"612,345","Response":"True",
+	"Ratings":[{"Source":"Internet Movie Database","Value":"8.5/10"},{"Source":"Rotten Tomatoes","Value":"92%"},{"Source":"Metacritic","Value":"79/100"}]}`
+
+func newOMDbStandIn(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Query().Get("apikey") != "omdb-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"Response":"False","Error":"Invalid API key!"}`)
+			return
+		}
+		switch r.URL.Query().Get("i") {
+		case "tt15239678":
+			fmt.Fprint(w, omdbDune)
+		default:
+			fmt.Fprint(w, `{"Response":"False","Error":"Incorrect IMDb ID."}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestParseOMDbRating(t *testing.T) {
+	for value, want := range map[string]float64{"7.1/10": 7.1, "94%": 94, "70/100": 70} {
+		got, ok := parseOMDbRating(value)
+		require.True(t, ok, value)
+		require.Equal(t, want, got, value)
+	}
+	_, ok := parseOMDbRating("N/A")
+	require.False(t, ok)
+}
+
+func TestOMDbRateFillsRatingsAndUsesCache(t *testing.T) {
+	var calls atomic.Int32
+	omdb := OMDbInit("omdb-key").
+		WithBaseURL(newOMDbStandIn(t, &calls).URL).
+		WithCache(cache.NewMemory(10), 0)
+
+	for range 2 {
+		m := &Short{ImdbID: "tt15239678", Ratings: map[string]Rating{RatingTMDB: {Value: 8.2, Votes: 6112}}}
+		require.NoError(t, omdb.Rate(context.Background(), m))
+		require.Equal(t, map[string]Rating{
+			RatingTMDB:           {Value: 8.2, Votes: 6112},
+			RatingIMDb:           {Value: 8.5, Votes: 612345},
+			RatingRottenTomatoes: {Value: 92},
+			RatingMetacritic:     {Value: 79},
+		}, m.Ratings)
+	}
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestOMDbRateSkipsUnknownAndMissingIDs(t *testing.T) {
+	var calls atomic.Int32
+	omdb := OMDbInit("omdb-key").WithBaseURL(newOMDbStandIn(t, &calls).URL)
+
+	unknown := &Short{ImdbID: "tt0000001"}
+	require.NoError(t, omdb.Rate(context.Background(), unknown))
+	require.Empty(t, unknown.Ratings)
+
+	require.NoError(t, omdb.Rate(context.Background(), &Short{}))
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestOMDbRateReportsInvalidKeyAsFatal(t *testing.T) {
+	var calls atomic.Int32
+	omdb := OMDbInit("wrong").WithBaseURL(newOMDbStandIn(t, &calls).URL)
+
+	err := omdb.Rate(context.Background(), &Short{ImdbID: "tt15239678"})
+
+	require.True(t, IsFatal(err))
+	require.ErrorContains(t, err, "Invalid API key!")
+}
+
+func TestRateMoviesSkipsFailuresAndStopsOnFatal(t *testing.T) {
+	ms := []*Short{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	skipped, err := RateMovies(context.Background(), ms, func(_ context.Context, m *Short) error {
+		if m.ID == "2" {
+			return errors.New("decode failed")
+		}
+		m.setRating(RatingIMDb, 7, 0)
+		return nil
+	}, 2)
+	require.NoError(t, err)
+	require.Len(t, skipped, 1)
+	require.Equal(t, 7.0, ms[0].Ratings[RatingIMDb].Value)
+
+	_, err = RateMovies(context.Background(), ms, func(context.Context, *Short) error {
+		return &APIError{StatusCode: http.StatusUnauthorized}
+	}, 1)
+	require.True(t, IsFatal(err))
+}
+
+func TestRateMoviesReturnsAfterItsRaters(t *testing.T) {
+	ms := []*Short{{ID: "1"}, {ID: "2"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := RateMovies(ctx, ms, func(_ context.Context, m *Short) error {
+		time.Sleep(50 * time.Millisecond)
+		m.setRating(RatingIMDb, 7, 0)
+		return nil
+	}, 2)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// Under -race this catches a rater still writing the ratings map.
+	for _, m := range ms {
+		m.setRating(RatingIMDb, 8, 0)
+	}
+}
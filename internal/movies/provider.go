@@ -0,0 +1,129 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Provider looks movies up in one metadata source.
+type Provider interface {
+	Name() string
+	// Match fills m's metadata from the source and reports whether the movie
+	// was found there.
+	Match(ctx context.Context, m *Short) (bool, error)
+	// Rate adds the source's ratings to a movie another provider matched.
+	Rate(ctx context.Context, m *Short) error
+}
+
+// Chain tries its providers in order until one matches a movie and then asks
+// the remaining ones for their ratings, e.g. TMDB first and Kinopoisk for
+// Russian-only releases TMDB does not know.
+//
+// A fatal error (see IsFatal) from the first provider stops the chain; later
+// providers are disabled for the rest of the run instead, so an exhausted
+// fallback quota does not abort the enrichment.
+type Chain struct {
+	providers []Provider
+	disabled  []atomic.Bool
+}
+
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers, disabled: make([]atomic.Bool, len(providers))}
+}
+
+func (c *Chain) Name() string {
+	names := make([]string, 0, len(c.providers))
+	for _, p := range c.providers {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+// Match returns the error of the last failed lookup only when no provider
+// matched the movie.
+func (c *Chain) Match(ctx context.Context, m *Short) (bool, error) {
+	matched := false
+	var matchErr error
+
+	for i, p := range c.providers {
+		if c.disabled[i].Load() {
+			continue
+		}
+
+		var err error
+		if matched {
+			err = traceRate(ctx, p, m)
+		} else {
+			matched, err = traceMatch(ctx, p, m)
+		}
+		if err == nil {
+			continue
+		}
+		if IsFatal(err) {
+			if i == 0 {
+				return false, err
+			}
+			c.disable(ctx, i, err)
+			continue
+		}
+		if matched {
+			slog.WarnContext(ctx, "provider ratings failed", "provider", p.Name(), "searchname", m.Searchname, "error", err)
+			continue
+		}
+		slog.DebugContext(ctx, "provider lookup failed", "provider", p.Name(), "searchname", m.Searchname, "error", err)
+		matchErr = err
+	}
+
+	if !matched {
+		return false, matchErr
+	}
+	return true, nil
+}
+
+// Rate collects the ratings of every enabled provider.
+func (c *Chain) Rate(ctx context.Context, m *Short) error {
+	var errs []error
+	for i, p := range c.providers {
+		if c.disabled[i].Load() {
+			continue
+		}
+		if err := traceRate(ctx, p, m); err != nil {
+			if IsFatal(err) && i > 0 {
+				c.disable(ctx, i, err)
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// traceMatch runs p.Match in a "<provider>.match" span, so the traces of a chain
+// show which provider found a movie.
+func traceMatch(ctx context.Context, p Provider, m *Short) (bool, error) {
+	ctx, span := tracer.Start(ctx, p.Name()+".match", trace.WithAttributes(attribute.String("movie.hash", m.Hash)))
+	found, err := p.Match(ctx, m)
+	span.SetAttributes(attribute.Bool("movie.matched", found))
+	endSpan(span, err)
+	return found, err
+}
+
+// traceRate runs p.Rate in a "<provider>.rate" span.
+func traceRate(ctx context.Context, p Provider, m *Short) error {
+	ctx, span := tracer.Start(ctx, p.Name()+".rate", trace.WithAttributes(attribute.String("movie.hash", m.Hash)))
+	err := p.Rate(ctx, m)
+	endSpan(span, err)
+	return err
+}
+
+func (c *Chain) disable(ctx context.Context, i int, err error) {
+	if c.disabled[i].CompareAndSwap(false, true) {
+		slog.ErrorContext(ctx, "disabling metadata provider", "provider", c.providers[i].Name(), "error", err)
+	}
+}
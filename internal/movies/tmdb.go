@@ -3,79 +3,319 @@ package movies
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 
 	// "math/rand"
 
 	"github.com/lieranderl/go-tmdb"
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
 	"github.com/lieranderl/moviestracker-package/pkg/pipeline"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
+var tracer = otel.Tracer("github.com/lieranderl/moviestracker-package/internal/movies")
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Match strategies recorded on Short.MatchStrategy.
+const (
+	MatchByIMDbID = "imdb_id"
+	MatchBySearch = "search"
+)
+
+// api is the subset of the TMDB client used by the enrichment stages. Calls
+// give up when ctx is done, including while they wait for the rate limit or a
+// retry.
+type api interface {
+	SearchMovie(ctx context.Context, name string, options map[string]string) (*tmdb.MovieSearchResults, error)
+	GetFind(ctx context.Context, id, source string, options map[string]string) (*tmdb.FindResults, error)
+	GetMovieImages(ctx context.Context, id int, options map[string]string) (*tmdb.MovieImages, error)
+	GetMovieInfo(ctx context.Context, id int, options map[string]string) (*tmdb.Movie, error)
+	GetMovieGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error)
+	GetTvGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error)
+}
+
+const defaultLanguage = "ru"
+
 type TMDb struct {
-	tmdb *tmdb.TMDb
+	tmdb      api
+	apiKey    string
+	languages []string
+	limiter   *rate.Limiter
 }
 
+// TMDBInit returns a client for the public TMDB API that is limited to
+// DefaultRateLimit requests per second and retries rate-limited, server and
+// network errors.
 func TMDBInit(tmdbkey string) *TMDb {
-	var TMDBCONFIG = tmdb.Config{
-		APIKey:   tmdbkey,
-		Proxies:  nil,
-		UseProxy: false,
+	mytmdb := &TMDb{
+		apiKey:    tmdbkey,
+		languages: []string{defaultLanguage},
+		limiter:   rate.NewLimiter(DefaultRateLimit, defaultRateBurst),
 	}
-	mytmdb := new(TMDb)
-	mytmdb.tmdb = tmdb.Init(TMDBCONFIG)
+	mytmdb.tmdb = mytmdb.newClient(DefaultBaseURL)
 	return mytmdb
 }
 
-func (tmdbapi *TMDb) fetchMovieDetails(m *Short) (*Short, error) {
-	options := make(map[string]string)
-	options["language"] = "ru"
-	options["year"] = m.Year
-	r, err := tmdbapi.tmdb.SearchMovie(m.Searchname, options)
+// WithLanguages sets the languages movie metadata is stored in. The first one
+// is used for matching and for the top-level Title; every language gets an
+// entry in Titles, Overviews and Posters. Empty input keeps the default "ru".
+func (tmdbapi *TMDb) WithLanguages(languages ...string) *TMDb {
+	cleaned := make([]string, 0, len(languages))
+	seen := make(map[string]struct{}, len(languages))
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if _, ok := seen[language]; ok || language == "" {
+			continue
+		}
+		seen[language] = struct{}{}
+		cleaned = append(cleaned, language)
+	}
+	if len(cleaned) > 0 {
+		tmdbapi.languages = cleaned
+	}
+	return tmdbapi
+}
+
+// Languages returns the configured metadata languages, primary first.
+func (tmdbapi *TMDb) Languages() []string {
+	if len(tmdbapi.languages) == 0 {
+		return []string{defaultLanguage}
+	}
+	return append([]string(nil), tmdbapi.languages...)
+}
+
+func (tmdbapi *TMDb) primaryLanguage() string {
+	if len(tmdbapi.languages) == 0 {
+		return defaultLanguage
+	}
+	return tmdbapi.languages[0]
+}
+
+// WithCache serves search, find and image responses from c for ttl before
+// asking TMDB again. A nil cache leaves the client unchanged.
+func (tmdbapi *TMDb) WithCache(c cache.Cache, ttl time.Duration) *TMDb {
+	if c == nil {
+		return tmdbapi
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	tmdbapi.tmdb = &cachedAPI{next: tmdbapi.tmdb, cache: c, ttl: ttl}
+	return tmdbapi
+}
+
+func (tmdbapi *TMDb) Name() string {
+	return "tmdb"
+}
+
+// Match matches m by IMDb id or title search; see fetchMovieDetails.
+func (tmdbapi *TMDb) Match(ctx context.Context, m *Short) (bool, error) {
+	if _, err := tmdbapi.fetchMovieDetails(ctx, m); err != nil {
+		return false, err
+	}
+	return m.MatchStrategy == MatchByIMDbID || m.MatchStrategy == MatchBySearch, nil
+}
+
+// Rate adds the TMDB rating of a movie another provider matched. Only movies
+// with an IMDb id can be rated, because a title search could pick another film.
+func (tmdbapi *TMDb) Rate(ctx context.Context, m *Short) error {
+	if m.ImdbID == "" {
+		return nil
+	}
+	r, err := tmdbapi.tmdb.GetFind(ctx, m.ImdbID, "imdb_id", map[string]string{"language": tmdbapi.primaryLanguage()})
 	if err != nil {
-		return nil, fmt.Errorf("tmdb search %q (%s): %w", m.Searchname, m.Year, err)
+		return fmt.Errorf("tmdb rating %s: %w", m.ImdbID, err)
 	}
-	if len(r.Results) > 0 {
-		releaseDate := strings.TrimSpace(r.Results[0].ReleaseDate)
-		releaseYear := ""
-		if len(releaseDate) >= 4 {
-			releaseYear = releaseDate[:4]
-		}
+	if len(r.MovieResults) > 0 {
+		m.setRating(RatingTMDB, float64From32(r.MovieResults[0].VoteAverage), int(r.MovieResults[0].VoteCount))
+	}
+	return nil
+}
 
-		sameTitle := strings.EqualFold(strings.TrimSpace(m.Searchname), strings.TrimSpace(r.Results[0].OriginalTitle)) ||
-			strings.EqualFold(strings.TrimSpace(m.Searchname), strings.TrimSpace(r.Results[0].Title))
-		sameYear := m.Year == "" || releaseYear == "" || m.Year == releaseYear
-
-		if sameTitle && sameYear {
-			// m.Adult = r.Results[0].Adult
-			m.BackdropPath = r.Results[0].BackdropPath
-			m.ID = fmt.Sprint(r.Results[0].ID)
-			m.OriginalTitle = r.Results[0].OriginalTitle
-			m.GenreIDs = r.Results[0].GenreIDs
-			// m.Popularity = r.Results[0].Popularity
-			m.PosterPath = r.Results[0].PosterPath
-			m.ReleaseDate = r.Results[0].ReleaseDate
-			m.Title = r.Results[0].Title
-			// m.Overview = r.Results[0].Overview
-			// m.Video = r.Results[0].Video
-			m.VoteAverage = fmt.Sprintf("%.1f", r.Results[0].VoteAverage)
-			m.VoteCount = fmt.Sprint(r.Results[0].VoteCount)
+// FillIMDbID looks up the IMDb id of a TMDB match that has none, e.g. one
+// found by title search. Other movies are left unchanged.
+func (tmdbapi *TMDb) FillIMDbID(ctx context.Context, m *Short) error {
+	if m.ImdbID != "" || (m.MatchStrategy != MatchByIMDbID && m.MatchStrategy != MatchBySearch) {
+		return nil
+	}
+	id, err := strconv.Atoi(m.ID)
+	if err != nil {
+		return nil
+	}
+	info, err := tmdbapi.tmdb.GetMovieInfo(ctx, id, map[string]string{"language": tmdbapi.primaryLanguage()})
+	if err != nil {
+		return fmt.Errorf("tmdb imdb id %s (%s): %w", m.ID, m.OriginalTitle, err)
+	}
+	m.ImdbID = info.ImdbID
+	return nil
+}
+
+func (tmdbapi *TMDb) fetchMovieDetails(ctx context.Context, m *Short) (*Short, error) {
+	if m.ImdbID != "" {
+		found, err := tmdbapi.findByIMDbID(ctx, m)
+		if err != nil {
+			return nil, err
 		}
-		// updage backdrop to english
-		options["language"] = "en"
-		images, imageErr := tmdbapi.tmdb.GetMovieImages(r.Results[0].ID, options)
-		if imageErr == nil && len(images.Backdrops) > 0 {
-			if m.BackdropPath == "" {
-				m.BackdropPath = images.Backdrops[0].FilePath
-			}
+		if found {
+			return m, nil
 		}
 	}
+	return tmdbapi.searchMovie(ctx, m)
+}
+
+// findByIMDbID looks the movie up through TMDB's /find endpoint, which is an
+// exact match and does not depend on title translations.
+func (tmdbapi *TMDb) findByIMDbID(ctx context.Context, m *Short) (bool, error) {
+	options := map[string]string{"language": tmdbapi.primaryLanguage()}
+	r, err := tmdbapi.tmdb.GetFind(ctx, m.ImdbID, "imdb_id", options)
+	if err != nil {
+		return false, fmt.Errorf("tmdb find %s (%s): %w", m.ImdbID, m.Searchname, err)
+	}
+	if len(r.MovieResults) == 0 {
+		return false, nil
+	}
+
+	applyResult(m, r.MovieResults[0])
+	m.MatchStrategy = MatchByIMDbID
+	m.MatchConfidence = 1
+	tmdbapi.fillBackdrop(ctx, m, r.MovieResults[0].ID)
+	tmdbapi.localize(ctx, m, r.MovieResults[0])
+	return true, nil
+}
+
+func (tmdbapi *TMDb) searchMovie(ctx context.Context, m *Short) (*Short, error) {
+	names := []string{m.Searchname, m.AltSearchname}
+
+	options := make(map[string]string)
+	options["language"] = tmdbapi.primaryLanguage()
+	if m.Year > 0 {
+		options["year"] = strconv.Itoa(m.Year)
+	}
+	r, err := tmdbapi.tmdb.SearchMovie(ctx, m.Searchname, options)
+	if err != nil {
+		return nil, fmt.Errorf("tmdb search %q (%d): %w", m.Searchname, m.Year, err)
+	}
+	results := r.Results
+
+	// TMDB filters by exact year, so widen the search to honour the ±1 year
+	// tolerance when the year-restricted results are not convincing.
+	if best, ok := bestCandidate(names, m.Year, results); m.Year > 0 && (!ok || best.score < LowMatchConfidence) {
+		delete(options, "year")
+		r, err = tmdbapi.tmdb.SearchMovie(ctx, m.Searchname, options)
+		if err != nil {
+			return nil, fmt.Errorf("tmdb search %q: %w", m.Searchname, err)
+		}
+		results = append(results, r.Results...)
+	}
+
+	best, ok := bestCandidate(names, m.Year, results)
+	if !ok || best.score < MinMatchConfidence {
+		return m, nil
+	}
+
+	applyResult(m, best.result)
+	m.MatchStrategy = MatchBySearch
+	m.MatchConfidence = best.score
+	m.LowConfidence = best.score < LowMatchConfidence
+	if m.LowConfidence {
+		slog.WarnContext(ctx, "low confidence tmdb match", "searchname", m.Searchname, "year", m.Year, "title", m.OriginalTitle, "release_date", m.ReleaseDate, "confidence", best.score)
+	}
+	tmdbapi.fillBackdrop(ctx, m, best.result.ID)
+	tmdbapi.localize(ctx, m, best.result)
 
-	// m.ID = int(rand.Int63())
-	// m.OriginalTitle=""
 	return m, nil
 }
 
-func MoviesPipelineStream(ctx context.Context, movies []*Short, tmdbkey string, limit int64) (chan *Short, chan error) {
+func applyResult(m *Short, r tmdb.MovieShort) {
+	// m.Adult = r.Adult
+	m.BackdropPath = r.BackdropPath
+	m.ID = fmt.Sprint(r.ID)
+	m.OriginalTitle = r.OriginalTitle
+	m.GenreIDs = r.GenreIDs
+	// m.Popularity = r.Popularity
+	m.PosterPath = r.PosterPath
+	m.ReleaseDate = parseReleaseDate(r.ReleaseDate)
+	m.Title = r.Title
+	// m.Overview = r.Overview
+	// m.Video = r.Video
+	m.VoteAverage = float64From32(r.VoteAverage)
+	m.VoteCount = int(r.VoteCount)
+	m.setRating(RatingTMDB, m.VoteAverage, m.VoteCount)
+}
+
+// parseReleaseDate parses TMDB's "2006-01-02" dates; unknown dates stay zero.
+func parseReleaseDate(s string) time.Time {
+	date, err := time.Parse(time.DateOnly, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// float64From32 widens TMDB's float32 values without the binary noise of a
+// plain conversion, so 7.1 stays 7.1 instead of 7.099999904632568.
+func float64From32(v float32) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+	return f
+}
+
+// fillBackdrop falls back to the english backdrop when TMDB has no localized one.
+func (tmdbapi *TMDb) fillBackdrop(ctx context.Context, m *Short, id int) {
+	if m.BackdropPath != "" {
+		return
+	}
+	options := map[string]string{"language": "en"}
+	images, imageErr := tmdbapi.tmdb.GetMovieImages(ctx, id, options)
+	if imageErr == nil && len(images.Backdrops) > 0 {
+		m.BackdropPath = images.Backdrops[0].FilePath
+	}
+}
+
+// localize stores title, overview and poster for every configured language. The
+// primary language comes with the matched result; the others cost one movie
+// details request each and are skipped with a warning when that fails.
+func (tmdbapi *TMDb) localize(ctx context.Context, m *Short, r tmdb.MovieShort) {
+	primary := tmdbapi.primaryLanguage()
+	m.Titles = map[string]string{primary: r.Title}
+	m.Overviews = map[string]string{primary: r.Overview}
+	m.Posters = map[string]string{primary: r.PosterPath}
+
+	for _, language := range tmdbapi.languages {
+		if language == primary {
+			continue
+		}
+		info, err := tmdbapi.tmdb.GetMovieInfo(ctx, r.ID, map[string]string{"language": language})
+		if err != nil {
+			slog.WarnContext(ctx, "tmdb localized details failed", "id", r.ID, "language", language, "error", err)
+			continue
+		}
+		m.Titles[language] = info.Title
+		m.Overviews[language] = info.Overview
+		m.Posters[language] = info.PosterPath
+	}
+}
+
+// MoviesPipelineStream matches every movie with provider, running at most
+// limit lookups concurrently. Pass a *TMDb or a Chain of providers. Each
+// lookup is traced as "<provider name>.match", e.g. "tmdb+kinopoisk.match"
+// for a Chain, whose steps are traced per provider.
+func MoviesPipelineStream(ctx context.Context, movies []*Short, provider Provider, limit int64) (chan *Short, chan error) {
 	m, err := pipeline.Producer(ctx, movies)
 	if err != nil {
 		mc := make(chan *Short)
@@ -85,32 +325,55 @@ func MoviesPipelineStream(ctx context.Context, movies []*Short, tmdbkey string,
 		close(ec)
 		return mc, ec
 	}
-	mytmdb := TMDBInit(tmdbkey)
-	movie_chan, errors := pipeline.Step(ctx, m, mytmdb.fetchMovieDetails, limit)
+	match := func(ctx context.Context, m *Short) (*Short, error) {
+		ctx, span := tracer.Start(logging.With(ctx, "movie_hash", m.Hash), provider.Name()+".match", trace.WithAttributes(
+			attribute.String("movie.hash", m.Hash),
+			attribute.String("movie.original_title", m.OriginalTitle),
+		))
+		found, err := provider.Match(ctx, m)
+		span.SetAttributes(attribute.Bool("movie.matched", found), attribute.String("movie.id", m.ID))
+		endSpan(span, err)
+		if err != nil {
+			if !IsFatal(err) {
+				slog.WarnContext(ctx, "skipping movie after tmdb failure", "error", err)
+			}
+			return nil, err
+		}
+		return m, nil
+	}
+	movie_chan, errors := pipeline.StepContext(ctx, m, match, limit)
 	return movie_chan, errors
 }
 
-func ChannelToMovies(ctx context.Context, cancelFunc context.CancelFunc, values <-chan *Short, errors <-chan error) ([]*Short, error) {
-	movies := make([]*Short, 0)
-	var firstErr error
+// ChannelToMovies collects the matched movies. A movie whose lookup failed
+// with a non-fatal error is left out and its error returned in skipped
+// (MoviesPipelineStream has logged it with the movie's hash); a fatal error
+// (see IsFatal) cancels the stream and is returned as err.
+func ChannelToMovies(ctx context.Context, cancelFunc context.CancelFunc, values <-chan *Short, errors <-chan error) (movies []*Short, skipped []error, err error) {
+	movies = make([]*Short, 0)
 
 	for values != nil || errors != nil {
 		select {
 		case <-ctx.Done():
-			if firstErr != nil {
-				return movies, firstErr
+			if err != nil {
+				return movies, skipped, err
 			}
-			return movies, ctx.Err()
-		case err, ok := <-errors:
+			return movies, skipped, ctx.Err()
+		case e, ok := <-errors:
 			if !ok {
 				errors = nil
 				continue
 			}
-			if err != nil {
-				cancelFunc()
-				if firstErr == nil {
-					firstErr = err
-				}
+			if e == nil {
+				continue
+			}
+			if !IsFatal(e) {
+				skipped = append(skipped, e)
+				continue
+			}
+			cancelFunc()
+			if err == nil {
+				err = e
 			}
 		case m, ok := <-values:
 			if ok {
@@ -124,5 +387,5 @@ func ChannelToMovies(ctx context.Context, cancelFunc context.CancelFunc, values
 		}
 	}
 
-	return movies, firstErr
+	return movies, skipped, err
 }
@@ -0,0 +1,103 @@
+package movies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+)
+
+const (
+	DefaultCacheTTL     = 24 * time.Hour
+	cacheRequestTimeout = 5 * time.Second
+)
+
+// cachedAPI serves TMDB responses from a cache and stores fresh ones. Cache
+// failures are logged and fall through to the wrapped client.
+type cachedAPI struct {
+	next  api
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func (c *cachedAPI) SearchMovie(ctx context.Context, name string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	key := cacheKey("search", options["language"], options["year"], strings.ToLower(strings.TrimSpace(name)))
+	return cached(ctx, c.cache, c.ttl, key, func() (*tmdb.MovieSearchResults, error) {
+		return c.next.SearchMovie(ctx, name, options)
+	})
+}
+
+func (c *cachedAPI) GetFind(ctx context.Context, id, source string, options map[string]string) (*tmdb.FindResults, error) {
+	key := cacheKey("find", options["language"], source, id)
+	return cached(ctx, c.cache, c.ttl, key, func() (*tmdb.FindResults, error) {
+		return c.next.GetFind(ctx, id, source, options)
+	})
+}
+
+func (c *cachedAPI) GetMovieImages(ctx context.Context, id int, options map[string]string) (*tmdb.MovieImages, error) {
+	key := cacheKey("images", options["language"], fmt.Sprint(id))
+	return cached(ctx, c.cache, c.ttl, key, func() (*tmdb.MovieImages, error) {
+		return c.next.GetMovieImages(ctx, id, options)
+	})
+}
+
+func (c *cachedAPI) GetMovieInfo(ctx context.Context, id int, options map[string]string) (*tmdb.Movie, error) {
+	key := cacheKey("movie", options["language"], options["append_to_response"], fmt.Sprint(id))
+	return cached(ctx, c.cache, c.ttl, key, func() (*tmdb.Movie, error) {
+		return c.next.GetMovieInfo(ctx, id, options)
+	})
+}
+
+func (c *cachedAPI) GetMovieGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error) {
+	return cached(ctx, c.cache, c.ttl, cacheKey("genres", "movie", options["language"]), func() (*tmdb.Genre, error) {
+		return c.next.GetMovieGenres(ctx, options)
+	})
+}
+
+func (c *cachedAPI) GetTvGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error) {
+	return cached(ctx, c.cache, c.ttl, cacheKey("genres", "tv", options["language"]), func() (*tmdb.Genre, error) {
+		return c.next.GetTvGenres(ctx, options)
+	})
+}
+
+func cacheKey(endpoint string, parts ...string) string {
+	return "tmdb:" + endpoint + ":" + strings.Join(parts, ":")
+}
+
+// cached returns the value stored under key or fetches and stores it for ttl.
+// Cache failures are logged and never fail the call.
+func cached[T any](ctx context.Context, c cache.Cache, ttl time.Duration, key string, fetch func() (*T, error)) (*T, error) {
+	cacheCtx, cancel := context.WithTimeout(ctx, cacheRequestTimeout)
+	defer cancel()
+
+	raw, ok, err := c.Get(cacheCtx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "metadata cache read failed", "key", key, "error", err)
+	}
+	if ok {
+		value := new(T)
+		if err := json.Unmarshal(raw, value); err == nil {
+			return value, nil
+		}
+		slog.WarnContext(ctx, "metadata cache entry is corrupt", "key", key)
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	raw, err = json.Marshal(value)
+	if err == nil {
+		err = c.Set(cacheCtx, key, raw, ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "metadata cache write failed", "key", key, "error", err)
+	}
+	return value, nil
+}
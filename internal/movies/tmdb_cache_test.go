@@ -0,0 +1,95 @@
+package movies
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/lieranderl/moviestracker-package/pkg/cache"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
+	"github.com/stretchr/testify/require"
+)
+
+type countingImagesAPI struct {
+	fakeAPI
+	images int
+}
+
+func (f *countingImagesAPI) GetMovieImages(context.Context, int, map[string]string) (*tmdb.MovieImages, error) {
+	f.images++
+	return &tmdb.MovieImages{Backdrops: []tmdb.MovieImage{{FilePath: "/en.jpg"}}}, nil
+}
+
+func TestCachedAPIServesRepeatedSearchFromCache(t *testing.T) {
+	next := &fakeAPI{search: []tmdb.MovieShort{{ID: 7, Title: "Dune"}}}
+	c := cache.NewMemory(10)
+	client := newTestTMDb(next).WithCache(c, time.Hour)
+	options := map[string]string{"language": "ru", "year": "2021"}
+
+	first, err := client.tmdb.SearchMovie(context.Background(), "Dune", options)
+	require.NoError(t, err)
+	second, err := client.tmdb.SearchMovie(context.Background(), " dune ", options)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, next.searched)
+	require.Equal(t, first.Results[0].ID, second.Results[0].ID)
+
+	_, ok, err := c.Get(context.Background(), "tmdb:search:ru:2021:dune")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = client.tmdb.SearchMovie(context.Background(), "Dune", map[string]string{"language": "en", "year": "2021"})
+	require.NoError(t, err)
+	require.Equal(t, 2, next.searched)
+}
+
+type brokenCache struct{}
+
+func (brokenCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (brokenCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func TestCacheFailuresLogWithTheRunContext(t *testing.T) {
+	var out bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(logging.Handler(slog.NewTextHandler(&out, nil))))
+
+	next := &fakeAPI{search: []tmdb.MovieShort{{ID: 7, Title: "Dune"}}}
+	client := newTestTMDb(next).WithCache(brokenCache{}, time.Hour)
+	ctx := logging.With(context.Background(), "run_id", "r1")
+	_, err := client.tmdb.SearchMovie(ctx, "Dune", map[string]string{"language": "ru"})
+
+	require.NoError(t, err, "cache failures never fail the call")
+	require.Regexp(t, `msg="metadata cache read failed" .* run_id=r1\n`, out.String())
+	require.Regexp(t, `msg="metadata cache write failed" .* run_id=r1\n`, out.String())
+}
+
+func TestWithCacheNilKeepsClient(t *testing.T) {
+	next := &fakeAPI{}
+	client := newTestTMDb(next).WithCache(nil, time.Hour)
+
+	require.Same(t, next, client.tmdb)
+}
+
+func TestFillBackdropSkipsImagesWhenBackdropIsSet(t *testing.T) {
+	api := &countingImagesAPI{}
+	client := newTestTMDb(api)
+
+	withBackdrop := &Short{BackdropPath: "/ru.jpg"}
+	client.fillBackdrop(context.Background(), withBackdrop, 1)
+	require.Equal(t, "/ru.jpg", withBackdrop.BackdropPath)
+	require.Zero(t, api.images)
+
+	withoutBackdrop := &Short{}
+	client.fillBackdrop(context.Background(), withoutBackdrop, 1)
+	require.Equal(t, "/en.jpg", withoutBackdrop.BackdropPath)
+	require.Equal(t, 1, api.images)
+}
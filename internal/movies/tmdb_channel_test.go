@@ -3,14 +3,16 @@ package movies
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
-func TestChannelToMoviesCollectsValidMoviesAndReturnsFirstError(t *testing.T) {
+func TestChannelToMoviesSkipsNonFatalErrors(t *testing.T) {
 	ctx := context.Background()
-	cancel := context.CancelFunc(func() {})
+	cancelled := false
+	cancel := context.CancelFunc(func() { cancelled = true })
 
 	values := make(chan *Short, 2)
 	errs := make(chan error, 2)
@@ -19,14 +21,33 @@ func TestChannelToMoviesCollectsValidMoviesAndReturnsFirstError(t *testing.T) {
 	values <- &Short{OriginalTitle: ""}
 	close(values)
 
-	wantErr := errors.New("tmdb failed")
-	errs <- wantErr
+	notFound := &APIError{StatusCode: http.StatusNotFound}
+	errs <- notFound
+	errs <- errors.New("decode failed")
 	close(errs)
 
-	got, err := ChannelToMovies(ctx, cancel, values, errs)
+	got, skipped, err := ChannelToMovies(ctx, cancel, values, errs)
 
-	require.Error(t, err)
-	require.ErrorIs(t, err, wantErr)
+	require.NoError(t, err)
+	require.False(t, cancelled)
+	require.Len(t, skipped, 2)
+	require.ErrorIs(t, skipped[0], notFound)
 	require.Len(t, got, 1)
 	require.Equal(t, "Movie A", got[0].OriginalTitle)
 }
+
+func TestChannelToMoviesStopsOnFatalError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	values := make(chan *Short)
+	errs := make(chan error, 1)
+
+	wantErr := &APIError{StatusCode: http.StatusUnauthorized, Message: "Invalid API key"}
+	errs <- wantErr
+
+	_, skipped, err := ChannelToMovies(ctx, cancel, values, errs)
+
+	require.ErrorIs(t, err, wantErr)
+	require.Empty(t, skipped)
+}
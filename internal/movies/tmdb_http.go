@@ -0,0 +1,177 @@
+package movies
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lieranderl/go-tmdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultBaseURL is the TMDB v3 API root used when no base URL is configured.
+const DefaultBaseURL = "https://api.themoviedb.org/3"
+
+const httpAPITimeout = 20 * time.Second
+
+// httpAPI talks to a TMDB compatible server at baseURL and decodes responses
+// into the go-tmdb types. Unlike the go-tmdb client it can target another base
+// URL, such as the local stand-in from internal/tmdbfake, and reports the HTTP
+// status and Retry-After header of failed requests as *APIError.
+type httpAPI struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func newHTTPAPI(baseURL, apiKey string) *httpAPI {
+	return &httpAPI{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: httpAPITimeout},
+	}
+}
+
+// WithBaseURL sends every TMDB request to baseURL instead of the public API.
+// It replaces the underlying client, so call it before WithCache. An empty
+// baseURL leaves the client unchanged.
+func (tmdbapi *TMDb) WithBaseURL(baseURL string) *TMDb {
+	if baseURL == "" {
+		return tmdbapi
+	}
+	tmdbapi.tmdb = tmdbapi.newClient(baseURL)
+	return tmdbapi
+}
+
+func (c *httpAPI) SearchMovie(ctx context.Context, name string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	var r tmdb.MovieSearchResults
+	query := pick(options, "page", "language", "include_adult", "year", "primary_release_year")
+	query.Set("query", name)
+	return &r, c.get(ctx, "/search/movie", query, &r)
+}
+
+func (c *httpAPI) GetFind(ctx context.Context, id, source string, options map[string]string) (*tmdb.FindResults, error) {
+	var r tmdb.FindResults
+	query := pick(options, "language")
+	query.Set("external_source", source)
+	return &r, c.get(ctx, "/find/"+url.PathEscape(id), query, &r)
+}
+
+func (c *httpAPI) GetMovieImages(ctx context.Context, id int, options map[string]string) (*tmdb.MovieImages, error) {
+	var r tmdb.MovieImages
+	return &r, c.get(ctx, "/movie/"+strconv.Itoa(id)+"/images", pick(options, "language", "include_image_language"), &r)
+}
+
+func (c *httpAPI) GetMovieInfo(ctx context.Context, id int, options map[string]string) (*tmdb.Movie, error) {
+	var r tmdb.Movie
+	return &r, c.get(ctx, "/movie/"+strconv.Itoa(id), pick(options, "language", "append_to_response"), &r)
+}
+
+func (c *httpAPI) GetMovieGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error) {
+	var r tmdb.Genre
+	return &r, c.get(ctx, "/genre/movie/list", pick(options, "language"), &r)
+}
+
+func (c *httpAPI) GetTvGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error) {
+	var r tmdb.Genre
+	return &r, c.get(ctx, "/genre/tv/list", pick(options, "language"), &r)
+}
+
+// APIError is a non-2xx response of TMDB or another metadata API.
+type APIError struct {
+	Path       string
+	StatusCode int
+	// Code and Message come from the error body, e.g. TMDB's 7 "Invalid API key".
+	Code       int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GET %s: status %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+func (c *httpAPI) get(ctx context.Context, path string, query url.Values, out any) error {
+	query.Set("api_key", c.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("tmdb GET %s: %w", path, err)
+	}
+	return getJSON(c.client, req, "tmdb", path, out)
+}
+
+// getJSON runs req in a "<service>.request" client span and decodes a 2xx body
+// into out. Other statuses become an *APIError carrying TMDB's status_message,
+// Kinopoisk's message or OMDb's Error. The span leaves out the query, which
+// holds the API key of TMDB and OMDb.
+func getJSON(client *http.Client, req *http.Request, service, path string, out any) (err error) {
+	ctx, span := tracer.Start(req.Context(), service+".request", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.String("url.path", req.URL.Path),
+	))
+	defer func() { endSpan(span, err) }()
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var status struct {
+			StatusCode    int    `json:"status_code"`
+			StatusMessage string `json:"status_message"`
+			Message       string `json:"message"`
+			Error         string `json:"Error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&status)
+		return &APIError{
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Code:       status.StatusCode,
+			Message:    cmp.Or(status.StatusMessage, status.Message, status.Error),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("GET %s: decode: %w", path, err)
+	}
+	return nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. Missing or malformed values return 0.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// pick copies the supported options into query parameters, mirroring the
+// allow-lists go-tmdb applies.
+func pick(options map[string]string, keys ...string) url.Values {
+	query := url.Values{}
+	for _, key := range keys {
+		if v, ok := options[key]; ok {
+			query.Set(key, v)
+		}
+	}
+	return query
+}
@@ -0,0 +1,58 @@
+package movies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lieranderl/moviestracker-package/internal/tmdbfake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoviesPipelineStreamAgainstFakeServer(t *testing.T) {
+	srv, h := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	client := TMDBInit("key").WithBaseURL(srv.URL).WithLanguages("ru", "en")
+	input := []*Short{
+		{Searchname: "Bad Boys: Ride or Die", Year: 2024, ImdbID: "tt4919268"},
+		{Searchname: "Dune: Part Two", Year: 2024},
+		{Searchname: "Unknown Movie", Year: 2024},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	values, errs := MoviesPipelineStream(ctx, input, client, 2)
+	got, skipped, err := ChannelToMovies(ctx, cancel, values, errs)
+
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+	require.Len(t, got, 2)
+	byID := make(map[string]*Short, len(got))
+	for _, m := range got {
+		byID[m.ID] = m
+	}
+
+	badBoys := byID["573435"]
+	require.NotNil(t, badBoys)
+	require.Equal(t, MatchByIMDbID, badBoys.MatchStrategy)
+	require.Equal(t, "/en-bad-boys-4-backdrop.jpg", badBoys.BackdropPath)
+	require.Equal(t, "Bad Boys: Ride or Die", badBoys.Titles["en"])
+
+	dune := byID["693134"]
+	require.NotNil(t, dune)
+	require.Equal(t, MatchBySearch, dune.MatchStrategy)
+	require.Equal(t, 8.2, dune.VoteAverage)
+
+	require.Equal(t, 1, h.Hits("/find/tt4919268"))
+}
+
+func TestHTTPAPIReturnsStatusErrors(t *testing.T) {
+	srv, _ := tmdbfake.NewServer(tmdbfake.DefaultFixtures(), "key")
+	defer srv.Close()
+
+	_, err := newHTTPAPI(srv.URL, "wrong").GetMovieInfo(context.Background(), 573435, nil)
+	require.ErrorContains(t, err, "status 401")
+
+	_, err = newHTTPAPI(srv.URL, "key").GetMovieInfo(context.Background(), 1, nil)
+	require.ErrorContains(t, err, "status 404")
+}
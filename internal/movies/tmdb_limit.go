@@ -0,0 +1,202 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lieranderl/go-tmdb"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultRateLimit keeps the enrichment workers below TMDB's per-IP limit
+	// of roughly 50 requests per second.
+	DefaultRateLimit = 20
+	defaultRateBurst = 5
+
+	defaultMaxRetries = 3
+	defaultRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = time.Minute
+)
+
+// IsRetryable reports whether a failed request is worth repeating: rate
+// limiting (429), server errors (5xx) and network failures.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsFatal reports whether err will fail every following request to the same
+// service as well, i.e. a rejected API key, an exhausted quota or a cancelled
+// run, so enrichment should stop rather than skip the movie. Requests that
+// only ran into httpAPITimeout are not fatal, see retry.
+func IsFatal(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden:
+			return true
+		}
+	}
+	return false
+}
+
+// requestLimiter spaces requests with a limiter shared by all workers and
+// retries retryable failures. A 429 with Retry-After pauses every worker, not
+// only the one that got it.
+type requestLimiter struct {
+	limiter    *rate.Limiter
+	maxRetries int
+	retryDelay time.Duration
+
+	mu       sync.Mutex
+	resumeAt time.Time
+}
+
+func newRequestLimiter(limiter *rate.Limiter) *requestLimiter {
+	return &requestLimiter{limiter: limiter, maxRetries: defaultMaxRetries, retryDelay: defaultRetryDelay}
+}
+
+// limitedAPI runs every TMDB call through a requestLimiter.
+type limitedAPI struct {
+	next   api
+	limits *requestLimiter
+}
+
+func (tmdbapi *TMDb) newClient(baseURL string) api {
+	return &limitedAPI{next: newHTTPAPI(baseURL, tmdbapi.apiKey), limits: newRequestLimiter(tmdbapi.limiter)}
+}
+
+// WithRateLimit caps TMDB requests at perSecond across all workers. Values
+// <= 0 keep the current limit.
+func (tmdbapi *TMDb) WithRateLimit(perSecond float64) *TMDb {
+	if perSecond > 0 && tmdbapi.limiter != nil {
+		tmdbapi.limiter.SetLimit(rate.Limit(perSecond))
+		tmdbapi.limiter.SetBurst(max(defaultRateBurst, int(perSecond/4)))
+	}
+	return tmdbapi
+}
+
+func (l *limitedAPI) SearchMovie(ctx context.Context, name string, options map[string]string) (*tmdb.MovieSearchResults, error) {
+	return retry(ctx, l.limits, func() (*tmdb.MovieSearchResults, error) { return l.next.SearchMovie(ctx, name, options) })
+}
+
+func (l *limitedAPI) GetFind(ctx context.Context, id, source string, options map[string]string) (*tmdb.FindResults, error) {
+	return retry(ctx, l.limits, func() (*tmdb.FindResults, error) { return l.next.GetFind(ctx, id, source, options) })
+}
+
+func (l *limitedAPI) GetMovieImages(ctx context.Context, id int, options map[string]string) (*tmdb.MovieImages, error) {
+	return retry(ctx, l.limits, func() (*tmdb.MovieImages, error) { return l.next.GetMovieImages(ctx, id, options) })
+}
+
+func (l *limitedAPI) GetMovieInfo(ctx context.Context, id int, options map[string]string) (*tmdb.Movie, error) {
+	return retry(ctx, l.limits, func() (*tmdb.Movie, error) { return l.next.GetMovieInfo(ctx, id, options) })
+}
+
+func (l *limitedAPI) GetMovieGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error) {
+	return retry(ctx, l.limits, func() (*tmdb.Genre, error) { return l.next.GetMovieGenres(ctx, options) })
+}
+
+func (l *limitedAPI) GetTvGenres(ctx context.Context, options map[string]string) (*tmdb.Genre, error) {
+	return retry(ctx, l.limits, func() (*tmdb.Genre, error) { return l.next.GetTvGenres(ctx, options) })
+}
+
+// retry runs call until it succeeds, fails for good or ctx is done. The waits
+// for a limiter slot and between attempts end early with ctx's error, and a
+// call that fails once ctx is done returns ctx's error too. Other context
+// errors come from the client's own timeout and are retried as
+// *requestTimeout.
+func retry[T any](ctx context.Context, l *requestLimiter, call func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		value, err := call()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				var zero T
+				return zero, fmt.Errorf("%w: %w", ctxErr, err)
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = &requestTimeout{err: err}
+			}
+		}
+		if err == nil || !IsRetryable(err) || attempt == l.maxRetries {
+			return value, err
+		}
+
+		delay := min(l.retryDelay<<attempt, maxRetryDelay)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			delay = min(apiErr.RetryAfter, maxRetryDelay)
+			l.pause(delay)
+		}
+		slog.DebugContext(ctx, "retrying request", "attempt", attempt+1, "delay", delay.String(), "error", err)
+		if err := sleep(ctx, delay); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+}
+
+// requestTimeout is a request that ran into httpAPITimeout while its caller
+// was still running. http.Client reports that as context.DeadlineExceeded,
+// which IsFatal would take for a cancelled run; requestTimeout hides it and
+// is a retryable net.Error instead.
+type requestTimeout struct{ err error }
+
+func (e *requestTimeout) Error() string   { return e.err.Error() }
+func (e *requestTimeout) Timeout() bool   { return true }
+func (e *requestTimeout) Temporary() bool { return true }
+
+// wait blocks while a Retry-After pause is active and then for a limiter slot,
+// or until ctx is done.
+func (l *requestLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	pause := time.Until(l.resumeAt)
+	l.mu.Unlock()
+	if err := sleep(ctx, pause); err != nil {
+		return err
+	}
+	if l.limiter != nil {
+		return l.limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// sleep waits for d or until ctx is done, returning ctx's error in that case.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *requestLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if resumeAt := time.Now().Add(d); resumeAt.After(l.resumeAt) {
+		l.resumeAt = resumeAt
+	}
+}
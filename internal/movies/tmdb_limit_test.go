@@ -0,0 +1,189 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tooMany := fmt.Errorf("tmdb search: %w", &APIError{StatusCode: http.StatusTooManyRequests})
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+	unauthorized := &APIError{StatusCode: http.StatusUnauthorized}
+	notFound := &APIError{StatusCode: http.StatusNotFound}
+
+	require.True(t, IsRetryable(tooMany))
+	require.True(t, IsRetryable(unavailable))
+	require.False(t, IsRetryable(unauthorized))
+	require.False(t, IsRetryable(notFound))
+
+	require.True(t, IsFatal(unauthorized))
+	require.True(t, IsFatal(fmt.Errorf("step: %w", context.Canceled)))
+	require.False(t, IsFatal(tooMany))
+	require.False(t, IsFatal(notFound))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	require.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	require.Equal(t, 10*time.Second, parseRetryAfter("Sat, 01 Jun 2024 12:00:10 GMT", now))
+	require.Zero(t, parseRetryAfter("Sat, 01 Jun 2024 11:00:00 GMT", now))
+	require.Zero(t, parseRetryAfter("soon", now))
+	require.Zero(t, parseRetryAfter("", now))
+}
+
+func newTestLimitedAPI(baseURL string, maxRetries int) *limitedAPI {
+	limits := newRequestLimiter(rate.NewLimiter(rate.Inf, 1))
+	limits.maxRetries = maxRetries
+	limits.retryDelay = time.Millisecond
+	return &limitedAPI{next: newHTTPAPI(baseURL, "key"), limits: limits}
+}
+
+func TestLimitedAPIRetriesRateLimitedRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"status_code":25,"status_message":"Your request count is over the allowed limit."}`)
+			return
+		}
+		fmt.Fprint(w, `{"genres":[{"id":28,"name":"Action"}]}`)
+	}))
+	defer srv.Close()
+
+	genres, err := newTestLimitedAPI(srv.URL, 3).GetMovieGenres(context.Background(), map[string]string{"language": "en"})
+
+	require.NoError(t, err)
+	require.Len(t, genres.Genres, 1)
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestLimitedAPIGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := newTestLimitedAPI(srv.URL, 2).GetMovieInfo(context.Background(), 1, nil)
+
+	require.ErrorContains(t, err, "status 502")
+	require.True(t, IsRetryable(err))
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestLimitedAPIDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := newTestLimitedAPI(srv.URL, 3).GetMovieInfo(context.Background(), 1, nil)
+
+	require.Error(t, err)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestLimitedAPIPausesAllWorkersOnRetryAfter(t *testing.T) {
+	l := newTestLimitedAPI("http://unused", 0).limits
+	l.pause(50 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, l.wait(context.Background()))
+
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestLimitedAPIStopsWaitingWhenCancelled(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := newTestLimitedAPI(srv.URL, 3).GetMovieInfo(ctx, 1, nil)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, IsFatal(err))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.EqualValues(t, 1, calls.Load())
+
+	l := newTestLimitedAPI("http://unused", 0).limits
+	l.pause(time.Minute)
+	require.ErrorIs(t, l.wait(ctx), context.DeadlineExceeded)
+}
+
+// newSlowTMDBStandIn answers searches for "Slow" after the client timeout of
+// newTimingOutAPI and every other request at once.
+func newSlowTMDBStandIn(t *testing.T, searches *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/search/") {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		if strings.HasPrefix(r.URL.Query().Get("query"), "Slow") {
+			searches.Add(1)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		fmt.Fprint(w, `{"results":[{"id":438631,"title":"Dune","original_title":"Dune","release_date":"2021-09-15"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTimingOutAPI(baseURL string, maxRetries int) *limitedAPI {
+	l := newTestLimitedAPI(baseURL, maxRetries)
+	l.next.(*httpAPI).client.Timeout = 20 * time.Millisecond
+	return l
+}
+
+func TestLimitedAPIRetriesClientTimeouts(t *testing.T) {
+	var searches atomic.Int32
+	srv := newSlowTMDBStandIn(t, &searches)
+
+	_, err := newTimingOutAPI(srv.URL, 2).SearchMovie(context.Background(), "Slow Movie", nil)
+
+	require.ErrorContains(t, err, "Client.Timeout")
+	require.True(t, IsRetryable(err))
+	require.False(t, IsFatal(err), "a slow response does not stop the run")
+	require.EqualValues(t, 3, searches.Load())
+}
+
+func TestSlowLookupSkipsOnlyItsMovie(t *testing.T) {
+	var searches atomic.Int32
+	srv := newSlowTMDBStandIn(t, &searches)
+	tmdbapi := newTestTMDb(newTimingOutAPI(srv.URL, 1))
+	found := []*Short{{Hash: "slow", Searchname: "Slow Movie", Year: 2021}, {Hash: "dune", Searchname: "Dune", Year: 2021}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	movieChan, errorChan := MoviesPipelineStream(ctx, found, tmdbapi, 2)
+	matched, skipped, err := ChannelToMovies(ctx, cancel, movieChan, errorChan)
+
+	require.NoError(t, err)
+	require.Len(t, skipped, 1)
+	require.Len(t, matched, 1)
+	require.Equal(t, "438631", matched[0].ID)
+}
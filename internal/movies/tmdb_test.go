@@ -0,0 +1,168 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lieranderl/go-tmdb"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAPI struct {
+	search   []tmdb.MovieShort
+	find     []tmdb.MovieShort
+	findErr  error
+	info     map[string]tmdb.Movie
+	genres   map[string]*tmdb.Genre
+	searched int
+	found    int
+}
+
+func (f *fakeAPI) SearchMovie(context.Context, string, map[string]string) (*tmdb.MovieSearchResults, error) {
+	f.searched++
+	return &tmdb.MovieSearchResults{Results: f.search}, nil
+}
+
+func (f *fakeAPI) GetFind(context.Context, string, string, map[string]string) (*tmdb.FindResults, error) {
+	f.found++
+	if f.findErr != nil {
+		return nil, f.findErr
+	}
+	return &tmdb.FindResults{MovieResults: f.find}, nil
+}
+
+func (f *fakeAPI) GetMovieImages(context.Context, int, map[string]string) (*tmdb.MovieImages, error) {
+	return &tmdb.MovieImages{}, nil
+}
+
+func (f *fakeAPI) GetMovieInfo(_ context.Context, id int, options map[string]string) (*tmdb.Movie, error) {
+	if f.info == nil {
+		return nil, errors.New("no movie info")
+	}
+	movie, ok := f.info[options["language"]]
+	if !ok {
+		return nil, errors.New("unknown language")
+	}
+	movie.ID = id
+	return &movie, nil
+}
+
+func (f *fakeAPI) GetMovieGenres(_ context.Context, options map[string]string) (*tmdb.Genre, error) {
+	return f.genreList("movie", options)
+}
+
+func (f *fakeAPI) GetTvGenres(_ context.Context, options map[string]string) (*tmdb.Genre, error) {
+	return f.genreList("tv", options)
+}
+
+func (f *fakeAPI) genreList(media string, options map[string]string) (*tmdb.Genre, error) {
+	list, ok := f.genres[media+":"+options["language"]]
+	if !ok {
+		return nil, errors.New("no genres")
+	}
+	return list, nil
+}
+
+func newTestTMDb(a api) *TMDb {
+	return &TMDb{tmdb: a, languages: []string{defaultLanguage}}
+}
+
+func TestFetchMovieDetailsPrefersIMDbID(t *testing.T) {
+	api := &fakeAPI{
+		find:   []tmdb.MovieShort{{ID: 573435, OriginalTitle: "Bad Boys: Ride or Die", Title: "Плохие парни до конца"}},
+		search: []tmdb.MovieShort{{ID: 1, OriginalTitle: "Other"}},
+	}
+	m := &Short{Searchname: "Плохие парни 4", Year: 2024, ImdbID: "tt4919268"}
+
+	got, err := newTestTMDb(api).fetchMovieDetails(context.Background(), m)
+
+	require.NoError(t, err)
+	require.Equal(t, "573435", got.ID)
+	require.Equal(t, MatchByIMDbID, got.MatchStrategy)
+	require.Equal(t, 1, api.found)
+	require.Zero(t, api.searched)
+}
+
+func TestFetchMovieDetailsFallsBackToSearch(t *testing.T) {
+	api := &fakeAPI{
+		search: []tmdb.MovieShort{{ID: 38700, OriginalTitle: "Bad Boys for Life", ReleaseDate: "2020-01-15"}},
+	}
+	m := &Short{Searchname: "Bad Boys for Life", Year: 2020, ImdbID: "tt1502397"}
+
+	got, err := newTestTMDb(api).fetchMovieDetails(context.Background(), m)
+
+	require.NoError(t, err)
+	require.Equal(t, "38700", got.ID)
+	require.Equal(t, MatchBySearch, got.MatchStrategy)
+	require.Equal(t, 1, api.found)
+	require.Equal(t, 1, api.searched)
+}
+
+func TestFetchMovieDetailsReturnsFindError(t *testing.T) {
+	api := &fakeAPI{findErr: errors.New("boom")}
+
+	_, err := newTestTMDb(api).fetchMovieDetails(context.Background(), &Short{ImdbID: "tt4919268"})
+
+	require.Error(t, err)
+	require.Zero(t, api.searched)
+}
+
+func TestFetchMovieDetailsFlagsLowConfidenceMatch(t *testing.T) {
+	api := &fakeAPI{
+		search: []tmdb.MovieShort{{ID: 8961, OriginalTitle: "Bad Boys II", ReleaseDate: "2003-07-09"}},
+	}
+	m := &Short{Searchname: "Bad Boys", Year: 2004}
+
+	got, err := newTestTMDb(api).fetchMovieDetails(context.Background(), m)
+
+	require.NoError(t, err)
+	require.Equal(t, "8961", got.ID)
+	require.True(t, got.LowConfidence)
+	require.Less(t, got.MatchConfidence, LowMatchConfidence)
+	require.Equal(t, 2, api.searched)
+}
+
+func TestFetchMovieDetailsRejectsTitleFromAnotherYear(t *testing.T) {
+	api := &fakeAPI{
+		search: []tmdb.MovieShort{{ID: 9737, OriginalTitle: "Bad Boys", ReleaseDate: "1995-04-07"}},
+	}
+	m := &Short{Searchname: "Bad Boys", Year: 2024}
+
+	got, err := newTestTMDb(api).fetchMovieDetails(context.Background(), m)
+
+	require.NoError(t, err)
+	require.Empty(t, got.ID, "the 1995 film is not the 2024 release")
+	require.Equal(t, 2, api.searched)
+}
+
+func TestFetchMovieDetailsDropsUnrelatedResults(t *testing.T) {
+	api := &fakeAPI{
+		search: []tmdb.MovieShort{{ID: 1, OriginalTitle: "Something Else", ReleaseDate: "2024-01-01"}},
+	}
+	m := &Short{Searchname: "Bad Boys", Year: 2024}
+
+	got, err := newTestTMDb(api).fetchMovieDetails(context.Background(), m)
+
+	require.NoError(t, err)
+	require.Empty(t, got.ID)
+	require.Empty(t, got.OriginalTitle)
+}
+
+func TestFetchMovieDetailsStoresConfiguredLanguages(t *testing.T) {
+	api := &fakeAPI{
+		find: []tmdb.MovieShort{{ID: 693134, OriginalTitle: "Dune: Part Two", Title: "Дюна: Часть вторая", Overview: "ru overview", PosterPath: "/ru.jpg"}},
+		info: map[string]tmdb.Movie{
+			"en": {Title: "Dune: Part Two", Overview: "en overview", PosterPath: "/en.jpg"},
+		},
+	}
+	client := newTestTMDb(api).WithLanguages("RU", "en", "uk", "ru")
+
+	got, err := client.fetchMovieDetails(context.Background(), &Short{ImdbID: "tt15239678"})
+
+	require.NoError(t, err)
+	require.Equal(t, "Дюна: Часть вторая", got.Title)
+	require.Equal(t, map[string]string{"ru": "Дюна: Часть вторая", "en": "Dune: Part Two"}, got.Titles)
+	require.Equal(t, map[string]string{"ru": "ru overview", "en": "en overview"}, got.Overviews)
+	require.Equal(t, map[string]string{"ru": "/ru.jpg", "en": "/en.jpg"}, got.Posters)
+}
@@ -0,0 +1,28 @@
+// Package notify delivers watch events to outside services.
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+)
+
+// Notifier sends the events of one watch run. Implementations retry transient
+// failures themselves; a returned error means some events were not delivered.
+type Notifier interface {
+	Notify(ctx context.Context, events []watch.Event) error
+}
+
+// Multi sends every event through each of its notifiers.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, events []watch.Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, events); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
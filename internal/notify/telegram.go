@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+
+	"github.com/lieranderl/moviestracker-package/internal/telegram"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+)
+
+// TelegramTemplate is the headline of Telegram messages; the release itself
+// (name, quality badges, seeds and magnet) is always appended.
+const TelegramTemplate = `{{if eq .Type "new_release"}}New release{{else if eq .Type "better_quality"}}Better quality{{else}}Seeds changed ({{.PreviousSeeds}} → {{.Torrent.Seeds}}){{end}} for {{.Query}}`
+
+// Telegram sends every event to each chat, with the release poster when the
+// torrent has one.
+type Telegram struct {
+	client   *telegram.Client
+	chatIDs  []int64
+	template *Template
+}
+
+func NewTelegram(client *telegram.Client, chatIDs ...int64) *Telegram {
+	return &Telegram{client: client, chatIDs: chatIDs, template: mustParseTemplate(TelegramTemplate)}
+}
+
+// WithTemplate replaces TelegramTemplate.
+func (n *Telegram) WithTemplate(t *Template) *Telegram {
+	n.template = t
+	return n
+}
+
+func (n *Telegram) Notify(ctx context.Context, events []watch.Event) error {
+	var errs []error
+	for _, e := range events {
+		headline, err := n.template.Render(e)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		text := html.EscapeString(headline) + "\n\n" + telegram.FormatTorrent(e.Torrent)
+
+		for _, chatID := range n.chatIDs {
+			if e.Torrent.Poster != "" && len([]rune(text)) <= telegram.MaxCaptionLength {
+				err = n.client.SendPhoto(ctx, chatID, e.Torrent.Poster, text)
+			} else {
+				err = n.client.SendMessage(ctx, chatID, text)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return errors.Join(append(errs, err)...)
+				}
+				errs = append(errs, fmt.Errorf("telegram %s for %q: %w", e.Type, e.Torrent.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
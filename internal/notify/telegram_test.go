@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lieranderl/moviestracker-package/internal/telegram"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegramSendsPosterWithCaption(t *testing.T) {
+	type request struct {
+		method string
+		params map[string]any
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		requests = append(requests, request{method: strings.TrimPrefix(r.URL.Path, "/bottoken/"), params: params})
+		fmt.Fprint(w, `{"ok":true,"result":{}}`)
+	}))
+	defer srv.Close()
+
+	withPoster := testEvent()
+	withPoster.Torrent.Poster = "https://example.org/poster.jpg"
+	withoutPoster := testEvent()
+	withoutPoster.Type = watch.EventBetterQuality
+
+	n := NewTelegram(telegram.New("token").WithBaseURL(srv.URL), 1, 2)
+	require.NoError(t, n.Notify(context.Background(), []watch.Event{withPoster, withoutPoster}))
+
+	require.Len(t, requests, 4)
+	require.Equal(t, "sendPhoto", requests[0].method)
+	require.Equal(t, "https://example.org/poster.jpg", requests[0].params["photo"])
+	require.EqualValues(t, 2, requests[1].params["chat_id"])
+	caption := requests[0].params["caption"].(string)
+	require.True(t, strings.HasPrefix(caption, "New release for Bad Boys 2024\n\n<b>Bad Boys: Ride or Die 2160p</b>"), caption)
+	require.Contains(t, caption, "2160p · DV · 42 seeds")
+	require.Contains(t, caption, "magnet:?xt=urn:btih:abc")
+
+	require.Equal(t, "sendMessage", requests[2].method)
+	require.True(t, strings.HasPrefix(requests[2].params["text"].(string), "Better quality for Bad Boys 2024"))
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+)
+
+// DefaultTemplate renders events like
+// "new_release: Bad Boys 2160p (2160p dv, 42 seeds) for "Bad Boys 2024"".
+const DefaultTemplate = `{{.Type}}: {{.Torrent.Name}} ({{resolution .Torrent}}{{with hdr .Torrent}} {{.}}{{end}}, {{.Torrent.Seeds}} seeds` +
+	`{{if eq .Type "seeds_changed"}}, was {{.PreviousSeeds}}{{end}}) for "{{.Query}}"`
+
+// Template renders the message text of an event. Templates use text/template
+// with a watch.Event as data and two helpers: resolution ("2160p", "1080p",
+// "sd") and hdr (space separated formats, empty for SDR).
+type Template struct {
+	tmpl *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"resolution": (*torrents.Torrent).Resolution,
+	"hdr": func(t *torrents.Torrent) string {
+		formats := t.HDRFormats()
+		if len(formats) == 1 && formats[0] == "sdr" {
+			return ""
+		}
+		return strings.Join(formats, " ")
+	},
+}
+
+// ParseTemplate compiles text; empty text uses DefaultTemplate.
+func ParseTemplate(text string) (*Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("notification").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse notification template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+func mustParseTemplate(text string) *Template {
+	t, err := ParseTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (t *Template) Render(e watch.Event) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, e); err != nil {
+		return "", fmt.Errorf("render notification: %w", err)
+	}
+	return b.String(), nil
+}
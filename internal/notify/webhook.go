@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+)
+
+const (
+	// SignatureHeader carries "sha256=<hex HMAC of the body>" when the webhook
+	// has a secret.
+	SignatureHeader = "X-Moviestracker-Signature"
+	EventHeader     = "X-Moviestracker-Event"
+	// DeliveryHeader identifies an event across retries, so receivers can drop
+	// duplicates.
+	DeliveryHeader = "X-Moviestracker-Delivery"
+
+	defaultWebhookAttempts = 4
+	defaultWebhookDelay    = time.Second
+	webhookTimeout         = 10 * time.Second
+)
+
+// Payload is the JSON body of a webhook request.
+type Payload struct {
+	Event           watch.EventType `json:"event"`
+	Query           string          `json:"query"`
+	Text            string          `json:"text"`
+	Torrent         TorrentPayload  `json:"torrent"`
+	PreviousQuality int             `json:"previous_quality,omitempty"`
+	PreviousSeeds   int32           `json:"previous_seeds,omitempty"`
+	At              time.Time       `json:"at"`
+}
+
+type TorrentPayload struct {
+	Name       string   `json:"name"`
+	MovieHash  string   `json:"movie_hash"`
+	MagnetHash string   `json:"magnet_hash"`
+	Magnet     string   `json:"magnet,omitempty"`
+	DetailsURL string   `json:"details_url,omitempty"`
+	Year       string   `json:"year,omitempty"`
+	Size       float32  `json:"size"`
+	Seeds      int32    `json:"seeds"`
+	Leeches    int32    `json:"leeches"`
+	Resolution string   `json:"resolution"`
+	HDR        []string `json:"hdr"`
+	Quality    int      `json:"quality"`
+}
+
+// Webhook posts every event as a JSON Payload to a URL.
+type Webhook struct {
+	url        string
+	secret     string
+	template   *Template
+	client     *http.Client
+	attempts   int
+	retryDelay time.Duration
+}
+
+// NewWebhook posts to url and signs bodies with secret when it is not empty.
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		url:        url,
+		secret:     secret,
+		template:   mustParseTemplate(DefaultTemplate),
+		client:     &http.Client{Timeout: webhookTimeout},
+		attempts:   defaultWebhookAttempts,
+		retryDelay: defaultWebhookDelay,
+	}
+}
+
+// WithTemplate sets the template of the payload's text field.
+func (w *Webhook) WithTemplate(t *Template) *Webhook {
+	w.template = t
+	return w
+}
+
+// WithRetries sets how often a delivery is attempted and the delay before the
+// first retry, which doubles after every attempt.
+func (w *Webhook) WithRetries(attempts int, delay time.Duration) *Webhook {
+	w.attempts = max(attempts, 1)
+	w.retryDelay = delay
+	return w
+}
+
+// Notify posts the events one by one and returns the failed deliveries.
+func (w *Webhook) Notify(ctx context.Context, events []watch.Event) error {
+	var errs []error
+	for _, e := range events {
+		if err := w.send(ctx, e); err != nil {
+			if ctx.Err() != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Webhook) send(ctx context.Context, e watch.Event) error {
+	text, err := w.template.Render(e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(newPayload(e, text))
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	delivery := deliveryID(e)
+
+	delay := w.retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, e, delivery, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.attempts {
+			return fmt.Errorf("webhook %s for %q: %w", e.Type, e.Torrent.Name, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// post reports whether a failed delivery is worth retrying: network errors,
+// 429 and 5xx responses are.
+func (w *Webhook) post(ctx context.Context, e watch.Event, delivery string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(e.Type))
+	req.Header.Set(DeliveryHeader, delivery)
+	if w.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a SignatureHeader value in constant time.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func deliveryID(e watch.Event) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%s|%d", e.Query, e.Torrent.MagnetHash, e.Type, e.At.UnixNano()))
+	return hex.EncodeToString(sum[:16])
+}
+
+func newPayload(e watch.Event, text string) Payload {
+	t := e.Torrent
+	return Payload{
+		Event: e.Type,
+		Query: e.Query,
+		Text:  text,
+		Torrent: TorrentPayload{
+			Name:       t.Name,
+			MovieHash:  t.Hash,
+			MagnetHash: t.MagnetHash,
+			Magnet:     t.Magnet,
+			DetailsURL: t.DetailsUrl,
+			Year:       t.Year,
+			Size:       t.Size,
+			Seeds:      t.Seeds,
+			Leeches:    t.Leeches,
+			Resolution: t.Resolution(),
+			HDR:        t.HDRFormats(),
+			Quality:    t.QualityRank(),
+		},
+		PreviousQuality: e.PreviousQuality,
+		PreviousSeeds:   e.PreviousSeeds,
+		At:              e.At,
+	}
+}
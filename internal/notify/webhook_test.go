@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/internal/watch"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local webhook endpoint that answers with the queued statuses
+// (then 204) and records what it received.
+type receiver struct {
+	mu         sync.Mutex
+	statuses   []int
+	bodies     [][]byte
+	signatures []string
+	deliveries []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.signatures = append(r.signatures, req.Header.Get(SignatureHeader))
+	r.deliveries = append(r.deliveries, req.Header.Get(DeliveryHeader))
+
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func testEvent() watch.Event {
+	return watch.Event{
+		Type:  watch.EventNewRelease,
+		Query: "Bad Boys 2024",
+		Torrent: &torrents.Torrent{
+			Name:       "Bad Boys: Ride or Die 2160p",
+			Hash:       "bad boys 2024",
+			MagnetHash: "abc",
+			Magnet:     "magnet:?xt=urn:btih:abc",
+			K4:         true,
+			DV:         true,
+			Seeds:      42,
+		},
+		At: time.Date(2024, 6, 7, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWebhookSignsPayload(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	err := NewWebhook(srv.URL, "s3cret").Notify(context.Background(), []watch.Event{testEvent()})
+	require.NoError(t, err)
+
+	require.Len(t, rcv.bodies, 1)
+	require.True(t, Verify("s3cret", rcv.bodies[0], rcv.signatures[0]))
+	require.False(t, Verify("other", rcv.bodies[0], rcv.signatures[0]))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(rcv.bodies[0], &payload))
+	require.Equal(t, watch.EventNewRelease, payload.Event)
+	require.Equal(t, "abc", payload.Torrent.MagnetHash)
+	require.Equal(t, "2160p", payload.Torrent.Resolution)
+	require.Equal(t, []string{"dv"}, payload.Torrent.HDR)
+	require.Equal(t, `new_release: Bad Boys: Ride or Die 2160p (2160p dv, 42 seeds) for "Bad Boys 2024"`, payload.Text)
+}
+
+func TestWebhookRetriesTransientFailures(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	err := NewWebhook(srv.URL, "").WithRetries(3, time.Millisecond).Notify(context.Background(), []watch.Event{testEvent()})
+	require.NoError(t, err)
+	require.Len(t, rcv.bodies, 3)
+	require.Empty(t, rcv.signatures[0])
+	require.Equal(t, rcv.deliveries[0], rcv.deliveries[2], "retries keep the delivery id")
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusBadRequest}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL, "").WithRetries(2, time.Millisecond)
+	err := hook.Notify(context.Background(), []watch.Event{testEvent(), testEvent()})
+	require.ErrorContains(t, err, "status 500")
+	require.ErrorContains(t, err, "status 400")
+	require.Len(t, rcv.bodies, 3, "client errors are not retried")
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{.Torrent.Name}} [{{resolution .Torrent}}|{{hdr .Torrent}}]`)
+	require.NoError(t, err)
+	text, err := tmpl.Render(testEvent())
+	require.NoError(t, err)
+	require.Equal(t, "Bad Boys: Ride or Die 2160p [2160p|dv]", text)
+
+	e := testEvent()
+	e.Type = watch.EventSeedsChanged
+	e.PreviousSeeds = 12
+	e.Torrent.K4, e.Torrent.DV = false, false
+	text, err = mustParseTemplate("").Render(e)
+	require.NoError(t, err)
+	require.Equal(t, `seeds_changed: Bad Boys: Ride or Die 2160p (sd, 42 seeds, was 12) for "Bad Boys 2024"`, text)
+
+	_, err = ParseTemplate("{{.Torrent.Name")
+	require.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package rutor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/pkg/pipeline"
+)
+
+var (
+	imdbIDPattern      = regexp.MustCompile(`imdb\.com/title/(tt\d{7,9})`)
+	kinopoiskIDPattern = regexp.MustCompile(`kinopoisk\.ru/(?:level/\d+/)?(?:film|series)/(\d+)`)
+	torrentIDPattern   = regexp.MustCompile(`/torrent/(\d+)`)
+)
+
+// EnrichDetails visits the rutor details page of every torrent that has one and
+// fills IMDb/Kinopoisk ids, poster, description, file list and the exact upload
+// time. At most limit pages are fetched concurrently. Pages that fail to load are
+// logged and skipped; torrents from other trackers are left untouched. When ctx
+// is done it returns ctx's error, but only after every page fetch has stopped,
+// so the torrents are not written to afterwards.
+func EnrichDetails(ctx context.Context, ts []*torrents.Torrent, limit int64) error {
+	pages := make([]*torrents.Torrent, 0, len(ts))
+	for _, t := range ts {
+		if t != nil && torrentIDPattern.MatchString(t.DetailsUrl) {
+			pages = append(pages, t)
+		}
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+
+	in, err := pipeline.Producer(ctx, pages)
+	if err != nil {
+		return err
+	}
+	values, errs := pipeline.StepContext(ctx, in, fetchDetails, limit)
+
+	// Drain both channels even once ctx is done: StepContext closes them only
+	// after its workers, which write into the torrents, have returned.
+	for values != nil || errs != nil {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "failed to fetch rutor details", "error", err)
+			}
+		case _, ok := <-values:
+			if !ok {
+				values = nil
+			}
+		}
+	}
+
+	return ctx.Err()
+}
+
+// moscowTime is the zone rutor prints its timestamps in. Russia has no DST, so
+// a fixed offset is enough.
+var moscowTime = time.FixedZone("MSK", 3*60*60)
+
+func fetchDetails(ctx context.Context, t *torrents.Torrent) (*torrents.Torrent, error) {
+	c := newCollector(ctx)
+	c.OnResponse(func(r *colly.Response) {
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(r.Body))
+		if err != nil {
+			return
+		}
+		applyDetails(doc.Selection, t)
+	})
+	if err := c.Visit(t.DetailsUrl); err != nil {
+		return t, fmt.Errorf("rutor details %q: %w", t.DetailsUrl, err)
+	}
+
+	filesURL := filesURLFor(t.DetailsUrl)
+	if filesURL == "" {
+		return t, nil
+	}
+
+	files := newCollector(ctx)
+	files.OnResponse(func(r *colly.Response) {
+		t.Files = parseFileList(r.Body)
+	})
+	if err := files.Visit(filesURL); err != nil {
+		return t, fmt.Errorf("rutor file list %q: %w", filesURL, err)
+	}
+
+	return t, nil
+}
+
+// filesURLFor returns the endpoint rutor loads the file list from, e.g.
+// https://rutor.is/torrent/123/name -> https://rutor.is/descriptions/123.files.
+func filesURLFor(detailsURL string) string {
+	u, err := url.Parse(detailsURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	matches := torrentIDPattern.FindStringSubmatch(u.Path)
+	if len(matches) != 2 {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/descriptions/" + matches[1] + ".files"
+}
+
+func applyDetails(doc *goquery.Selection, t *torrents.Torrent) {
+	details := doc.Find("#details")
+	if details.Length() == 0 {
+		return
+	}
+
+	details.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		if t.ImdbID == "" {
+			if m := imdbIDPattern.FindStringSubmatch(href); len(m) == 2 {
+				t.ImdbID = m[1]
+			}
+		}
+		if t.KinopoiskID == "" {
+			if m := kinopoiskIDPattern.FindStringSubmatch(href); len(m) == 2 {
+				t.KinopoiskID = m[1]
+			}
+		}
+	})
+
+	rows := details.Find("tr")
+	description := rows.First().Children().Eq(1)
+	if src, ok := description.Find("img").First().Attr("src"); ok && strings.HasPrefix(src, "http") {
+		t.Poster = src
+	}
+	if text := strings.Join(strings.Fields(description.Text()), " "); text != "" {
+		t.Description = text
+	}
+
+	rows.Each(func(_ int, row *goquery.Selection) {
+		cells := row.Children()
+		if strings.TrimSpace(cells.Eq(0).Text()) != "Добавлен" {
+			return
+		}
+		if date, ok := parseUploadTime(cells.Eq(1).Text()); ok {
+			t.Date = date
+		}
+	})
+}
+
+// parseUploadTime parses the "Добавлен" value of a details page, e.g.
+// "18-06-2024 14:22:11  (2 дня назад)", which is Moscow time, and returns it
+// in UTC.
+func parseUploadTime(text string) (time.Time, bool) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return time.Time{}, false
+	}
+	ts, err := time.ParseInLocation("02-01-2006 15:04:05", fields[0]+" "+fields[1], moscowTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts.UTC(), true
+}
+
+// parseFileList reads the table rows rutor returns for the file list. The body
+// is a bare "<tr>" fragment, so it is wrapped in a table before parsing.
+func parseFileList(body []byte) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<table>" + string(body) + "</table>"))
+	if err != nil {
+		return nil
+	}
+
+	files := make([]string, 0)
+	doc.Find("tr").Each(func(_ int, row *goquery.Selection) {
+		name := strings.TrimSpace(row.Children().First().Text())
+		if name != "" {
+			files = append(files, name)
+		}
+	})
+	return files
+}
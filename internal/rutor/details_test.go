@@ -0,0 +1,122 @@
+package rutor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/stretchr/testify/require"
+)
+
+const detailsPage = `<html><body><div id="all">
+<table id="details">
+<tr><td></td><td>
+<img src="https://i.example/poster.jpg">
+<b>Описание:</b> Детективы   Майами снова в деле.
+<a href="https://www.imdb.com/title/tt4919268/">IMDb</a>
+<a href="https://www.kinopoisk.ru/film/1009536/">Кинопоиск</a>
+</td></tr>
+<tr><td class="header">Раздают</td><td>55</td></tr>
+<tr><td class="header">Добавлен</td><td>18-06-2024 14:22:11  (2 дня назад)</td></tr>
+</table>
+</div></body></html>`
+
+func TestApplyDetails(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(detailsPage))
+	require.NoError(t, err)
+
+	torrent := &torrents.Torrent{Date: time.Date(2024, 6, 18, 0, 0, 0, 0, time.UTC)}
+	applyDetails(doc.Selection, torrent)
+
+	require.Equal(t, "tt4919268", torrent.ImdbID)
+	require.Equal(t, "1009536", torrent.KinopoiskID)
+	require.Equal(t, "https://i.example/poster.jpg", torrent.Poster)
+	require.Equal(t, "Описание: Детективы Майами снова в деле. IMDb Кинопоиск", torrent.Description)
+	require.Equal(t, time.Date(2024, 6, 18, 11, 22, 11, 0, time.UTC), torrent.Date, "upload times are Moscow time")
+}
+
+func TestFilesURLFor(t *testing.T) {
+	require.Equal(t, "https://rutor.is/descriptions/981234.files", filesURLFor("https://rutor.is/torrent/981234/bad-boys"))
+	require.Empty(t, filesURLFor("1933366"))
+}
+
+func TestEnrichDetailsSkipsOtherTrackersAndFailedPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/torrent/1/movie":
+			fmt.Fprint(w, detailsPage)
+		case "/descriptions/1.files":
+			fmt.Fprint(w, "<tr><td>Movie.2160p.mkv</td><td>20 GB</td></tr><tr><td>Sample.mkv</td><td>1 GB</td></tr>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ok := &torrents.Torrent{DetailsUrl: srv.URL + "/torrent/1/movie"}
+	missing := &torrents.Torrent{DetailsUrl: srv.URL + "/torrent/2/missing"}
+	kinozal := &torrents.Torrent{DetailsUrl: "1933366"}
+
+	err := EnrichDetails(context.Background(), []*torrents.Torrent{ok, missing, kinozal}, 2)
+	require.NoError(t, err)
+
+	require.Equal(t, "tt4919268", ok.ImdbID)
+	require.Equal(t, []string{"Movie.2160p.mkv", "Sample.mkv"}, ok.Files)
+	require.Empty(t, missing.ImdbID)
+	require.Empty(t, kinozal.ImdbID)
+}
+
+func TestEnrichDetailsReturnsAfterItsWorkers(t *testing.T) {
+	// Pages finish one after another around the deadline, so some are
+	// parsed while ctx is already done.
+	var served atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Duration(served.Add(1)) * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, ".files") {
+			fmt.Fprint(w, "<tr><td>Movie.2160p.mkv</td><td>20 GB</td></tr>")
+			return
+		}
+		fmt.Fprint(w, detailsPage)
+	}))
+	defer srv.Close()
+
+	found := make([]*torrents.Torrent, 40)
+	for i := range found {
+		found[i] = &torrents.Torrent{DetailsUrl: fmt.Sprintf("%s/torrent/%d/movie", srv.URL, i+1)}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := EnrichDetails(ctx, found, 40)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// Under -race this catches a worker still filling a torrent.
+	for _, torrent := range found {
+		torrent.Poster, torrent.Files = "", nil
+	}
+}
+
+func TestFetchDetailsStopsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := fetchDetails(ctx, &torrents.Torrent{DetailsUrl: srv.URL + "/torrent/1/movie"})
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 2*time.Second)
+}
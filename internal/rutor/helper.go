@@ -26,13 +26,11 @@ var fullMonth = map[string]string{
 	"Дек": "Декабря",
 }
 
-func parseDate(text string) string {
-	const layout = "2006-01-02T15:04:05.000Z"
-
+func parseDate(text string) time.Time {
 	stringDate := ""
 	dateList := strings.Fields(text)
 	if len(dateList) < 3 {
-		return time.Now().UTC().Format(layout)
+		return time.Now().UTC()
 	}
 
 	for k, v := range fullMonth {
@@ -46,8 +44,8 @@ func parseDate(text string) string {
 	}
 	ts, err := monday.ParseInLocation("2 January 2006", stringDate, time.Now().UTC().Location(), monday.LocaleRuRU)
 	if err != nil {
-		return time.Now().UTC().Format(layout)
+		return time.Now().UTC()
 	}
 
-	return ts.Format(layout)
+	return ts.UTC()
 }
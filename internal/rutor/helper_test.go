@@ -14,12 +14,13 @@ func TestIsRussianOnly(t *testing.T) {
 
 func TestParseDate(t *testing.T) {
 	got := parseDate("06 Янв 24")
-	require.Equal(t, "2024-01-06T00:00:00.000Z", got)
+	require.Equal(t, time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), got)
 }
 
 func TestParseDateFallback(t *testing.T) {
+	before := time.Now().UTC().Add(-time.Second)
+
 	got := parseDate("invalid")
 
-	_, err := time.Parse("2006-01-02T15:04:05.000Z", got)
-	require.NoError(t, err)
+	require.True(t, got.After(before))
 }
@@ -1,8 +1,10 @@
 package rutor
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
@@ -11,6 +13,11 @@ import (
 	"github.com/lieranderl/moviestracker-package/internal/torrents"
 )
 
+const (
+	baseURL   = "https://rutor.is"
+	userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15"
+)
+
 var btihPattern = regexp.MustCompile(`btih:([a-fA-F0-9]{40})`)
 
 func extractMagnetHash(magnet string) (string, bool) {
@@ -30,11 +37,31 @@ func buildMovieHash(t *rutorTorrent) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
 }
 
-func parsePage(url string, isSeries bool) ([]*torrents.Torrent, error) {
-	result := make([]*torrents.Torrent, 0)
+// contextTransport binds the requests of a collector to ctx. colly v1 takes no
+// context of its own, so this is what lets a cancelled run abort a visit in
+// flight.
+type contextTransport struct {
+	ctx context.Context
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req.WithContext(t.ctx))
+}
+
+func newCollector(ctx context.Context) *colly.Collector {
 	c := colly.NewCollector()
 	c.SetRequestTimeout(20 * time.Second)
-	c.UserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15"
+	c.UserAgent = userAgent
+	c.WithTransport(contextTransport{ctx: ctx})
+	return c
+}
+
+func parsePage(ctx context.Context, url string, isSeries bool) ([]*torrents.Torrent, error) {
+	result := make([]*torrents.Torrent, 0)
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	c := newCollector(ctx)
 
 	c.OnHTML("tr", func(e *colly.HTMLElement) {
 		class := e.Attr("class")
@@ -66,8 +93,9 @@ func parsePage(url string, isSeries bool) ([]*torrents.Torrent, error) {
 		if !exists {
 			return
 		}
-		t.DetailsUrl = "https://rutor.is" + detailsURL
+		t.DetailsUrl = baseURL + detailsURL
 		t.Hash = buildMovieHash(t)
+		t.ImdbID = torrents.ExtractIMDbID(t.Name)
 
 		result = append(result, &t.Torrent)
 	})
@@ -80,10 +108,10 @@ func parsePage(url string, isSeries bool) ([]*torrents.Torrent, error) {
 	return result, nil
 }
 
-func ParseMoviePage(url string) ([]*torrents.Torrent, error) {
-	return parsePage(url, false)
+func ParseMoviePage(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+	return parsePage(ctx, url, false)
 }
 
-func ParseSeriesPage(url string) ([]*torrents.Torrent, error) {
-	return parsePage(url, true)
+func ParseSeriesPage(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+	return parsePage(ctx, url, true)
 }
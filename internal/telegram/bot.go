@@ -0,0 +1,151 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+const (
+	// searchResults is how many releases a /search reply lists.
+	searchResults = 10
+	searchTimeout = 3 * time.Minute
+
+	usage = "Usage: /search &lt;title&gt; [year], e.g. <code>/search Bad Boys 2024</code>"
+)
+
+// SearchFunc runs a tracker search for a /search command.
+type SearchFunc func(ctx context.Context, title, year string) ([]*torrents.Torrent, error)
+
+// Bot answers /search commands from the allowed chats.
+type Bot struct {
+	client  *Client
+	search  SearchFunc
+	allowed []int64
+}
+
+// NewBot only answers chats in allowed, since every search scrapes the
+// trackers; messages from other chats are ignored.
+func NewBot(client *Client, search SearchFunc, allowed ...int64) *Bot {
+	return &Bot{client: client, search: search, allowed: allowed}
+}
+
+// Run long polls for commands until ctx is done. Commands are handled one at
+// a time, in the order they arrive.
+func (b *Bot) Run(ctx context.Context) error {
+	var offset int64
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.WarnContext(ctx, "telegram poll failed", "error", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = max(offset, u.UpdateID+1)
+			if u.Message != nil {
+				b.handle(ctx, u.Message)
+			}
+		}
+	}
+}
+
+func (b *Bot) handle(ctx context.Context, m *Message) {
+	command, args, ok := parseCommand(m.Text)
+	if !ok {
+		return
+	}
+	if !slices.Contains(b.allowed, m.Chat.ID) {
+		slog.WarnContext(ctx, "telegram command from unknown chat ignored", "chat_id", m.Chat.ID, "command", command)
+		return
+	}
+
+	var reply string
+	switch command {
+	case "search":
+		reply = b.runSearch(ctx, args)
+	case "start", "help":
+		reply = usage
+	default:
+		return
+	}
+	if err := b.client.SendMessage(ctx, m.Chat.ID, reply); err != nil {
+		slog.ErrorContext(ctx, "telegram reply failed", "chat_id", m.Chat.ID, "error", err)
+	}
+}
+
+func (b *Bot) runSearch(ctx context.Context, args string) string {
+	title, year := parseSearch(args)
+	if title == "" {
+		return usage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+	slog.InfoContext(ctx, "telegram search", "query", title, "year", year)
+	found, err := b.search(ctx, title, year)
+	if err != nil {
+		slog.ErrorContext(ctx, "telegram search failed", "query", title, "year", year, "error", err)
+		return "Search failed: " + html.EscapeString(err.Error())
+	}
+	return FormatSearchResults(strings.TrimSpace(title+" "+year), found)
+}
+
+// FormatSearchResults lists the best seeded releases first, within the
+// message length limit.
+func FormatSearchResults(query string, found []*torrents.Torrent) string {
+	if len(found) == 0 {
+		return fmt.Sprintf("Nothing found for <b>%s</b>", html.EscapeString(query))
+	}
+
+	sorted := slices.Clone(found)
+	slices.SortStableFunc(sorted, func(a, b *torrents.Torrent) int { return int(b.Seeds) - int(a.Seeds) })
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "Found %d releases for <b>%s</b>", len(found), html.EscapeString(query))
+	for i, t := range sorted[:min(len(sorted), searchResults)] {
+		entry := fmt.Sprintf("\n\n%d. %s", i+1, FormatTorrent(t))
+		if msg.Len()+len(entry) > MaxMessageLength {
+			break
+		}
+		msg.WriteString(entry)
+	}
+	return msg.String()
+}
+
+// parseCommand splits "/search@my_bot Bad Boys 2024" into "search" and
+// "Bad Boys 2024".
+func parseCommand(text string) (command, args string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+	command, args, _ = strings.Cut(text[1:], " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(args), command != ""
+}
+
+// parseSearch takes a trailing four digit number as the year.
+func parseSearch(args string) (title, year string) {
+	fields := strings.Fields(args)
+	if n := len(fields); n > 1 {
+		if y, err := strconv.Atoi(fields[n-1]); err == nil && len(fields[n-1]) == 4 && y >= 1870 {
+			return strings.Join(fields[:n-1], " "), fields[n-1]
+		}
+	}
+	return strings.Join(fields, " "), ""
+}
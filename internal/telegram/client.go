@@ -0,0 +1,191 @@
+// Package telegram is a small Telegram Bot API client: sending messages and
+// photos and long polling for commands.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the public Bot API; WithBaseURL points the client at
+	// a local Bot API server or a test stand-in.
+	DefaultBaseURL = "https://api.telegram.org"
+
+	// pollTimeout is how long getUpdates waits for new messages.
+	pollTimeout       = 30 * time.Second
+	maxAttempts       = 3
+	defaultRetryDelay = time.Second
+	maxRetryWait      = time.Minute
+)
+
+// APIError is a failed Bot API call.
+type APIError struct {
+	Method      string
+	Code        int
+	Description string
+	RetryAfter  time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram %s: %d %s", e.Method, e.Code, e.Description)
+}
+
+type Client struct {
+	baseURL    string
+	token      string
+	client     *http.Client
+	retryDelay time.Duration
+}
+
+func New(token string) *Client {
+	return &Client{
+		baseURL:    DefaultBaseURL,
+		token:      token,
+		client:     &http.Client{Timeout: pollTimeout + 15*time.Second},
+		retryDelay: defaultRetryDelay,
+	}
+}
+
+// WithBaseURL replaces DefaultBaseURL; empty keeps it.
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	if baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/"); baseURL != "" {
+		c.baseURL = baseURL
+	}
+	return c
+}
+
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+// SendMessage sends HTML formatted text without link previews.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id":              chatID,
+		"text":                 text,
+		"parse_mode":           "HTML",
+		"link_preview_options": map[string]any{"is_disabled": true},
+	}, nil)
+}
+
+// SendPhoto sends the image at photoURL with an HTML formatted caption.
+func (c *Client) SendPhoto(ctx context.Context, chatID int64, photoURL, caption string) error {
+	return c.call(ctx, "sendPhoto", map[string]any{
+		"chat_id":    chatID,
+		"photo":      photoURL,
+		"caption":    caption,
+		"parse_mode": "HTML",
+	}, nil)
+}
+
+// GetUpdates long polls for messages after offset.
+func (c *Client) GetUpdates(ctx context.Context, offset int64) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(pollTimeout / time.Second),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// call posts params to method and retries rate limits (honouring
+// retry_after) and server errors.
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encode telegram %s: %w", method, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.post(ctx, method, body, result)
+		var apiErr *APIError
+		if err == nil || !errors.As(err, &apiErr) || attempt >= maxAttempts {
+			return err
+		}
+		if apiErr.Code != http.StatusTooManyRequests && apiErr.Code < 500 {
+			return err
+		}
+
+		wait := c.retryDelay * time.Duration(attempt)
+		if apiErr.RetryAfter > 0 {
+			wait = min(apiErr.RetryAfter, maxRetryWait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) post(ctx context.Context, method string, body []byte, result any) error {
+	url := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// The url contains the bot token; keep it out of logs.
+		var urlErr interface{ Unwrap() error }
+		if errors.As(err, &urlErr) {
+			err = urlErr.Unwrap()
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return &APIError{Method: method, Code: resp.StatusCode, Description: "undecodable response: " + err.Error()}
+	}
+	if !envelope.OK {
+		code := envelope.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return &APIError{
+			Method:      method,
+			Code:        code,
+			Description: envelope.Description,
+			RetryAfter:  time.Duration(envelope.Parameters.RetryAfter) * time.Second,
+		}
+	}
+	if result != nil {
+		if err := json.Unmarshal(envelope.Result, result); err != nil {
+			return fmt.Errorf("decode telegram %s: %w", method, err)
+		}
+	}
+	return nil
+}
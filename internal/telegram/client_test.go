@@ -0,0 +1,147 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/stretchr/testify/require"
+)
+
+type call struct {
+	Method string
+	Params map[string]any
+}
+
+// botAPI is a local Bot API stand-in: getUpdates hands out the queued updates
+// once, every other method succeeds unless a failure is queued for it.
+type botAPI struct {
+	mu       sync.Mutex
+	updates  []Update
+	failures map[string][]string
+	calls    []call
+}
+
+func (a *botAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bottoken/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+		return
+	}
+	var params map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&params)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if method != "getUpdates" {
+		a.calls = append(a.calls, call{Method: method, Params: params})
+	}
+	if queued := a.failures[method]; len(queued) > 0 {
+		a.failures[method] = queued[1:]
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, queued[0])
+		return
+	}
+
+	result := any(true)
+	if method == "getUpdates" {
+		result, a.updates = a.updates, nil
+		if result == nil {
+			result = []Update{}
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (a *botAPI) sent() []call {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]call(nil), a.calls...)
+}
+
+func newTestClient(t *testing.T, api *botAPI) *Client {
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	c := New("token").WithBaseURL(srv.URL + "/")
+	c.retryDelay = time.Millisecond
+	return c
+}
+
+func TestClientRetriesRateLimits(t *testing.T) {
+	api := &botAPI{failures: map[string][]string{
+		"sendMessage": {`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0"}`},
+	}}
+	c := newTestClient(t, api)
+
+	require.NoError(t, c.SendMessage(context.Background(), 42, "<b>hi</b>"))
+	calls := api.sent()
+	require.Len(t, calls, 2)
+	require.Equal(t, "HTML", calls[1].Params["parse_mode"])
+	require.EqualValues(t, 42, calls[1].Params["chat_id"])
+}
+
+func TestClientReportsAPIErrors(t *testing.T) {
+	c := newTestClient(t, &botAPI{})
+	c.token = "wrong"
+
+	err := c.SendMessage(context.Background(), 42, "hi")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 404, apiErr.Code)
+	require.NotContains(t, err.Error(), "wrong", "the token stays out of errors")
+}
+
+func TestBotAnswersSearch(t *testing.T) {
+	api := &botAPI{updates: []Update{
+		{UpdateID: 1, Message: &Message{Chat: Chat{ID: 7}, Text: "/search Bad Boys 2024"}},
+		{UpdateID: 2, Message: &Message{Chat: Chat{ID: 42}, Text: "/search@moviestracker_bot Bad Boys 2024"}},
+		{UpdateID: 3, Message: &Message{Chat: Chat{ID: 42}, Text: "hello"}},
+	}}
+	c := newTestClient(t, api)
+
+	var searched []string
+	search := func(_ context.Context, title, year string) ([]*torrents.Torrent, error) {
+		searched = append(searched, title+"|"+year)
+		return []*torrents.Torrent{
+			{Name: "Bad Boys 1080p", MagnetHash: "aaa", FHD: true, Seeds: 3},
+			{Name: "Bad Boys <2160p>", MagnetHash: "bbb", K4: true, DV: true, Seeds: 40},
+		}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewBot(c, search, 42).Run(ctx) }()
+	require.Eventually(t, func() bool { return len(api.sent()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	require.Equal(t, []string{"Bad Boys|2024"}, searched)
+	reply := api.sent()[0]
+	require.Equal(t, "sendMessage", reply.Method)
+	require.EqualValues(t, 42, reply.Params["chat_id"])
+	text := reply.Params["text"].(string)
+	require.Contains(t, text, "Found 2 releases for <b>Bad Boys 2024</b>")
+	require.Less(t, strings.Index(text, "Bad Boys &lt;2160p&gt;"), strings.Index(text, "Bad Boys 1080p"), "best seeded first")
+	require.Contains(t, text, "2160p · DV · 40 seeds")
+	require.Contains(t, text, "<code>magnet:?xt=urn:btih:bbb</code>")
+}
+
+func TestParseSearch(t *testing.T) {
+	for args, want := range map[string][2]string{
+		"Bad Boys 2024":        {"Bad Boys", "2024"},
+		"Bad Boys":             {"Bad Boys", ""},
+		"1917":                 {"1917", ""},
+		"Dune  Part Two 2024 ": {"Dune Part Two", "2024"},
+	} {
+		title, year := parseSearch(args)
+		require.Equal(t, want, [2]string{title, year}, args)
+	}
+}
@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+const (
+	// MaxMessageLength and MaxCaptionLength are the Bot API limits.
+	MaxMessageLength = 4096
+	MaxCaptionLength = 1024
+)
+
+// Badges renders the quality of a release, e.g. "2160p · DV · HDR10".
+func Badges(t *torrents.Torrent) string {
+	badges := []string{t.Resolution()}
+	for _, f := range t.HDRFormats() {
+		if f != "sdr" {
+			badges = append(badges, strings.ToUpper(f))
+		}
+	}
+	return strings.Join(badges, " · ")
+}
+
+// ShortMagnet is a magnet link with only the info hash, which stays short
+// enough for captions and is easy to copy; clients find peers via DHT.
+func ShortMagnet(t *torrents.Torrent) string {
+	if t.MagnetHash != "" {
+		return "magnet:?xt=urn:btih:" + t.MagnetHash
+	}
+	return t.Magnet
+}
+
+// FormatTorrent renders one release as HTML: name, badges, seeds and a
+// tap-to-copy magnet.
+func FormatTorrent(t *torrents.Torrent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n%s · %d seeds", html.EscapeString(t.Name), Badges(t), t.Seeds)
+	if magnet := ShortMagnet(t); magnet != "" {
+		fmt.Fprintf(&b, "\n<code>%s</code>", html.EscapeString(magnet))
+	}
+	return b.String()
+}
@@ -0,0 +1,200 @@
+// Package tmdbfake is a local stand-in for the TMDB v3 API. It answers the
+// endpoints the enrichment stages use from JSON fixture files, so the pipeline
+// can run end to end in tests and offline.
+//
+// Fixtures mirror the request path below the API root:
+//
+//	search/movie/<query>.json      GET /search/movie?query=...
+//	search/tv/<query>.json         GET /search/tv?query=...
+//	movie/<id>.json                GET /movie/<id>
+//	movie/<id>/images.json         GET /movie/<id>/images
+//	find/<external id>.json        GET /find/<external id>
+//	genre/<movie|tv>/list.json     GET /genre/<movie|tv>/list
+//
+// <query> is the lowercased query with runs of other characters than letters
+// and digits replaced by "-", e.g. "Bad Boys: Ride or Die" -> bad-boys-ride-or-die.
+// A "<name>.<language>.json" file is preferred over "<name>.json" when the
+// request has a language parameter. Searches without a fixture return an empty
+// result page; other missing fixtures return TMDB's 404 body.
+package tmdbfake
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed fixtures
+var embedded embed.FS
+
+// DefaultFixtures returns the fixtures shipped with the package.
+func DefaultFixtures() fs.FS {
+	sub, err := fs.Sub(embedded, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Handler serves TMDB responses from fixtures and counts requests per path.
+type Handler struct {
+	fixtures fs.FS
+	apiKey   string
+
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+// NewHandler serves fixtures. When apiKey is not empty, requests with another
+// api_key get TMDB's 401 response.
+func NewHandler(fixtures fs.FS, apiKey string) *Handler {
+	return &Handler{fixtures: fixtures, apiKey: apiKey, hits: make(map[string]int)}
+}
+
+// NewServer starts an httptest server backed by a new Handler. The caller
+// closes the server; its URL is the base URL to configure the client with.
+func NewServer(fixtures fs.FS, apiKey string) (*httptest.Server, *Handler) {
+	h := NewHandler(fixtures, apiKey)
+	return httptest.NewServer(h), h
+}
+
+// Hits returns how many requests were made for path, e.g. "/search/movie".
+func (h *Handler) Hits(path string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hits[path]
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := "/" + strings.Trim(r.URL.Path, "/")
+	h.mu.Lock()
+	h.hits[p]++
+	h.mu.Unlock()
+
+	if r.Method != http.MethodGet {
+		writeStatus(w, http.StatusMethodNotAllowed, 3, "Method not allowed.")
+		return
+	}
+	query := r.URL.Query()
+	if h.apiKey != "" && query.Get("api_key") != h.apiKey {
+		writeStatus(w, http.StatusUnauthorized, 7, "Invalid API key: You must be granted a valid key.")
+		return
+	}
+
+	name := strings.TrimPrefix(p, "/")
+	language := query.Get("language")
+	switch name {
+	case "search/movie":
+		h.serveSearch(w, name, query.Get("query"), language, query.Get("year"))
+	case "search/tv":
+		h.serveSearch(w, name, query.Get("query"), language, "")
+	default:
+		h.serveFixture(w, name, language)
+	}
+}
+
+// serveSearch answers a search from its fixture. Like TMDB, a year keeps only
+// results released that year.
+func (h *Handler) serveSearch(w http.ResponseWriter, endpoint, query, language, year string) {
+	data, err := h.load(path.Join(endpoint, Slug(query)), language)
+	if errors.Is(err, fs.ErrNotExist) {
+		data = []byte(emptySearch)
+	} else if err != nil {
+		writeStatus(w, http.StatusInternalServerError, 11, err.Error())
+		return
+	}
+	if year != "" {
+		if data, err = filterByYear(data, year); err != nil {
+			writeStatus(w, http.StatusInternalServerError, 11, err.Error())
+			return
+		}
+	}
+	writeJSON(w, data)
+}
+
+const emptySearch = `{"page":1,"results":[],"total_pages":0,"total_results":0}`
+
+func filterByYear(data []byte, year string) ([]byte, error) {
+	var page map[string]any
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+	results, _ := page["results"].([]any)
+	kept := make([]any, 0, len(results))
+	for _, result := range results {
+		movie, _ := result.(map[string]any)
+		if date, _ := movie["release_date"].(string); strings.HasPrefix(date, year+"-") {
+			kept = append(kept, result)
+		}
+	}
+	page["results"] = kept
+	page["total_results"] = len(kept)
+	return json.Marshal(page)
+}
+
+func (h *Handler) serveFixture(w http.ResponseWriter, name, language string) {
+	data, err := h.load(name, language)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeStatus(w, http.StatusNotFound, 34, "The resource you requested could not be found.")
+		return
+	}
+	if err != nil {
+		writeStatus(w, http.StatusInternalServerError, 11, err.Error())
+		return
+	}
+	writeJSON(w, data)
+}
+
+// load reads "<name>.<language>.json", falling back to "<name>.json".
+func (h *Handler) load(name, language string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, fs.ErrNotExist
+	}
+	if language != "" {
+		data, err := fs.ReadFile(h.fixtures, name+"."+strings.ToLower(language)+".json")
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+	return fs.ReadFile(h.fixtures, name+".json")
+}
+
+// Slug returns the fixture file name used for a search query.
+func Slug(query string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(query)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func writeJSON(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+func writeStatus(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success":        false,
+		"status_code":    code,
+		"status_message": message,
+	})
+}
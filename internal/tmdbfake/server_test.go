@@ -0,0 +1,67 @@
+package tmdbfake
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlug(t *testing.T) {
+	require.Equal(t, "bad-boys-ride-or-die", Slug("  Bad Boys: Ride or Die "))
+	require.Equal(t, "плохие-парни-4", Slug("Плохие парни 4"))
+	require.Empty(t, Slug("!!"))
+}
+
+func get(t *testing.T, url string) (int, map[string]any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	return resp.StatusCode, decoded
+}
+
+func TestServerServesFixtures(t *testing.T) {
+	srv, h := NewServer(DefaultFixtures(), "")
+	defer srv.Close()
+
+	status, body := get(t, srv.URL+"/search/movie?query=Bad+Boys:+Ride+or+Die")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, body["results"], 2)
+
+	_, body = get(t, srv.URL+"/search/movie?query=Bad+Boys:+Ride+or+Die&year=1995")
+	require.Len(t, body["results"], 1)
+
+	_, body = get(t, srv.URL+"/search/movie?query=unknown")
+	require.Empty(t, body["results"])
+
+	_, body = get(t, srv.URL+"/movie/573435?language=en")
+	require.Equal(t, "Bad Boys: Ride or Die", body["title"])
+	_, body = get(t, srv.URL+"/movie/573435?language=uk")
+	require.Equal(t, "Плохие парни до конца", body["title"])
+
+	status, body = get(t, srv.URL+"/movie/1")
+	require.Equal(t, http.StatusNotFound, status)
+	require.EqualValues(t, 34, body["status_code"])
+
+	require.Equal(t, 3, h.Hits("/search/movie"))
+}
+
+func TestServerChecksAPIKey(t *testing.T) {
+	srv, _ := NewServer(DefaultFixtures(), "secret")
+	defer srv.Close()
+
+	status, _ := get(t, srv.URL+"/genre/movie/list?language=en&api_key=wrong")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	status, body := get(t, srv.URL+"/genre/movie/list?language=en&api_key=secret")
+	require.Equal(t, http.StatusOK, status)
+	require.NotEmpty(t, body["genres"])
+}
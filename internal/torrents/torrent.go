@@ -2,10 +2,14 @@ package torrents
 
 import (
 	"context"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
+var imdbIDPattern = regexp.MustCompile(`\btt\d{7,9}\b`)
+
 type Torrent struct {
 	Name         string
 	DetailsUrl   string
@@ -14,7 +18,7 @@ type Torrent struct {
 	Year         string
 	Size         float32
 	Magnet       string
-	Date         string
+	Date         time.Time
 	K4           bool
 	FHD          bool
 	HDR          bool
@@ -25,6 +29,74 @@ type Torrent struct {
 	Leeches      int32
 	Hash         string
 	MagnetHash   string
+	ImdbID       string
+	KinopoiskID  string
+	Poster       string
+	Description  string
+	Files        []string
+}
+
+// ExtractIMDbID returns the first IMDb title id (e.g. "tt4919268") found in text,
+// such as a release name, or an empty string.
+func ExtractIMDbID(text string) string {
+	return imdbIDPattern.FindString(text)
+}
+
+// QualityRank orders releases by resolution first and HDR format second, so a
+// higher rank is a better copy of the same movie: 4K DV > 4K HDR10 > 1080p.
+func (t *Torrent) QualityRank() int {
+	rank := 0
+	switch {
+	case t.K4:
+		rank = 20
+	case t.FHD:
+		rank = 10
+	}
+	switch {
+	case t.DV:
+		rank += 4
+	case t.HDR10plus:
+		rank += 3
+	case t.HDR10:
+		rank += 2
+	case t.HDR:
+		rank++
+	}
+	return rank
+}
+
+// Resolution is "2160p", "1080p" or "sd".
+func (t *Torrent) Resolution() string {
+	switch {
+	case t.K4:
+		return "2160p"
+	case t.FHD:
+		return "1080p"
+	default:
+		return "sd"
+	}
+}
+
+// HDRFormats lists the dynamic range formats of the release, best first:
+// "dv", "hdr10+", "hdr10", "hdr"; releases without any are "sdr".
+func (t *Torrent) HDRFormats() []string {
+	var formats []string
+	if t.DV {
+		formats = append(formats, "dv")
+	}
+	if t.HDR10plus {
+		formats = append(formats, "hdr10+")
+	}
+	if t.HDR10 {
+		formats = append(formats, "hdr10")
+	}
+	if t.HDR {
+		formats = append(formats, "hdr")
+	}
+	if len(formats) == 0 {
+		formats = append(formats, "sdr")
+	}
+	return formats
 }
 
 func MergeTorrentChannlesToSlice(ctx context.Context, cancelFunc context.CancelFunc, values <-chan []*Torrent, errors <-chan error) ([]*Torrent, error) {
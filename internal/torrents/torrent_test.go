@@ -74,3 +74,22 @@ func TestMergeTorrentChannlesToSliceCompatibilityWrapper(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, got, 1)
 }
+
+func TestExtractIMDbID(t *testing.T) {
+	require.Equal(t, "tt4919268", ExtractIMDbID("Bad Boys: Ride or Die (2024) tt4919268 2160p"))
+	require.Equal(t, "tt12345678", ExtractIMDbID("imdb.com/title/tt12345678/"))
+	require.Empty(t, ExtractIMDbID("Bad Boys xtt4919268"))
+}
+
+func TestQualityRank(t *testing.T) {
+	sd := &Torrent{}
+	fhd := &Torrent{FHD: true}
+	fhdHDR := &Torrent{FHD: true, HDR: true}
+	uhdHDR10 := &Torrent{K4: true, HDR10: true}
+	uhdDV := &Torrent{K4: true, HDR10: true, DV: true}
+
+	require.Less(t, sd.QualityRank(), fhd.QualityRank())
+	require.Less(t, fhd.QualityRank(), fhdHDR.QualityRank())
+	require.Less(t, fhdHDR.QualityRank(), uhdHDR10.QualityRank())
+	require.Less(t, uhdHDR10.QualityRank(), uhdDV.QualityRank())
+}
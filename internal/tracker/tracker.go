@@ -5,21 +5,33 @@ import (
 	"errors"
 
 	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
 	"github.com/lieranderl/moviestracker-package/pkg/pipeline"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/lieranderl/moviestracker-package/internal/tracker")
+
 type Config struct {
+	// Name labels the "tracker.visit" spans and the "tracker" log attribute,
+	// e.g. "rutor".
+	Name          string
 	Urls          []string
-	TrackerParser func(string) ([]*torrents.Torrent, error)
+	TrackerParser func(context.Context, string) ([]*torrents.Torrent, error)
 }
 
 type Tracker struct {
+	name          string
 	urls          []string
-	trackerParser func(string) ([]*torrents.Torrent, error)
+	trackerParser func(context.Context, string) ([]*torrents.Torrent, error)
 }
 
 func Init(config Config) *Tracker {
 	return &Tracker{
+		name:          config.Name,
 		urls:          append([]string(nil), config.Urls...),
 		trackerParser: config.TrackerParser,
 	}
@@ -44,6 +56,23 @@ func (t Tracker) TorrentsPipelineStream(ctx context.Context) (<-chan []*torrents
 		close(ec)
 		return tc, ec
 	}
-	torrents_chan, errors := pipeline.Step(ctx, urlStream, t.trackerParser, 3)
+	torrents_chan, errors := pipeline.StepContext(ctx, urlStream, t.visit, 3)
 	return torrents_chan, errors
 }
+
+// visit parses one search page in a "tracker.visit" span.
+func (t Tracker) visit(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+	ctx, span := tracer.Start(ctx, "tracker.visit", trace.WithAttributes(
+		attribute.String("tracker.name", t.name),
+		attribute.String("url.full", url),
+	))
+	defer span.End()
+
+	found, err := t.trackerParser(logging.With(ctx, "tracker", t.name), url)
+	span.SetAttributes(attribute.Int("tracker.torrents", len(found)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return found, err
+}
@@ -1,11 +1,14 @@
 package tracker
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
 	"testing"
 
 	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/lieranderl/moviestracker-package/pkg/logging"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,7 +28,7 @@ func TestTorrentsPipelineStreamRequiresParser(t *testing.T) {
 func TestTorrentsPipelineStreamProcessesURLs(t *testing.T) {
 	tr := Init(Config{
 		Urls: []string{"u1", "u2"},
-		TrackerParser: func(url string) ([]*torrents.Torrent, error) {
+		TrackerParser: func(_ context.Context, url string) ([]*torrents.Torrent, error) {
 			if url == "" {
 				return nil, errors.New("empty")
 			}
@@ -55,3 +58,25 @@ func TestTorrentsPipelineStreamProcessesURLs(t *testing.T) {
 
 	require.Len(t, got, 2)
 }
+
+func TestTorrentsPipelineStreamLogsTrackerName(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(logging.Handler(slog.NewTextHandler(&out, nil)))
+	tr := Init(Config{
+		Name: "rutor",
+		Urls: []string{"u1"},
+		TrackerParser: func(ctx context.Context, url string) ([]*torrents.Torrent, error) {
+			logger.InfoContext(ctx, "page parsed", "url", url)
+			return nil, nil
+		},
+	})
+
+	values, errs := tr.TorrentsPipelineStream(logging.With(context.Background(), "run_id", "r1"))
+	for range values {
+	}
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Contains(t, out.String(), "run_id=r1 tracker=rutor")
+}
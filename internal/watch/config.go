@@ -0,0 +1,129 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultSchedule applies to queries that set none.
+	DefaultSchedule = "@hourly"
+	// DefaultMinSeedDelta is how much the seed count must move before a
+	// seeds_changed event is emitted.
+	DefaultMinSeedDelta = 10
+)
+
+// Query is one saved search.
+type Query struct {
+	// Name identifies the query in the state file and in events; it defaults
+	// to "<query> <year>".
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Year     string `json:"year"`
+	Movie    *bool  `json:"movie,omitempty"`
+	Schedule string `json:"schedule,omitempty"`
+	// Notify filters the events sent to notifiers; nil sends all of them.
+	Notify *Rule `json:"notify,omitempty"`
+	// Download sends new releases that pass the rule to the torrent client,
+	// including the ones already listed on the query's first run; nil
+	// downloads nothing.
+	Download *DownloadRule `json:"download,omitempty"`
+}
+
+// DownloadRule selects the releases of a query to download and where they go;
+// empty Category, SavePath and Tags keep the client-wide defaults. On the
+// first run of a query, which only records a baseline and reports no events,
+// the rule is applied to every release found.
+type DownloadRule struct {
+	Rule
+	Category string   `json:"category,omitempty"`
+	SavePath string   `json:"save_path,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// IsMovie reports whether the query searches movies (the default) or series.
+func (q Query) IsMovie() bool {
+	return q.Movie == nil || *q.Movie
+}
+
+// Config is the watch file, e.g.
+//
+//	{
+//	  "schedule": "*/30 * * * *",
+//	  "min_seed_delta": 10,
+//	  "queries": [
+//	    {"query": "Bad Boys", "year": "2024"},
+//	    {"name": "the-bear", "query": "Медведь", "year": "2024", "movie": false, "schedule": "@daily"},
+//	    {"query": "Dune", "year": "2024", "notify": {"resolution": ["2160p"], "hdr": ["dv"]}},
+//	    {"query": "Alien", "year": "2024", "download": {"resolution": ["2160p"], "min_seeds": 5, "category": "movies"}}
+//	  ]
+//	}
+type Config struct {
+	Schedule     string  `json:"schedule,omitempty"`
+	MinSeedDelta int32   `json:"min_seed_delta,omitempty"`
+	Queries      []Query `json:"queries"`
+}
+
+// LoadConfig reads and validates a watch file.
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read watch config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse watch config %s: %w", path, err)
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, fmt.Errorf("watch config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// normalize fills defaults and checks names and schedules.
+func (c *Config) normalize() error {
+	if c.Schedule == "" {
+		c.Schedule = DefaultSchedule
+	}
+	if c.MinSeedDelta <= 0 {
+		c.MinSeedDelta = DefaultMinSeedDelta
+	}
+	if len(c.Queries) == 0 {
+		return errors.New("no queries")
+	}
+
+	seen := make(map[string]struct{}, len(c.Queries))
+	for i := range c.Queries {
+		q := &c.Queries[i]
+		q.Query = strings.TrimSpace(q.Query)
+		if q.Query == "" {
+			return fmt.Errorf("query %d: empty query", i+1)
+		}
+		if q.Name == "" {
+			q.Name = strings.TrimSpace(q.Query + " " + q.Year)
+		}
+		if _, ok := seen[q.Name]; ok {
+			return fmt.Errorf("query %d: duplicate name %q", i+1, q.Name)
+		}
+		seen[q.Name] = struct{}{}
+
+		if q.Schedule == "" {
+			q.Schedule = c.Schedule
+		}
+		if _, err := ParseSchedule(q.Schedule); err != nil {
+			return fmt.Errorf("query %q: %w", q.Name, err)
+		}
+		if err := q.Notify.normalize(); err != nil {
+			return fmt.Errorf("query %q: notify: %w", q.Name, err)
+		}
+		if q.Download != nil {
+			if err := q.Download.normalize(); err != nil {
+				return fmt.Errorf("query %q: download: %w", q.Name, err)
+			}
+		}
+	}
+	return nil
+}
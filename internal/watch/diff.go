@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+type EventType string
+
+const (
+	// EventNewRelease is a torrent the query has not found before.
+	EventNewRelease EventType = "new_release"
+	// EventBetterQuality is a new torrent that beats the best known quality
+	// of the same movie (see torrents.Torrent.QualityRank).
+	EventBetterQuality EventType = "better_quality"
+	// EventSeedsChanged is a known torrent whose seed count moved by at least
+	// Config.MinSeedDelta since it was last reported.
+	EventSeedsChanged EventType = "seeds_changed"
+)
+
+// stateRetention is how long torrents that disappeared from the results stay
+// in the snapshot, so a release that drops off a page is not announced again.
+const stateRetention = 30 * 24 * time.Hour
+
+type Event struct {
+	Type    EventType         `json:"type"`
+	Query   string            `json:"query"`
+	Torrent *torrents.Torrent `json:"torrent"`
+	// PreviousQuality is the best known quality for better_quality events.
+	PreviousQuality int `json:"previous_quality,omitempty"`
+	// PreviousSeeds is the last reported seed count for seeds_changed events.
+	PreviousSeeds int32     `json:"previous_seeds,omitempty"`
+	At            time.Time `json:"at"`
+}
+
+// Diff compares the torrents of one run with the previous snapshot and returns
+// the resulting events and the snapshot to store. Torrents without a magnet
+// hash cannot be tracked and are ignored.
+func Diff(query string, previous *Snapshot, current []*torrents.Torrent, minSeedDelta int32, now time.Time) ([]Event, *Snapshot) {
+	if minSeedDelta <= 0 {
+		minSeedDelta = DefaultMinSeedDelta
+	}
+	next := &Snapshot{CheckedAt: now, Torrents: make(map[string]TorrentState)}
+	bestQuality := make(map[string]int)
+	if previous != nil {
+		for hash, state := range previous.Torrents {
+			if now.Sub(state.LastSeen) < stateRetention {
+				next.Torrents[hash] = state
+			}
+			if q, ok := bestQuality[state.MovieHash]; !ok || state.Quality > q {
+				bestQuality[state.MovieHash] = state.Quality
+			}
+		}
+	}
+
+	events := make([]Event, 0)
+	for _, t := range current {
+		if t == nil || t.MagnetHash == "" {
+			continue
+		}
+		hash := strings.ToLower(t.MagnetHash)
+		state, known := next.Torrents[hash]
+
+		if !known {
+			event := Event{Type: EventNewRelease, Query: query, Torrent: t, At: now}
+			if best, ok := bestQuality[t.Hash]; ok && t.QualityRank() > best {
+				event.Type = EventBetterQuality
+				event.PreviousQuality = best
+			}
+			events = append(events, event)
+			state = TorrentState{Name: t.Name, MovieHash: t.Hash, Quality: t.QualityRank(), Seeds: t.Seeds, FirstSeen: now}
+		} else if delta := t.Seeds - state.Seeds; delta >= minSeedDelta || -delta >= minSeedDelta {
+			events = append(events, Event{Type: EventSeedsChanged, Query: query, Torrent: t, PreviousSeeds: state.Seeds, At: now})
+			state.Seeds = t.Seeds
+		}
+
+		state.LastSeen = now
+		next.Torrents[hash] = state
+	}
+	return events, next
+}
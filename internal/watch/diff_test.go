@@ -0,0 +1,76 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	first := time.Date(2024, 6, 7, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	fhd := &torrents.Torrent{Name: "Bad Boys 1080p", Hash: "movie", MagnetHash: "AAA", FHD: true, Seeds: 50}
+	events, snapshot := Diff("bad boys", nil, []*torrents.Torrent{fhd, {Name: "no magnet"}}, 10, first)
+	require.Len(t, events, 1)
+	require.Equal(t, EventNewRelease, events[0].Type)
+	require.Len(t, snapshot.Torrents, 1)
+	require.Contains(t, snapshot.Torrents, "aaa")
+
+	fhdMoreSeeds := *fhd
+	fhdMoreSeeds.Seeds = 65
+	uhd := &torrents.Torrent{Name: "Bad Boys 2160p", Hash: "movie", MagnetHash: "bbb", K4: true, DV: true, Seeds: 5}
+	other := &torrents.Torrent{Name: "Bad Boys II", Hash: "other", MagnetHash: "ccc", K4: true, Seeds: 5}
+
+	events, snapshot = Diff("bad boys", snapshot, []*torrents.Torrent{&fhdMoreSeeds, uhd, other}, 10, second)
+	require.Len(t, events, 3)
+
+	require.Equal(t, EventSeedsChanged, events[0].Type)
+	require.EqualValues(t, 50, events[0].PreviousSeeds)
+
+	require.Equal(t, EventBetterQuality, events[1].Type)
+	require.Equal(t, fhd.QualityRank(), events[1].PreviousQuality)
+
+	require.Equal(t, EventNewRelease, events[2].Type)
+	require.Equal(t, "other", events[2].Torrent.Hash)
+
+	state := snapshot.Torrents["aaa"]
+	require.EqualValues(t, 65, state.Seeds)
+	require.Equal(t, first, state.FirstSeen)
+	require.Equal(t, second, state.LastSeen)
+}
+
+func TestDiffSeedDriftAccumulates(t *testing.T) {
+	now := time.Date(2024, 6, 7, 10, 0, 0, 0, time.UTC)
+	tr := &torrents.Torrent{Hash: "movie", MagnetHash: "aaa", Seeds: 10}
+	_, snapshot := Diff("q", nil, []*torrents.Torrent{tr}, 10, now)
+
+	for _, seeds := range []int32{14, 18} {
+		next := *tr
+		next.Seeds = seeds
+		var events []Event
+		events, snapshot = Diff("q", snapshot, []*torrents.Torrent{&next}, 10, now)
+		require.Empty(t, events)
+	}
+
+	next := *tr
+	next.Seeds = 20
+	events, _ := Diff("q", snapshot, []*torrents.Torrent{&next}, 10, now)
+	require.Len(t, events, 1)
+	require.Equal(t, EventSeedsChanged, events[0].Type)
+}
+
+func TestDiffForgetsOldTorrents(t *testing.T) {
+	now := time.Date(2024, 6, 7, 10, 0, 0, 0, time.UTC)
+	previous := &Snapshot{Torrents: map[string]TorrentState{
+		"recent": {MovieHash: "movie", LastSeen: now.Add(-24 * time.Hour)},
+		"stale":  {MovieHash: "movie", LastSeen: now.Add(-stateRetention)},
+	}}
+
+	events, snapshot := Diff("q", previous, nil, 10, now)
+	require.Empty(t, events)
+	require.Contains(t, snapshot.Torrents, "recent")
+	require.NotContains(t, snapshot.Torrents, "stale")
+}
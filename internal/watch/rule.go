@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rule narrows down which events of a query are worth a notification, e.g.
+// only 4K Dolby Vision releases:
+//
+//	{"events": ["new_release", "better_quality"], "resolution": ["2160p"], "hdr": ["dv"]}
+//
+// Empty lists allow everything.
+type Rule struct {
+	Events []EventType `json:"events,omitempty"`
+	// Resolution accepts "2160p", "1080p" and "sd".
+	Resolution []string `json:"resolution,omitempty"`
+	// HDR accepts "dv", "hdr10+", "hdr10", "hdr" and "sdr"; a release matches
+	// when it has any of the listed formats.
+	HDR      []string `json:"hdr,omitempty"`
+	MinSeeds int32    `json:"min_seeds,omitempty"`
+}
+
+// Matches reports whether e passes the rule. A nil rule matches every event.
+func (r *Rule) Matches(e Event) bool {
+	if r == nil {
+		return true
+	}
+	if len(r.Events) > 0 && !slices.Contains(r.Events, e.Type) {
+		return false
+	}
+	t := e.Torrent
+	if t == nil {
+		return false
+	}
+	if t.Seeds < r.MinSeeds {
+		return false
+	}
+	if len(r.Resolution) > 0 && !slices.Contains(r.Resolution, t.Resolution()) {
+		return false
+	}
+	if len(r.HDR) > 0 && !slices.ContainsFunc(t.HDRFormats(), func(f string) bool { return slices.Contains(r.HDR, f) }) {
+		return false
+	}
+	return true
+}
+
+// Filter returns the events that pass the rule.
+func (r *Rule) Filter(events []Event) []Event {
+	matched := make([]Event, 0, len(events))
+	for _, e := range events {
+		if r.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// normalize lowercases the rule values and rejects unknown ones.
+func (r *Rule) normalize() error {
+	if r == nil {
+		return nil
+	}
+	for _, e := range r.Events {
+		if e != EventNewRelease && e != EventBetterQuality && e != EventSeedsChanged {
+			return fmt.Errorf("unknown event %q", e)
+		}
+	}
+	for i, v := range r.Resolution {
+		r.Resolution[i] = strings.ToLower(strings.TrimSpace(v))
+		if !slices.Contains([]string{"2160p", "1080p", "sd"}, r.Resolution[i]) {
+			return fmt.Errorf("unknown resolution %q (2160p|1080p|sd)", v)
+		}
+	}
+	for i, v := range r.HDR {
+		r.HDR[i] = strings.ToLower(strings.TrimSpace(v))
+		if !slices.Contains([]string{"dv", "hdr10+", "hdr10", "hdr", "sdr"}, r.HDR[i]) {
+			return fmt.Errorf("unknown hdr format %q (dv|hdr10+|hdr10|hdr|sdr)", v)
+		}
+	}
+	return nil
+}
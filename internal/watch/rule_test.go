@@ -0,0 +1,40 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMatches(t *testing.T) {
+	uhdDV := Event{Type: EventNewRelease, Torrent: &torrents.Torrent{K4: true, HDR10: true, DV: true, Seeds: 20}}
+	uhdHDR := Event{Type: EventNewRelease, Torrent: &torrents.Torrent{K4: true, HDR10: true, Seeds: 20}}
+	fhd := Event{Type: EventSeedsChanged, Torrent: &torrents.Torrent{FHD: true, Seeds: 3}}
+
+	var all *Rule
+	require.True(t, all.Matches(fhd))
+
+	dv := &Rule{Resolution: []string{"2160p"}, HDR: []string{"dv"}}
+	require.True(t, dv.Matches(uhdDV))
+	require.False(t, dv.Matches(uhdHDR))
+	require.False(t, dv.Matches(fhd))
+
+	sdr := &Rule{HDR: []string{"sdr"}}
+	require.True(t, sdr.Matches(fhd))
+	require.False(t, sdr.Matches(uhdHDR))
+
+	releases := &Rule{Events: []EventType{EventNewRelease, EventBetterQuality}, MinSeeds: 5}
+	require.Equal(t, []Event{uhdDV, uhdHDR}, releases.Filter([]Event{uhdDV, fhd, uhdHDR}))
+}
+
+func TestRuleNormalize(t *testing.T) {
+	r := &Rule{Resolution: []string{" 2160P "}, HDR: []string{"DV", "HDR10+"}}
+	require.NoError(t, r.normalize())
+	require.Equal(t, []string{"2160p"}, r.Resolution)
+	require.Equal(t, []string{"dv", "hdr10+"}, r.HDR)
+
+	require.Error(t, (&Rule{Resolution: []string{"720p"}}).normalize())
+	require.Error(t, (&Rule{HDR: []string{"hlg"}}).normalize())
+	require.Error(t, (&Rule{Events: []EventType{"deleted"}}).normalize())
+}
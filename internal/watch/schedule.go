@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a saved query runs next.
+type Schedule interface {
+	// Next returns the first run time strictly after t, or the zero time when
+	// the schedule never fires again.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule reads a five-field cron expression (minute, hour, day of
+// month, month, day of week; e.g. "*/30 * * * *" or "0 9-21/3 * * 1-5") or one
+// of the shortcuts @hourly, @daily, @weekly and "@every <duration>".
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("parse schedule %q: %w", spec, err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("parse schedule %q: interval must be at least 1m", spec)
+		}
+		return every(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("parse schedule %q: want 5 fields or a @shortcut, got %d fields", spec, len(fields))
+	}
+
+	var c cronSchedule
+	var err error
+	bounds := []struct {
+		set      *uint64
+		min, max int
+	}{
+		{&c.minute, 0, 59},
+		{&c.hour, 0, 23},
+		{&c.dom, 1, 31},
+		{&c.month, 1, 12},
+		{&c.dow, 0, 7},
+	}
+	for i, b := range bounds {
+		if *b.set, err = parseField(fields[i], b.min, b.max); err != nil {
+			return nil, fmt.Errorf("parse schedule %q: field %d: %w", spec, i+1, err)
+		}
+	}
+	// Both 0 and 7 mean Sunday.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cronSchedule holds one bit per allowed value of each field.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// maxCronSearch bounds Next for expressions that never match, e.g. "0 0 31 2 *".
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+func (c cronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+
+	for next.Before(limit) {
+		switch {
+		case !has(c.month, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !c.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !has(c.hour, next.Hour()):
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !has(c.minute, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted, either may match.
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// parseField reads a comma separated list of "*", "n", "a-b", each optionally
+// followed by "/step".
+func parseField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var errA, errB error
+			lo, errA = strconv.Atoi(a)
+			hi, errB = strconv.Atoi(b)
+			if err := errors.Join(errA, errB); err != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			v, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,49 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	from := time.Date(2024, 6, 7, 10, 17, 30, 0, time.UTC) // Friday
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@hourly", time.Date(2024, 6, 7, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC)},
+		{"*/30 * * * *", time.Date(2024, 6, 7, 10, 30, 0, 0, time.UTC)},
+		{"0 9-21/3 * * *", time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC)},
+		{"15 8 * * 1-5", time.Date(2024, 6, 10, 8, 15, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC)},
+		// Restricted day of month and day of week match either.
+		{"0 0 8 * 1", time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)},
+		{"@every 45m", from.Add(45 * time.Minute)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, s.Next(from))
+		})
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every 10s", "@every soon", "@yearly"} {
+		_, err := ParseSchedule(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestCronScheduleNeverFires(t *testing.T) {
+	s, err := ParseSchedule("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, s.Next(time.Now()).IsZero())
+}
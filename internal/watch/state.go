@@ -0,0 +1,108 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Snapshot is what a query has found so far, keyed by magnet hash.
+type Snapshot struct {
+	CheckedAt time.Time               `json:"checked_at"`
+	Torrents  map[string]TorrentState `json:"torrents"`
+}
+
+// TorrentState is the part of a torrent the diff compares.
+type TorrentState struct {
+	Name      string `json:"name"`
+	MovieHash string `json:"movie_hash"`
+	Quality   int    `json:"quality"`
+	// Seeds is the count last reported in an event (or first seen), so slow
+	// drift adds up until it crosses the threshold.
+	Seeds     int32     `json:"seeds"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Store keeps one Snapshot per query name between runs.
+type Store interface {
+	Load(ctx context.Context, query string) (*Snapshot, bool, error)
+	Save(ctx context.Context, query string, snapshot *Snapshot) error
+}
+
+// FileStore keeps all snapshots in a single JSON file. Writes go to a
+// temporary file first, so an interrupted save never corrupts the state.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(_ context.Context, query string) (*Snapshot, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return nil, false, err
+	}
+	snapshot, ok := all[query]
+	return snapshot, ok, nil
+}
+
+func (s *FileStore) Save(_ context.Context, query string, snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	all[query] = snapshot
+
+	raw, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode watch state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) read() (map[string]*Snapshot, error) {
+	all := make(map[string]*Snapshot)
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read watch state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, fmt.Errorf("parse watch state %s: %w", s.path, err)
+	}
+	return all, nil
+}
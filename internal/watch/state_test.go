@@ -0,0 +1,49 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := NewFileStore(path)
+
+	_, found, err := store.Load(ctx, "bad boys")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	at := time.Date(2024, 6, 7, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(ctx, "bad boys", &Snapshot{CheckedAt: at, Torrents: map[string]TorrentState{
+		"aaa": {Name: "Bad Boys", Seeds: 12, FirstSeen: at, LastSeen: at},
+	}}))
+	require.NoError(t, store.Save(ctx, "the bear", &Snapshot{CheckedAt: at}))
+
+	snapshot, found, err := NewFileStore(path).Load(ctx, "bad boys")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, at, snapshot.CheckedAt)
+	require.EqualValues(t, 12, snapshot.Torrents["aaa"].Seeds)
+
+	_, found, err = store.Load(ctx, "the bear")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files are cleaned up")
+}
+
+func TestFileStoreCorruptState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, _, err := NewFileStore(path).Load(context.Background(), "q")
+	require.ErrorContains(t, err, "parse watch state")
+}
@@ -0,0 +1,156 @@
+// Package watch reruns saved tracker searches on a schedule and reports what
+// changed since the previous run.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+)
+
+// SearchFunc runs the tracker pipeline for one query and returns its
+// deduplicated torrents.
+type SearchFunc func(ctx context.Context, q Query) ([]*torrents.Torrent, error)
+
+// CheckFunc runs one query and returns what changed since its previous run,
+// see SearchCheck and Detect.
+type CheckFunc func(ctx context.Context, q Query) ([]Event, error)
+
+// Handler receives the events of one query run; it is not called for runs
+// without events.
+type Handler func(ctx context.Context, q Query, events []Event)
+
+// Watcher runs the saved queries of a Config on their schedules.
+type Watcher struct {
+	config    *Config
+	schedules map[string]Schedule
+	check     CheckFunc
+	handle    Handler
+	now       func() time.Time
+}
+
+// SearchCheck diffs the results of search against the snapshots in store.
+func SearchCheck(search SearchFunc, store Store, minSeedDelta int32) CheckFunc {
+	return func(ctx context.Context, q Query) ([]Event, error) {
+		results, err := search(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return Detect(ctx, store, q.Name, results, minSeedDelta, time.Now())
+	}
+}
+
+// Detect diffs results against the stored snapshot of query and saves the new
+// one. The first run of a query only records a baseline and returns no events.
+func Detect(ctx context.Context, store Store, query string, results []*torrents.Torrent, minSeedDelta int32, now time.Time) ([]Event, error) {
+	events, _, err := DetectWithBaseline(ctx, store, query, results, minSeedDelta, now)
+	return events, err
+}
+
+// DetectWithBaseline is Detect that also returns the results of a query's
+// first run, as new_release events in baseline, for callers that act on what
+// was already out when the query was added, e.g. download rules. baseline is
+// nil on every later run.
+func DetectWithBaseline(ctx context.Context, store Store, query string, results []*torrents.Torrent, minSeedDelta int32, now time.Time) (events, baseline []Event, err error) {
+	previous, found, err := store.Load(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, snapshot := Diff(query, previous, results, minSeedDelta, now)
+	if err := store.Save(ctx, query, snapshot); err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		slog.InfoContext(ctx, "watch baseline recorded", "query", query, "torrents", len(snapshot.Torrents))
+		return nil, events, nil
+	}
+	return events, nil, nil
+}
+
+// New prepares a watcher for a validated config (see LoadConfig).
+func New(cfg *Config, check CheckFunc, handle Handler) (*Watcher, error) {
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	schedules := make(map[string]Schedule, len(cfg.Queries))
+	for _, q := range cfg.Queries {
+		s, err := ParseSchedule(q.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		schedules[q.Name] = s
+	}
+	return &Watcher{config: cfg, schedules: schedules, check: check, handle: handle, now: time.Now}, nil
+}
+
+// RunOnce checks every query once, e.g. from a system cron job.
+func (w *Watcher) RunOnce(ctx context.Context) error {
+	var errs []error
+	for _, q := range w.config.Queries {
+		if err := w.Check(ctx, q); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run checks each query whenever its schedule fires until ctx is done. Failed
+// runs are logged and retried at the next scheduled time.
+func (w *Watcher) Run(ctx context.Context) error {
+	next := make(map[string]time.Time, len(w.config.Queries))
+	for _, q := range w.config.Queries {
+		next[q.Name] = w.schedules[q.Name].Next(w.now())
+	}
+
+	for {
+		due, at := w.earliest(next)
+		if at.IsZero() {
+			return errors.New("watch: no query is scheduled to run again")
+		}
+		slog.DebugContext(ctx, "next watch run", "query", due.Name, "at", at)
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := w.Check(ctx, due); err != nil {
+			slog.ErrorContext(ctx, "watch run failed", "query", due.Name, "error", err)
+		}
+		next[due.Name] = w.schedules[due.Name].Next(w.now())
+	}
+}
+
+func (w *Watcher) earliest(next map[string]time.Time) (Query, time.Time) {
+	var (
+		due Query
+		at  time.Time
+	)
+	for _, q := range w.config.Queries {
+		t := next[q.Name]
+		if !t.IsZero() && (at.IsZero() || t.Before(at)) {
+			due, at = q, t
+		}
+	}
+	return due, at
+}
+
+// Check runs one query and passes its events to the handler.
+func (w *Watcher) Check(ctx context.Context, q Query) error {
+	events, err := w.check(ctx, q)
+	if err != nil {
+		return fmt.Errorf("watch %q: %w", q.Name, err)
+	}
+	slog.InfoContext(ctx, "watch run completed", "query", q.Name, "events", len(events))
+	if len(events) > 0 && w.handle != nil {
+		w.handle(ctx, q, events)
+	}
+	return nil
+}
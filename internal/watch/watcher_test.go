@@ -0,0 +1,120 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lieranderl/moviestracker-package/internal/torrents"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"schedule": "*/30 * * * *",
+		"queries": [
+			{"query": "Bad Boys", "year": "2024"},
+			{"name": "the-bear", "query": "Медведь", "year": "2024", "movie": false, "schedule": "@daily"},
+			{"query": "Alien", "download": {"resolution": ["2160P"], "category": "movies", "tags": ["watch"]}}
+		]
+	}`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.EqualValues(t, DefaultMinSeedDelta, cfg.MinSeedDelta)
+	require.Len(t, cfg.Queries, 3)
+	require.Equal(t, "Bad Boys 2024", cfg.Queries[0].Name)
+	require.Equal(t, "*/30 * * * *", cfg.Queries[0].Schedule)
+	require.True(t, cfg.Queries[0].IsMovie())
+	require.Equal(t, "@daily", cfg.Queries[1].Schedule)
+	require.False(t, cfg.Queries[1].IsMovie())
+	require.Nil(t, cfg.Queries[0].Download)
+	require.Equal(t, &DownloadRule{Rule: Rule{Resolution: []string{"2160p"}}, Category: "movies", Tags: []string{"watch"}}, cfg.Queries[2].Download)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for name, body := range map[string]string{
+		"no queries": `{"queries": []}`,
+		"empty":      `{"queries": [{"query": " "}]}`,
+		"duplicate":  `{"queries": [{"query": "a"}, {"query": "a"}]}`,
+		"schedule":   `{"queries": [{"query": "a", "schedule": "sometimes"}]}`,
+		"download":   `{"queries": [{"query": "a", "download": {"hdr": ["hlg"]}}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "watch.json")
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+		_, err := LoadConfig(path)
+		require.Error(t, err, name)
+	}
+}
+
+func TestWatcherCheck(t *testing.T) {
+	ctx := context.Background()
+	results := []*torrents.Torrent{{Name: "Bad Boys 1080p", Hash: "movie", MagnetHash: "aaa", FHD: true, Seeds: 10}}
+	search := func(context.Context, Query) ([]*torrents.Torrent, error) { return results, nil }
+
+	var got []Event
+	handle := func(_ context.Context, _ Query, events []Event) { got = append(got, events...) }
+
+	cfg := &Config{Queries: []Query{{Query: "Bad Boys", Year: "2024"}}}
+	w, err := New(cfg, SearchCheck(search, NewFileStore(filepath.Join(t.TempDir(), "state.json")), cfg.MinSeedDelta), handle)
+	require.NoError(t, err)
+
+	// The first run only records what already exists.
+	require.NoError(t, w.RunOnce(ctx))
+	require.Empty(t, got)
+
+	results = append(results, &torrents.Torrent{Name: "Bad Boys 2160p", Hash: "movie", MagnetHash: "bbb", K4: true, Seeds: 3})
+	require.NoError(t, w.RunOnce(ctx))
+	require.Len(t, got, 1)
+	require.Equal(t, EventBetterQuality, got[0].Type)
+	require.Equal(t, "Bad Boys 2024", got[0].Query)
+
+	got = nil
+	require.NoError(t, w.RunOnce(ctx))
+	require.Empty(t, got)
+}
+
+func TestWatcherRunOnceJoinsErrors(t *testing.T) {
+	search := func(_ context.Context, q Query) ([]*torrents.Torrent, error) {
+		if q.Name == "broken" {
+			return nil, errors.New("tracker down")
+		}
+		return nil, nil
+	}
+	cfg := &Config{Queries: []Query{{Name: "broken", Query: "a"}, {Name: "ok", Query: "b"}}}
+	store := NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	w, err := New(cfg, SearchCheck(search, store, cfg.MinSeedDelta), nil)
+	require.NoError(t, err)
+
+	err = w.RunOnce(context.Background())
+	require.ErrorContains(t, err, `watch "broken": tracker down`)
+
+	_, found, err := store.Load(context.Background(), "ok")
+	require.NoError(t, err)
+	require.True(t, found, "other queries still run")
+}
+
+func TestWatcherRunFollowsSchedule(t *testing.T) {
+	runs := make(chan string, 4)
+	search := func(_ context.Context, q Query) ([]*torrents.Torrent, error) {
+		runs <- q.Name
+		return nil, nil
+	}
+	cfg := &Config{Queries: []Query{{Name: "q", Query: "a", Schedule: "@every 1m"}}}
+	w, err := New(cfg, SearchCheck(search, NewFileStore(filepath.Join(t.TempDir(), "state.json")), cfg.MinSeedDelta), nil)
+	require.NoError(t, err)
+	w.schedules["q"] = every(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	require.Equal(t, "q", <-runs)
+	require.Equal(t, "q", <-runs)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
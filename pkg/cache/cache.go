@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores opaque values by key for a limited time. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key. The boolean is false when the key
+	// is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl. A ttl <= 0 means the entry never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+func expiresAt(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func expired(now, expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
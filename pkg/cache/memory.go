@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultMemoryCapacity = 10000
+
+// Memory is an in-process LRU cache. When full, the least recently used entry
+// is evicted.
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewMemory(capacity int) *Memory {
+	if capacity < 1 {
+		capacity = DefaultMemoryCapacity
+	}
+	return &Memory{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element, capacity),
+		now:      time.Now,
+	}
+}
+
+func (c *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if expired(c.now(), entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (c *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{
+		key:       key,
+		value:     append([]byte(nil), value...),
+		expiresAt: expiresAt(c.now(), ttl),
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+func (c *Memory) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory(2)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+	_, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), 0))
+
+	_, ok, _ = c.Get(ctx, "b")
+	require.False(t, ok)
+	v, ok, _ := c.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), v)
+	require.Equal(t, 2, c.Len())
+}
+
+func TestMemoryExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemory(10)
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "k", []byte("v"), time.Hour))
+
+	_, ok, _ := c.Get(ctx, "k")
+	require.True(t, ok)
+
+	now = now.Add(time.Hour)
+	_, ok, _ = c.Get(ctx, "k")
+	require.False(t, ok)
+	require.Zero(t, c.Len())
+}
+
+func TestMemoryOverwriteKeepsSingleEntry(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory(0)
+
+	require.NoError(t, c.Set(ctx, "k", []byte("old"), 0))
+	require.NoError(t, c.Set(ctx, "k", []byte("new"), 0))
+
+	v, ok, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("new"), v)
+	require.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Mongo keeps entries in a MongoDB collection so they survive between runs.
+// Expired documents are removed by a TTL index on expires_at; Get also ignores
+// them because MongoDB only purges expired documents once a minute.
+type Mongo struct {
+	collection *mongo.Collection
+	client     *mongo.Client
+}
+
+type mongoEntry struct {
+	Key       string    `bson:"_id"`
+	Value     []byte    `bson:"value"`
+	ExpiresAt time.Time `bson:"expires_at,omitempty"`
+}
+
+func NewMongo(collection *mongo.Collection) *Mongo {
+	return &Mongo{collection: collection}
+}
+
+// ConnectMongo connects to uri and returns a cache backed by database.collection
+// with its TTL index in place. Close releases the connection.
+func ConnectMongo(ctx context.Context, uri, database, collection string) (*Mongo, error) {
+	client, err := mongo.Connect(options.Client().ApplyURI(uri).SetServerSelectionTimeout(10 * time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("connect mongo cache: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("ping mongo cache: %w", err)
+	}
+
+	c := NewMongo(client.Database(database).Collection(collection))
+	c.client = client
+	if err := c.EnsureIndexes(ctx); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("create mongo cache ttl index: %w", err)
+	}
+	return nil
+}
+
+func (c *Mongo) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var entry mongoEntry
+	err := c.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read mongo cache %q: %w", key, err)
+	}
+	if expired(time.Now(), entry.ExpiresAt) {
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+func (c *Mongo) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := mongoEntry{Key: key, Value: value, ExpiresAt: expiresAt(time.Now(), ttl)}
+	_, err := c.collection.ReplaceOne(ctx, bson.M{"_id": key}, entry, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("write mongo cache %q: %w", key, err)
+	}
+	return nil
+}
+
+// Close disconnects the client opened by ConnectMongo. It is a no-op for caches
+// created with NewMongo.
+func (c *Mongo) Close(ctx context.Context) error {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Disconnect(ctx)
+}
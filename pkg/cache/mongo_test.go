@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMongoRoundTrip(t *testing.T) {
+	if os.Getenv("RUN_INTEGRATION_TESTS") != "1" {
+		t.Skip("integration test skipped: set RUN_INTEGRATION_TESTS=1 to enable")
+	}
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("integration test skipped: MONGO_URI is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := ConnectMongo(ctx, uri, "movies_test", "cache_test")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, c.Close(context.Background())) }()
+
+	require.NoError(t, c.Set(ctx, "k", []byte("v"), time.Minute))
+	v, ok, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("v"), v)
+
+	require.NoError(t, c.Set(ctx, "expired", []byte("v"), time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, ok, err = c.Get(ctx, "expired")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
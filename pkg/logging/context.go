@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type attrsKey struct{}
+
+// With returns a copy of ctx that carries args, slog key-value pairs or
+// Attrs, on top of the attributes ctx already carries; an arg replaces a
+// carried attribute with the same key. Loggers built by New add them to every
+// record logged with the context, e.g. by slog.InfoContext.
+func With(ctx context.Context, args ...any) context.Context {
+	var r slog.Record
+	r.Add(args...)
+	added := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		added = append(added, a)
+		return true
+	})
+
+	parent := attrs(ctx)
+	merged := make([]slog.Attr, 0, len(parent)+len(added))
+	for _, a := range parent {
+		if !slices.ContainsFunc(added, func(b slog.Attr) bool { return b.Key == a.Key }) {
+			merged = append(merged, a)
+		}
+	}
+	return context.WithValue(ctx, attrsKey{}, append(merged, added...))
+}
+
+func attrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	a, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return a
+}
+
+// NewRunID returns a random id that correlates the log lines of one run.
+func NewRunID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Handler wraps h so that records logged with a context also get the
+// attributes of With and, inside a span, its trace_id and span_id.
+func Handler(h slog.Handler) slog.Handler {
+	return contextHandler{h}
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	extra := attrs(ctx)
+	span := trace.SpanContextFromContext(ctx)
+	if len(extra) > 0 || span.IsValid() {
+		r = r.Clone()
+		r.AddAttrs(extra...)
+		if span.IsValid() {
+			r.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -1,17 +1,109 @@
+// Package logging configures the slog default logger and carries log
+// attributes in contexts.
 package logging
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// Config selects the level, format and destination of the logs.
+type Config struct {
+	Level slog.Level
+	// Format is "text" (default) or "json".
+	Format string
+	// Output is "stdout" (default), "stderr" or the path of a log file.
+	Output string
+	// A log file is rotated once it reaches MaxSizeMB (default 100), keeping
+	// MaxBackups old files (default 5, 0 keeps all) for MaxAgeDays (0 keeps
+	// them regardless of age).
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// ConfigFromEnv reads LOG_LEVEL, LOG_FORMAT, LOG_OUTPUT, LOG_MAX_SIZE_MB,
+// LOG_MAX_BACKUPS and LOG_MAX_AGE_DAYS.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Level:      parseLevel(os.Getenv("LOG_LEVEL")),
+		Format:     os.Getenv("LOG_FORMAT"),
+		Output:     os.Getenv("LOG_OUTPUT"),
+		MaxSizeMB:  100,
+		MaxBackups: 5,
+	}
+	var errs []error
+	for key, dst := range map[string]*int{
+		"LOG_MAX_SIZE_MB":  &cfg.MaxSizeMB,
+		"LOG_MAX_BACKUPS":  &cfg.MaxBackups,
+		"LOG_MAX_AGE_DAYS": &cfg.MaxAgeDays,
+	} {
+		value := strings.TrimSpace(os.Getenv(key))
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %q", key, value))
+			continue
+		}
+		*dst = n
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// New builds a logger from cfg. Its handler adds the attributes of With and
+// the trace and span ids of the context to every record, see Handler.
+func New(cfg Config) (*slog.Logger, error) {
+	var out io.Writer
+	switch output := strings.TrimSpace(cfg.Output); strings.ToLower(output) {
+	case "", "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		out = &lumberjack.Logger{
+			Filename:   output,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+	var h slog.Handler
+	switch strings.ToLower(strings.TrimSpace(cfg.Format)) {
+	case "", "text":
+		h = slog.NewTextHandler(out, opts)
+	case "json":
+		h = slog.NewJSONHandler(out, opts)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q (text|json)", cfg.Format)
+	}
+	return slog.New(Handler(h)), nil
+}
+
+// Init installs the logger configured by the environment (see ConfigFromEnv)
+// as the slog default. Invalid settings fall back to text logs on stdout and
+// are reported as a warning.
 func Init() *slog.Logger {
-	level := parseLevel(os.Getenv("LOG_LEVEL"))
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
+	cfg, err := ConfigFromEnv()
+	logger, newErr := New(cfg)
+	if newErr != nil {
+		logger, _ = New(Config{Level: cfg.Level})
+		err = errors.Join(err, newErr)
+	}
 	slog.SetDefault(logger)
+	if err != nil {
+		logger.Warn("invalid logging settings", "error", err)
+	}
 	return logger
 }
 
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_OUTPUT", "/var/log/moviestracker.log")
+	t.Setenv("LOG_MAX_SIZE_MB", "10")
+	t.Setenv("LOG_MAX_BACKUPS", "")
+	t.Setenv("LOG_MAX_AGE_DAYS", "7")
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, Config{Level: slog.LevelDebug, Format: "json", Output: "/var/log/moviestracker.log", MaxSizeMB: 10, MaxBackups: 5, MaxAgeDays: 7}, cfg)
+
+	t.Setenv("LOG_MAX_BACKUPS", "-1")
+	_, err = ConfigFromEnv()
+	require.EqualError(t, err, `invalid LOG_MAX_BACKUPS "-1"`)
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "moviestracker.log")
+	logger, err := New(Config{Format: "JSON", Output: path, MaxSizeMB: 1})
+	require.NoError(t, err)
+	logger.Info("pipeline completed", "torrents", 3)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(data, &line))
+	require.Equal(t, "pipeline completed", line["msg"])
+	require.EqualValues(t, 3, line["torrents"])
+
+	_, err = New(Config{Format: "logfmt"})
+	require.EqualError(t, err, `unsupported log format "logfmt" (text|json)`)
+}
+
+func TestContextAttributes(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(Handler(slog.NewJSONHandler(&out, nil)))
+
+	ctx := With(context.Background(), "run_id", "r1", "query", "Bad Boys", "stage", "trackers")
+	ctx = With(ctx, slog.String("stage", "tmdb"), "movie_hash", "abc")
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	logger.InfoContext(ctx, "movie matched")
+	logger.Info("no context")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	require.Equal(t, "r1", line["run_id"])
+	require.Equal(t, "Bad Boys", line["query"])
+	require.Equal(t, "tmdb", line["stage"])
+	require.Equal(t, "abc", line["movie_hash"])
+	require.Equal(t, traceID.String(), line["trace_id"])
+	require.Equal(t, spanID.String(), line["span_id"])
+	require.Equal(t, 1, strings.Count(lines[0], `"stage"`), "a later key replaces an earlier one")
+	require.NotContains(t, lines[1], "run_id")
+}
@@ -0,0 +1,908 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: moviestracker/v1/search.proto
+
+package moviestrackerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MediaType int32
+
+const (
+	// Unspecified searches movies.
+	MediaType_MEDIA_TYPE_UNSPECIFIED MediaType = 0
+	MediaType_MEDIA_TYPE_MOVIE       MediaType = 1
+	MediaType_MEDIA_TYPE_SERIES      MediaType = 2
+)
+
+// Enum value maps for MediaType.
+var (
+	MediaType_name = map[int32]string{
+		0: "MEDIA_TYPE_UNSPECIFIED",
+		1: "MEDIA_TYPE_MOVIE",
+		2: "MEDIA_TYPE_SERIES",
+	}
+	MediaType_value = map[string]int32{
+		"MEDIA_TYPE_UNSPECIFIED": 0,
+		"MEDIA_TYPE_MOVIE":       1,
+		"MEDIA_TYPE_SERIES":      2,
+	}
+)
+
+func (x MediaType) Enum() *MediaType {
+	p := new(MediaType)
+	*p = x
+	return p
+}
+
+func (x MediaType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MediaType) Descriptor() protoreflect.EnumDescriptor {
+	return file_moviestracker_v1_search_proto_enumTypes[0].Descriptor()
+}
+
+func (MediaType) Type() protoreflect.EnumType {
+	return &file_moviestracker_v1_search_proto_enumTypes[0]
+}
+
+func (x MediaType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MediaType.Descriptor instead.
+func (MediaType) EnumDescriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{0}
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Year          string                 `protobuf:"bytes,2,opt,name=year,proto3" json:"year,omitempty"`
+	Type          MediaType              `protobuf:"varint,3,opt,name=type,proto3,enum=moviestracker.v1.MediaType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_moviestracker_v1_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviestracker_v1_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetYear() string {
+	if x != nil {
+		return x.Year
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetType() MediaType {
+	if x != nil {
+		return x.Type
+	}
+	return MediaType_MEDIA_TYPE_UNSPECIFIED
+}
+
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tracker is "rutor" or "kinozal".
+	Tracker       string     `protobuf:"bytes,1,opt,name=tracker,proto3" json:"tracker,omitempty"`
+	Torrents      []*Torrent `protobuf:"bytes,2,rep,name=torrents,proto3" json:"torrents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_moviestracker_v1_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviestracker_v1_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchResponse) GetTracker() string {
+	if x != nil {
+		return x.Tracker
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetTorrents() []*Torrent {
+	if x != nil {
+		return x.Torrents
+	}
+	return nil
+}
+
+type Torrent struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DetailsUrl   string                 `protobuf:"bytes,2,opt,name=details_url,json=detailsUrl,proto3" json:"details_url,omitempty"`
+	OriginalName string                 `protobuf:"bytes,3,opt,name=original_name,json=originalName,proto3" json:"original_name,omitempty"`
+	RussianName  string                 `protobuf:"bytes,4,opt,name=russian_name,json=russianName,proto3" json:"russian_name,omitempty"`
+	Year         string                 `protobuf:"bytes,5,opt,name=year,proto3" json:"year,omitempty"`
+	// Size is in GB.
+	Size      float32                `protobuf:"fixed32,6,opt,name=size,proto3" json:"size,omitempty"`
+	Magnet    string                 `protobuf:"bytes,7,opt,name=magnet,proto3" json:"magnet,omitempty"`
+	Date      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=date,proto3" json:"date,omitempty"`
+	K4        bool                   `protobuf:"varint,9,opt,name=k4,proto3" json:"k4,omitempty"`
+	Fhd       bool                   `protobuf:"varint,10,opt,name=fhd,proto3" json:"fhd,omitempty"`
+	Hdr       bool                   `protobuf:"varint,11,opt,name=hdr,proto3" json:"hdr,omitempty"`
+	Hdr10     bool                   `protobuf:"varint,12,opt,name=hdr10,proto3" json:"hdr10,omitempty"`
+	Hdr10Plus bool                   `protobuf:"varint,13,opt,name=hdr10plus,proto3" json:"hdr10plus,omitempty"`
+	Dv        bool                   `protobuf:"varint,14,opt,name=dv,proto3" json:"dv,omitempty"`
+	Seeds     int32                  `protobuf:"varint,15,opt,name=seeds,proto3" json:"seeds,omitempty"`
+	Leeches   int32                  `protobuf:"varint,16,opt,name=leeches,proto3" json:"leeches,omitempty"`
+	// Hash identifies the movie the torrent belongs to.
+	Hash          string   `protobuf:"bytes,17,opt,name=hash,proto3" json:"hash,omitempty"`
+	MagnetHash    string   `protobuf:"bytes,18,opt,name=magnet_hash,json=magnetHash,proto3" json:"magnet_hash,omitempty"`
+	ImdbId        string   `protobuf:"bytes,19,opt,name=imdb_id,json=imdbId,proto3" json:"imdb_id,omitempty"`
+	KinopoiskId   string   `protobuf:"bytes,20,opt,name=kinopoisk_id,json=kinopoiskId,proto3" json:"kinopoisk_id,omitempty"`
+	Poster        string   `protobuf:"bytes,21,opt,name=poster,proto3" json:"poster,omitempty"`
+	Description   string   `protobuf:"bytes,22,opt,name=description,proto3" json:"description,omitempty"`
+	Files         []string `protobuf:"bytes,23,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Torrent) Reset() {
+	*x = Torrent{}
+	mi := &file_moviestracker_v1_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Torrent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Torrent) ProtoMessage() {}
+
+func (x *Torrent) ProtoReflect() protoreflect.Message {
+	mi := &file_moviestracker_v1_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Torrent.ProtoReflect.Descriptor instead.
+func (*Torrent) Descriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Torrent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Torrent) GetDetailsUrl() string {
+	if x != nil {
+		return x.DetailsUrl
+	}
+	return ""
+}
+
+func (x *Torrent) GetOriginalName() string {
+	if x != nil {
+		return x.OriginalName
+	}
+	return ""
+}
+
+func (x *Torrent) GetRussianName() string {
+	if x != nil {
+		return x.RussianName
+	}
+	return ""
+}
+
+func (x *Torrent) GetYear() string {
+	if x != nil {
+		return x.Year
+	}
+	return ""
+}
+
+func (x *Torrent) GetSize() float32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Torrent) GetMagnet() string {
+	if x != nil {
+		return x.Magnet
+	}
+	return ""
+}
+
+func (x *Torrent) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Torrent) GetK4() bool {
+	if x != nil {
+		return x.K4
+	}
+	return false
+}
+
+func (x *Torrent) GetFhd() bool {
+	if x != nil {
+		return x.Fhd
+	}
+	return false
+}
+
+func (x *Torrent) GetHdr() bool {
+	if x != nil {
+		return x.Hdr
+	}
+	return false
+}
+
+func (x *Torrent) GetHdr10() bool {
+	if x != nil {
+		return x.Hdr10
+	}
+	return false
+}
+
+func (x *Torrent) GetHdr10Plus() bool {
+	if x != nil {
+		return x.Hdr10Plus
+	}
+	return false
+}
+
+func (x *Torrent) GetDv() bool {
+	if x != nil {
+		return x.Dv
+	}
+	return false
+}
+
+func (x *Torrent) GetSeeds() int32 {
+	if x != nil {
+		return x.Seeds
+	}
+	return 0
+}
+
+func (x *Torrent) GetLeeches() int32 {
+	if x != nil {
+		return x.Leeches
+	}
+	return 0
+}
+
+func (x *Torrent) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Torrent) GetMagnetHash() string {
+	if x != nil {
+		return x.MagnetHash
+	}
+	return ""
+}
+
+func (x *Torrent) GetImdbId() string {
+	if x != nil {
+		return x.ImdbId
+	}
+	return ""
+}
+
+func (x *Torrent) GetKinopoiskId() string {
+	if x != nil {
+		return x.KinopoiskId
+	}
+	return ""
+}
+
+func (x *Torrent) GetPoster() string {
+	if x != nil {
+		return x.Poster
+	}
+	return ""
+}
+
+func (x *Torrent) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Torrent) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type Rating struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         float64                `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Votes         int32                  `protobuf:"varint,2,opt,name=votes,proto3" json:"votes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rating) Reset() {
+	*x = Rating{}
+	mi := &file_moviestracker_v1_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rating) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rating) ProtoMessage() {}
+
+func (x *Rating) ProtoReflect() protoreflect.Message {
+	mi := &file_moviestracker_v1_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rating.ProtoReflect.Descriptor instead.
+func (*Rating) Descriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Rating) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Rating) GetVotes() int32 {
+	if x != nil {
+		return x.Votes
+	}
+	return 0
+}
+
+type Genres struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Genres) Reset() {
+	*x = Genres{}
+	mi := &file_moviestracker_v1_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Genres) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Genres) ProtoMessage() {}
+
+func (x *Genres) ProtoReflect() protoreflect.Message {
+	mi := &file_moviestracker_v1_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Genres.ProtoReflect.Descriptor instead.
+func (*Genres) Descriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Genres) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+type Movie struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title           string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	OriginalTitle   string                 `protobuf:"bytes,3,opt,name=original_title,json=originalTitle,proto3" json:"original_title,omitempty"`
+	Year            int32                  `protobuf:"varint,4,opt,name=year,proto3" json:"year,omitempty"`
+	ReleaseDate     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=release_date,json=releaseDate,proto3" json:"release_date,omitempty"`
+	VoteAverage     float64                `protobuf:"fixed64,6,opt,name=vote_average,json=voteAverage,proto3" json:"vote_average,omitempty"`
+	VoteCount       int32                  `protobuf:"varint,7,opt,name=vote_count,json=voteCount,proto3" json:"vote_count,omitempty"`
+	PosterPath      string                 `protobuf:"bytes,8,opt,name=poster_path,json=posterPath,proto3" json:"poster_path,omitempty"`
+	BackdropPath    string                 `protobuf:"bytes,9,opt,name=backdrop_path,json=backdropPath,proto3" json:"backdrop_path,omitempty"`
+	GenreIds        []int32                `protobuf:"varint,10,rep,packed,name=genre_ids,json=genreIds,proto3" json:"genre_ids,omitempty"`
+	Hash            string                 `protobuf:"bytes,11,opt,name=hash,proto3" json:"hash,omitempty"`
+	Searchname      string                 `protobuf:"bytes,12,opt,name=searchname,proto3" json:"searchname,omitempty"`
+	LastTimeFound   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_time_found,json=lastTimeFound,proto3" json:"last_time_found,omitempty"`
+	ImdbId          string                 `protobuf:"bytes,14,opt,name=imdb_id,json=imdbId,proto3" json:"imdb_id,omitempty"`
+	KinopoiskId     string                 `protobuf:"bytes,15,opt,name=kinopoisk_id,json=kinopoiskId,proto3" json:"kinopoisk_id,omitempty"`
+	MatchStrategy   string                 `protobuf:"bytes,16,opt,name=match_strategy,json=matchStrategy,proto3" json:"match_strategy,omitempty"`
+	MatchConfidence float64                `protobuf:"fixed64,17,opt,name=match_confidence,json=matchConfidence,proto3" json:"match_confidence,omitempty"`
+	LowConfidence   bool                   `protobuf:"varint,18,opt,name=low_confidence,json=lowConfidence,proto3" json:"low_confidence,omitempty"`
+	// Titles, overviews, posters and genres are keyed by language, ratings by
+	// source (tmdb, imdb, kinopoisk).
+	Titles        map[string]string  `protobuf:"bytes,19,rep,name=titles,proto3" json:"titles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Overviews     map[string]string  `protobuf:"bytes,20,rep,name=overviews,proto3" json:"overviews,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Posters       map[string]string  `protobuf:"bytes,21,rep,name=posters,proto3" json:"posters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Genres        map[string]*Genres `protobuf:"bytes,22,rep,name=genres,proto3" json:"genres,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Ratings       map[string]*Rating `protobuf:"bytes,23,rep,name=ratings,proto3" json:"ratings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Torrents      []*Torrent         `protobuf:"bytes,24,rep,name=torrents,proto3" json:"torrents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Movie) Reset() {
+	*x = Movie{}
+	mi := &file_moviestracker_v1_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Movie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Movie) ProtoMessage() {}
+
+func (x *Movie) ProtoReflect() protoreflect.Message {
+	mi := &file_moviestracker_v1_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Movie.ProtoReflect.Descriptor instead.
+func (*Movie) Descriptor() ([]byte, []int) {
+	return file_moviestracker_v1_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Movie) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Movie) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Movie) GetOriginalTitle() string {
+	if x != nil {
+		return x.OriginalTitle
+	}
+	return ""
+}
+
+func (x *Movie) GetYear() int32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Movie) GetReleaseDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReleaseDate
+	}
+	return nil
+}
+
+func (x *Movie) GetVoteAverage() float64 {
+	if x != nil {
+		return x.VoteAverage
+	}
+	return 0
+}
+
+func (x *Movie) GetVoteCount() int32 {
+	if x != nil {
+		return x.VoteCount
+	}
+	return 0
+}
+
+func (x *Movie) GetPosterPath() string {
+	if x != nil {
+		return x.PosterPath
+	}
+	return ""
+}
+
+func (x *Movie) GetBackdropPath() string {
+	if x != nil {
+		return x.BackdropPath
+	}
+	return ""
+}
+
+func (x *Movie) GetGenreIds() []int32 {
+	if x != nil {
+		return x.GenreIds
+	}
+	return nil
+}
+
+func (x *Movie) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Movie) GetSearchname() string {
+	if x != nil {
+		return x.Searchname
+	}
+	return ""
+}
+
+func (x *Movie) GetLastTimeFound() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastTimeFound
+	}
+	return nil
+}
+
+func (x *Movie) GetImdbId() string {
+	if x != nil {
+		return x.ImdbId
+	}
+	return ""
+}
+
+func (x *Movie) GetKinopoiskId() string {
+	if x != nil {
+		return x.KinopoiskId
+	}
+	return ""
+}
+
+func (x *Movie) GetMatchStrategy() string {
+	if x != nil {
+		return x.MatchStrategy
+	}
+	return ""
+}
+
+func (x *Movie) GetMatchConfidence() float64 {
+	if x != nil {
+		return x.MatchConfidence
+	}
+	return 0
+}
+
+func (x *Movie) GetLowConfidence() bool {
+	if x != nil {
+		return x.LowConfidence
+	}
+	return false
+}
+
+func (x *Movie) GetTitles() map[string]string {
+	if x != nil {
+		return x.Titles
+	}
+	return nil
+}
+
+func (x *Movie) GetOverviews() map[string]string {
+	if x != nil {
+		return x.Overviews
+	}
+	return nil
+}
+
+func (x *Movie) GetPosters() map[string]string {
+	if x != nil {
+		return x.Posters
+	}
+	return nil
+}
+
+func (x *Movie) GetGenres() map[string]*Genres {
+	if x != nil {
+		return x.Genres
+	}
+	return nil
+}
+
+func (x *Movie) GetRatings() map[string]*Rating {
+	if x != nil {
+		return x.Ratings
+	}
+	return nil
+}
+
+func (x *Movie) GetTorrents() []*Torrent {
+	if x != nil {
+		return x.Torrents
+	}
+	return nil
+}
+
+var File_moviestracker_v1_search_proto protoreflect.FileDescriptor
+
+const file_moviestracker_v1_search_proto_rawDesc = "" +
+	"\n" +
+	"\x1dmoviestracker/v1/search.proto\x12\x10moviestracker.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"j\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04year\x18\x02 \x01(\tR\x04year\x12/\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x1b.moviestracker.v1.MediaTypeR\x04type\"a\n" +
+	"\x0eSearchResponse\x12\x18\n" +
+	"\atracker\x18\x01 \x01(\tR\atracker\x125\n" +
+	"\btorrents\x18\x02 \x03(\v2\x19.moviestracker.v1.TorrentR\btorrents\"\xdf\x04\n" +
+	"\aTorrent\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\vdetails_url\x18\x02 \x01(\tR\n" +
+	"detailsUrl\x12#\n" +
+	"\roriginal_name\x18\x03 \x01(\tR\foriginalName\x12!\n" +
+	"\frussian_name\x18\x04 \x01(\tR\vrussianName\x12\x12\n" +
+	"\x04year\x18\x05 \x01(\tR\x04year\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x02R\x04size\x12\x16\n" +
+	"\x06magnet\x18\a \x01(\tR\x06magnet\x12.\n" +
+	"\x04date\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x0e\n" +
+	"\x02k4\x18\t \x01(\bR\x02k4\x12\x10\n" +
+	"\x03fhd\x18\n" +
+	" \x01(\bR\x03fhd\x12\x10\n" +
+	"\x03hdr\x18\v \x01(\bR\x03hdr\x12\x14\n" +
+	"\x05hdr10\x18\f \x01(\bR\x05hdr10\x12\x1c\n" +
+	"\thdr10plus\x18\r \x01(\bR\thdr10plus\x12\x0e\n" +
+	"\x02dv\x18\x0e \x01(\bR\x02dv\x12\x14\n" +
+	"\x05seeds\x18\x0f \x01(\x05R\x05seeds\x12\x18\n" +
+	"\aleeches\x18\x10 \x01(\x05R\aleeches\x12\x12\n" +
+	"\x04hash\x18\x11 \x01(\tR\x04hash\x12\x1f\n" +
+	"\vmagnet_hash\x18\x12 \x01(\tR\n" +
+	"magnetHash\x12\x17\n" +
+	"\aimdb_id\x18\x13 \x01(\tR\x06imdbId\x12!\n" +
+	"\fkinopoisk_id\x18\x14 \x01(\tR\vkinopoiskId\x12\x16\n" +
+	"\x06poster\x18\x15 \x01(\tR\x06poster\x12 \n" +
+	"\vdescription\x18\x16 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05files\x18\x17 \x03(\tR\x05files\"4\n" +
+	"\x06Rating\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x14\n" +
+	"\x05votes\x18\x02 \x01(\x05R\x05votes\"\x1e\n" +
+	"\x06Genres\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"\xd0\n" +
+	"\n" +
+	"\x05Movie\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12%\n" +
+	"\x0eoriginal_title\x18\x03 \x01(\tR\roriginalTitle\x12\x12\n" +
+	"\x04year\x18\x04 \x01(\x05R\x04year\x12=\n" +
+	"\frelease_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vreleaseDate\x12!\n" +
+	"\fvote_average\x18\x06 \x01(\x01R\vvoteAverage\x12\x1d\n" +
+	"\n" +
+	"vote_count\x18\a \x01(\x05R\tvoteCount\x12\x1f\n" +
+	"\vposter_path\x18\b \x01(\tR\n" +
+	"posterPath\x12#\n" +
+	"\rbackdrop_path\x18\t \x01(\tR\fbackdropPath\x12\x1b\n" +
+	"\tgenre_ids\x18\n" +
+	" \x03(\x05R\bgenreIds\x12\x12\n" +
+	"\x04hash\x18\v \x01(\tR\x04hash\x12\x1e\n" +
+	"\n" +
+	"searchname\x18\f \x01(\tR\n" +
+	"searchname\x12B\n" +
+	"\x0flast_time_found\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\rlastTimeFound\x12\x17\n" +
+	"\aimdb_id\x18\x0e \x01(\tR\x06imdbId\x12!\n" +
+	"\fkinopoisk_id\x18\x0f \x01(\tR\vkinopoiskId\x12%\n" +
+	"\x0ematch_strategy\x18\x10 \x01(\tR\rmatchStrategy\x12)\n" +
+	"\x10match_confidence\x18\x11 \x01(\x01R\x0fmatchConfidence\x12%\n" +
+	"\x0elow_confidence\x18\x12 \x01(\bR\rlowConfidence\x12;\n" +
+	"\x06titles\x18\x13 \x03(\v2#.moviestracker.v1.Movie.TitlesEntryR\x06titles\x12D\n" +
+	"\toverviews\x18\x14 \x03(\v2&.moviestracker.v1.Movie.OverviewsEntryR\toverviews\x12>\n" +
+	"\aposters\x18\x15 \x03(\v2$.moviestracker.v1.Movie.PostersEntryR\aposters\x12;\n" +
+	"\x06genres\x18\x16 \x03(\v2#.moviestracker.v1.Movie.GenresEntryR\x06genres\x12>\n" +
+	"\aratings\x18\x17 \x03(\v2$.moviestracker.v1.Movie.RatingsEntryR\aratings\x125\n" +
+	"\btorrents\x18\x18 \x03(\v2\x19.moviestracker.v1.TorrentR\btorrents\x1a9\n" +
+	"\vTitlesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a<\n" +
+	"\x0eOverviewsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fPostersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aS\n" +
+	"\vGenresEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
+	"\x05value\x18\x02 \x01(\v2\x18.moviestracker.v1.GenresR\x05value:\x028\x01\x1aT\n" +
+	"\fRatingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
+	"\x05value\x18\x02 \x01(\v2\x18.moviestracker.v1.RatingR\x05value:\x028\x01*T\n" +
+	"\tMediaType\x12\x1a\n" +
+	"\x16MEDIA_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10MEDIA_TYPE_MOVIE\x10\x01\x12\x15\n" +
+	"\x11MEDIA_TYPE_SERIES\x10\x022^\n" +
+	"\rSearchService\x12M\n" +
+	"\x06Search\x12\x1f.moviestracker.v1.SearchRequest\x1a .moviestracker.v1.SearchResponse0\x01BUZSgithub.com/lieranderl/moviestracker-package/pkg/pb/moviestracker/v1;moviestrackerv1b\x06proto3"
+
+var (
+	file_moviestracker_v1_search_proto_rawDescOnce sync.Once
+	file_moviestracker_v1_search_proto_rawDescData []byte
+)
+
+func file_moviestracker_v1_search_proto_rawDescGZIP() []byte {
+	file_moviestracker_v1_search_proto_rawDescOnce.Do(func() {
+		file_moviestracker_v1_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_moviestracker_v1_search_proto_rawDesc), len(file_moviestracker_v1_search_proto_rawDesc)))
+	})
+	return file_moviestracker_v1_search_proto_rawDescData
+}
+
+var file_moviestracker_v1_search_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_moviestracker_v1_search_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_moviestracker_v1_search_proto_goTypes = []any{
+	(MediaType)(0),                // 0: moviestracker.v1.MediaType
+	(*SearchRequest)(nil),         // 1: moviestracker.v1.SearchRequest
+	(*SearchResponse)(nil),        // 2: moviestracker.v1.SearchResponse
+	(*Torrent)(nil),               // 3: moviestracker.v1.Torrent
+	(*Rating)(nil),                // 4: moviestracker.v1.Rating
+	(*Genres)(nil),                // 5: moviestracker.v1.Genres
+	(*Movie)(nil),                 // 6: moviestracker.v1.Movie
+	nil,                           // 7: moviestracker.v1.Movie.TitlesEntry
+	nil,                           // 8: moviestracker.v1.Movie.OverviewsEntry
+	nil,                           // 9: moviestracker.v1.Movie.PostersEntry
+	nil,                           // 10: moviestracker.v1.Movie.GenresEntry
+	nil,                           // 11: moviestracker.v1.Movie.RatingsEntry
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_moviestracker_v1_search_proto_depIdxs = []int32{
+	0,  // 0: moviestracker.v1.SearchRequest.type:type_name -> moviestracker.v1.MediaType
+	3,  // 1: moviestracker.v1.SearchResponse.torrents:type_name -> moviestracker.v1.Torrent
+	12, // 2: moviestracker.v1.Torrent.date:type_name -> google.protobuf.Timestamp
+	12, // 3: moviestracker.v1.Movie.release_date:type_name -> google.protobuf.Timestamp
+	12, // 4: moviestracker.v1.Movie.last_time_found:type_name -> google.protobuf.Timestamp
+	7,  // 5: moviestracker.v1.Movie.titles:type_name -> moviestracker.v1.Movie.TitlesEntry
+	8,  // 6: moviestracker.v1.Movie.overviews:type_name -> moviestracker.v1.Movie.OverviewsEntry
+	9,  // 7: moviestracker.v1.Movie.posters:type_name -> moviestracker.v1.Movie.PostersEntry
+	10, // 8: moviestracker.v1.Movie.genres:type_name -> moviestracker.v1.Movie.GenresEntry
+	11, // 9: moviestracker.v1.Movie.ratings:type_name -> moviestracker.v1.Movie.RatingsEntry
+	3,  // 10: moviestracker.v1.Movie.torrents:type_name -> moviestracker.v1.Torrent
+	5,  // 11: moviestracker.v1.Movie.GenresEntry.value:type_name -> moviestracker.v1.Genres
+	4,  // 12: moviestracker.v1.Movie.RatingsEntry.value:type_name -> moviestracker.v1.Rating
+	1,  // 13: moviestracker.v1.SearchService.Search:input_type -> moviestracker.v1.SearchRequest
+	2,  // 14: moviestracker.v1.SearchService.Search:output_type -> moviestracker.v1.SearchResponse
+	14, // [14:15] is the sub-list for method output_type
+	13, // [13:14] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_moviestracker_v1_search_proto_init() }
+func file_moviestracker_v1_search_proto_init() {
+	if File_moviestracker_v1_search_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_moviestracker_v1_search_proto_rawDesc), len(file_moviestracker_v1_search_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_moviestracker_v1_search_proto_goTypes,
+		DependencyIndexes: file_moviestracker_v1_search_proto_depIdxs,
+		EnumInfos:         file_moviestracker_v1_search_proto_enumTypes,
+		MessageInfos:      file_moviestracker_v1_search_proto_msgTypes,
+	}.Build()
+	File_moviestracker_v1_search_proto = out.File
+	file_moviestracker_v1_search_proto_goTypes = nil
+	file_moviestracker_v1_search_proto_depIdxs = nil
+}
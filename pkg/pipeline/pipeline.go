@@ -4,14 +4,30 @@ import (
 	"context"
 	"sync"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/sync/semaphore"
 )
 
+var tracer = otel.Tracer("github.com/lieranderl/moviestracker-package/pkg/pipeline")
+
 func Step[In any, Out any](
 	ctx context.Context,
 	inputChannel <-chan In,
 	fn func(In) (Out, error),
 	limit int64,
+) (chan Out, chan error) {
+	return StepContext(ctx, inputChannel, func(_ context.Context, s In) (Out, error) { return fn(s) }, limit)
+}
+
+// StepContext is Step for functions that take a context. Every call runs in
+// its own "pipeline.step" span, and fn gets the span's context so the spans
+// it starts nest under it.
+func StepContext[In any, Out any](
+	ctx context.Context,
+	inputChannel <-chan In,
+	fn func(context.Context, In) (Out, error),
+	limit int64,
 ) (chan Out, chan error) {
 	if limit < 1 {
 		limit = 1
@@ -24,10 +40,14 @@ func Step[In any, Out any](
 	// can see the values being processed in batches of 2 at a time, in parallel
 	// limit := int64(runtime.NumCPU())
 	sem1 := semaphore.NewWeighted(limit)
+	// workers is waited for before the channels are closed, so a worker that
+	// finishes after ctx is done never sends on a closed channel.
+	var workers sync.WaitGroup
 
 	go func() {
 		defer close(outputChannel)
 		defer close(errorChannel)
+		defer workers.Wait()
 
 		for {
 			var s In
@@ -56,10 +76,18 @@ func Step[In any, Out any](
 				return
 			}
 
+			workers.Add(1)
 			go func(s In) {
+				defer workers.Done()
 				defer sem1.Release(1)
 
-				result, err := fn(s)
+				spanCtx, span := tracer.Start(ctx, "pipeline.step")
+				result, err := fn(spanCtx, s)
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				}
+				span.End()
 				if err != nil {
 					select {
 					case errorChannel <- err:
@@ -3,9 +3,15 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestProducerEmitsAllValues(t *testing.T) {
@@ -54,6 +60,66 @@ func TestStepProcessesValues(t *testing.T) {
 	require.ElementsMatch(t, []int{2, 4, 6}, results)
 }
 
+// spanRecorder installs the global tracer provider once: the package tracer
+// only picks up the first one.
+var spanRecorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+})
+
+func TestStepContextNestsSpans(t *testing.T) {
+	recorder := spanRecorder()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "stage")
+	input, err := Producer(ctx, []int{1, 2})
+	require.NoError(t, err)
+
+	out, errs := StepContext(ctx, input, func(ctx context.Context, v int) (int, error) {
+		_, span := otel.Tracer("test").Start(ctx, "visit")
+		defer span.End()
+		if v == 2 {
+			return 0, errors.New("boom")
+		}
+		return v, nil
+	}, 2)
+	for out != nil || errs != nil {
+		select {
+		case _, ok := <-out:
+			if !ok {
+				out = nil
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+		}
+	}
+	parent.End()
+
+	steps := make(map[trace.SpanID]sdktrace.ReadOnlySpan)
+	failed := 0
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			continue
+		}
+		if s.Name() == "pipeline.step" {
+			require.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+			steps[s.SpanContext().SpanID()] = s
+			if len(s.Events()) > 0 {
+				failed++
+			}
+		}
+	}
+	require.Len(t, steps, 2)
+	require.Equal(t, 1, failed, "the failing call records its error")
+	for _, s := range recorder.Ended() {
+		if s.Name() == "visit" && s.SpanContext().TraceID() == parent.SpanContext().TraceID() {
+			require.Contains(t, steps, s.Parent().SpanID())
+		}
+	}
+}
+
 func TestStepEmitsErrors(t *testing.T) {
 	ctx := context.Background()
 
@@ -95,6 +161,37 @@ func TestStepEmitsErrors(t *testing.T) {
 	require.ElementsMatch(t, []int{1, 3}, gotValues)
 }
 
+func TestStepWaitsForWorkersWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	input := make(chan int, 1)
+	input <- 1
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	out, errs := Step(ctx, input, func(v int) (int, error) {
+		close(started)
+		<-release
+		return v, nil
+	}, 1)
+
+	<-started
+	cancel()
+
+	// The channels stay open while the worker runs, so its send cannot hit a
+	// closed channel.
+	select {
+	case <-out:
+		t.Fatal("output closed while a worker was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+
+	for range out {
+	}
+	for range errs {
+	}
+}
+
 func TestMergeCombinesChannels(t *testing.T) {
 	ctx := context.Background()
 
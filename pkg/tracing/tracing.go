@@ -0,0 +1,63 @@
+// Package tracing installs the OpenTelemetry tracer provider used by the
+// executor, pipeline, tracker and metadata spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Init installs the global tracer provider of the exporter selected by
+// OTEL_TRACES_EXPORTER:
+//
+//   - "" or "none": tracing stays disabled and spans cost next to nothing
+//   - "otlp": OTLP over gRPC, configured by the standard OTEL_EXPORTER_OTLP_*
+//     variables (endpoint defaults to localhost:4317)
+//   - "stdout" or "console": spans are written to stderr as JSON, for debugging
+//
+// service names the process unless OTEL_SERVICE_NAME is set. The returned
+// shutdown flushes pending spans; it is never nil.
+func Init(ctx context.Context, service string) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+
+	var exporter sdktrace.SpanExporter
+	kind := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER")))
+	switch kind {
+	case "", "none":
+		return noop, nil
+	case "otlp":
+		exporter, err = otlptracegrpc.New(ctx)
+	case "stdout", "console":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		return noop, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q (none|otlp|stdout)", kind)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("create %s trace exporter: %w", kind, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", service)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return noop, fmt.Errorf("trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
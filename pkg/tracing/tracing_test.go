@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestInit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	shutdown, err := Init(ctx, "moviestracker")
+	require.NoError(t, err)
+	require.NoError(t, shutdown(ctx))
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.False(t, ok, "tracing stays disabled")
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "zipkin")
+	shutdown, err = Init(ctx, "moviestracker")
+	require.ErrorContains(t, err, `unsupported OTEL_TRACES_EXPORTER "zipkin"`)
+	require.NotNil(t, shutdown)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "stdout")
+	shutdown, err = Init(ctx, "moviestracker")
+	require.NoError(t, err)
+	_, ok = otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, shutdown(ctx))
+}